@@ -18,6 +18,7 @@ require (
 	github.com/golang/glog v0.0.0-20141105023935-44145f04b68c
 	github.com/golang/groupcache v0.0.0-20160516000752-02826c3e7903 // indirect
 	github.com/golang/protobuf v0.0.0-20161109072736-4bd1920723d7 // indirect
+	github.com/golang/snappy v0.0.1
 	github.com/google/btree v0.0.0-20160524151835-7d79101e329e // indirect
 	github.com/google/gofuzz v0.0.0-20161122191042-44d81051d367 // indirect
 	github.com/googleapis/gnostic v0.0.0-20170729233727-0c5108395e2d // indirect
@@ -53,6 +54,7 @@ require (
 	golang.org/x/sys v0.0.0-20171031081856-95c657629925 // indirect
 	golang.org/x/text v0.0.0-20170810154203-b19bf474d317 // indirect
 	google.golang.org/appengine v0.0.0-20160823001527-4f7eeb5305a4 // indirect
+	google.golang.org/grpc v1.2.1
 	gopkg.in/inf.v0 v0.9.0 // indirect
 	gopkg.in/yaml.v2 v2.0.0-20150924142314-53feefa2559f // indirect
 	k8s.io/api v0.0.0-20171207041203-11147472b7c9
@@ -0,0 +1,30 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// maxRequestSize maximum size of a notification
+const maxRequestSize = 4 << 20
+
+// Handler returns the http handler of the Alertmanager webhook receiver that feeds the store
+func Handler(store *Store, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		msg := &Message{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Sugar().Debugf("alertmanager: %d alerts received from receiver %s, group %s", len(msg.Alerts), msg.Receiver, msg.GroupKey)
+		store.Update(msg)
+		w.WriteHeader(http.StatusOK)
+	})
+}
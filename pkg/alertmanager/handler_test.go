@@ -0,0 +1,42 @@
+package alertmanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestHandler(t *testing.T) {
+	devLogger, _ := zap.NewDevelopment()
+	store := NewStore(time.Hour)
+	handler := Handler(store, devLogger)
+
+	body := `{"version":"4","status":"firing","receiver":"kubervisor","alerts":[{"status":"firing","labels":{"alertname":"HighErrorRate","pod":"A"},"startsAt":"2018-06-01T12:00:00Z","endsAt":"0001-01-01T00:00:00Z"}]}`
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantAlerts int
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "invalid body", method: http.MethodPost, body: "{", wantStatus: http.StatusBadRequest},
+		{name: "notification", method: http.MethodPost, body: body, wantStatus: http.StatusOK, wantAlerts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, "/api/v1/alerts", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := len(store.Firing(func(map[string]string) bool { return true })); got != tt.wantAlerts {
+				t.Errorf("firing alerts = %d, want %d", got, tt.wantAlerts)
+			}
+		})
+	}
+}
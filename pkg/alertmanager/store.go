@@ -0,0 +1,101 @@
+package alertmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Store keeps the firing alerts received on the webhook. A resolved alert is removed, as well as a firing alert once its EndsAt is passed
+// or once it has not been received for maxAge: the Alertmanager sends the firing alerts again every repeat_interval, an alert with a zero
+// EndsAt whose resolution was lost would stay forever otherwise
+type Store struct {
+	sync.RWMutex
+	alerts      map[model.Fingerprint]storedAlert
+	subscribers map[int]chan struct{}
+	nextID      int
+	maxAge      time.Duration
+	now         func() time.Time
+}
+
+type storedAlert struct {
+	Alert
+	lastSeen time.Time
+}
+
+// NewStore returns a new Store instance. maxAge must be greater than the repeat_interval of the Alertmanager route
+func NewStore(maxAge time.Duration) *Store {
+	return &Store{
+		alerts:      map[model.Fingerprint]storedAlert{},
+		subscribers: map[int]chan struct{}{},
+		maxAge:      maxAge,
+		now:         time.Now,
+	}
+}
+
+// Update applies the alerts of the notification and notifies the subscribers
+func (s *Store) Update(msg *Message) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	for _, a := range msg.Alerts {
+		fp := fingerprint(a.Labels)
+		if a.Status == StatusResolved {
+			delete(s.alerts, fp)
+			continue
+		}
+		s.alerts[fp] = storedAlert{Alert: a, lastSeen: now}
+	}
+	for _, c := range s.subscribers {
+		select {
+		case c <- struct{}{}:
+		default: // an evaluation is already pending
+		}
+	}
+}
+
+// Firing returns the firing alerts accepted by the match function
+func (s *Store) Firing(match func(labels map[string]string) bool) []Alert {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	result := []Alert{}
+	for fp, a := range s.alerts {
+		if (!a.EndsAt.IsZero() && a.EndsAt.Before(now)) || now.Sub(a.lastSeen) > s.maxAge {
+			delete(s.alerts, fp)
+			continue
+		}
+		if match(a.Labels) {
+			result = append(result, a.Alert)
+		}
+	}
+	return result
+}
+
+// Subscribe returns a channel that receives after each Update. Pending notifications are merged.
+// The returned function must be called to unsubscribe
+func (s *Store) Subscribe() (<-chan struct{}, func()) {
+	s.Lock()
+	defer s.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	c := make(chan struct{}, 1)
+	s.subscribers[id] = c
+	return c, func() {
+		s.Lock()
+		defer s.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+func fingerprint(labels map[string]string) model.Fingerprint {
+	set := make(model.LabelSet, len(labels))
+	for k, v := range labels {
+		set[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return set.Fingerprint()
+}
@@ -0,0 +1,79 @@
+package alertmanager
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := NewStore(time.Hour)
+	s.now = func() time.Time { return now }
+	trigger, cancel := s.Subscribe()
+
+	s.Update(&Message{Alerts: []Alert{
+		{Status: StatusFiring, Labels: map[string]string{"alertname": "HighErrorRate", "pod": "A"}},
+		{Status: StatusFiring, Labels: map[string]string{"alertname": "HighErrorRate", "pod": "B"}},
+		{Status: StatusFiring, Labels: map[string]string{"alertname": "HighErrorRate", "pod": "C"}, EndsAt: now.Add(-time.Second)},
+		{Status: StatusFiring, Labels: map[string]string{"alertname": "HighLatency", "pod": "D"}},
+	}})
+	s.Update(&Message{Alerts: []Alert{
+		{Status: StatusResolved, Labels: map[string]string{"alertname": "HighErrorRate", "pod": "B"}},
+	}})
+
+	select {
+	case <-trigger:
+	default:
+		t.Errorf("the subscriber was not notified")
+	}
+	select {
+	case <-trigger:
+		t.Errorf("pending notifications should be merged")
+	default:
+	}
+
+	alerts := s.Firing(func(labels map[string]string) bool { return labels["alertname"] == "HighErrorRate" })
+	pods := []string{}
+	for _, a := range alerts {
+		pods = append(pods, a.Labels["pod"])
+	}
+	sort.Strings(pods)
+	if !reflect.DeepEqual(pods, []string{"A"}) {
+		t.Errorf("Firing() pods = %v, want [A]", pods)
+	}
+
+	cancel()
+	s.Update(&Message{})
+	select {
+	case <-trigger:
+		t.Errorf("the subscriber was notified after unsubscribe")
+	default:
+	}
+}
+
+func TestStore_maxAge(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := NewStore(time.Hour)
+	s.now = func() time.Time { return now }
+	firing := func(pod string) Alert {
+		return Alert{Status: StatusFiring, Labels: map[string]string{"alertname": "HighErrorRate", "pod": pod}}
+	}
+	all := func(labels map[string]string) bool { return true }
+
+	s.Update(&Message{Alerts: []Alert{firing("A"), firing("B")}})
+	now = now.Add(40 * time.Minute)
+	// the Alertmanager sends again the alerts still firing at repeat_interval, the resolution of B is lost
+	s.Update(&Message{Alerts: []Alert{firing("A")}})
+	now = now.Add(40 * time.Minute)
+
+	alerts := s.Firing(all)
+	if len(alerts) != 1 || alerts[0].Labels["pod"] != "A" {
+		t.Errorf("Firing() = %v, want only the alert of pod A", alerts)
+	}
+	now = now.Add(time.Hour)
+	if alerts := s.Firing(all); len(alerts) != 0 {
+		t.Errorf("Firing() = %v, want no alert once the max age is passed", alerts)
+	}
+}
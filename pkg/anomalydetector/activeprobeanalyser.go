@@ -0,0 +1,320 @@
+package anomalydetector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+// maxProbeBodySize maximum number of bytes of the response body read
+const maxProbeBodySize = 1 << 20
+
+func init() {
+	prometheus.MustRegister(probeSuccessRatioGauges)
+	prometheus.MustRegister(probeLatencyGauges)
+}
+
+var (
+	probeSuccessRatioGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_active_probe_success_ratio",
+			Help: "Ratio of successful active probes among the last probes of the pod",
+		},
+		[]string{"kubervisorservice", "namespace", "strategy", "pod"},
+	)
+
+	probeLatencyGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_active_probe_latency_seconds",
+			Help: "Average latency of the last active probes of the pod",
+		},
+		[]string{"kubervisorservice", "namespace", "strategy", "pod"},
+	)
+)
+
+var _ AnomalyDetector = &ActiveProbeAnalyser{}
+var _ Runner = &ActiveProbeAnalyser{}
+
+// prober sends one synthetic request to the address (host:port) of a pod
+type prober interface {
+	probe(ctx context.Context, addr string) error
+}
+
+type probeResult struct {
+	failed  bool
+	latency time.Duration
+}
+
+// probeHistory last probe results of a pod
+type probeHistory struct {
+	uid     types.UID
+	results []probeResult
+}
+
+//ActiveProbeAnalyser anomalyDetector that probes each pod and returns the pods with a ratio of failed probes above the tolerance
+type ActiveProbeAnalyser struct {
+	api.ActiveProbe
+	kubervisorName string
+	namespace      string
+	strategyName   string
+	selector       labels.Selector
+	podLister      kv1.PodNamespaceLister
+	logger         *zap.Logger
+	prober         prober
+	address        func(p *kapiv1.Pod) string
+
+	// history is written by the breaker goroutine that calls GetPodsOutOfBounds, and cleared by Run when the breaker stops
+	mutex   sync.Mutex
+	history map[string]*probeHistory
+	stopped bool
+}
+
+//Run implements interface Runner: the metrics of the probed pods are deleted when the breaker stops
+func (d *ActiveProbeAnalyser) Run(stop <-chan struct{}) {
+	<-stop
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stopped = true
+	for name := range d.history {
+		d.deleteMetrics(name)
+		delete(d.history, name)
+	}
+}
+
+func (d *ActiveProbeAnalyser) deleteMetrics(podName string) {
+	probeSuccessRatioGauges.DeleteLabelValues(d.kubervisorName, d.namespace, d.strategyName, podName)
+	probeLatencyGauges.DeleteLabelValues(d.kubervisorName, d.namespace, d.strategyName, podName)
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *ActiveProbeAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	toProbe := []*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			d.logger.Sugar().Debugf("the pod %s is not probed as it is marked out of traffic\n", p.Name)
+			continue
+		}
+		if p.Status.PodIP == "" {
+			continue
+		}
+		toProbe = append(toProbe, p)
+	}
+
+	results := d.probeAll(toProbe)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.stopped {
+		return nil, fmt.Errorf("the active probe analyser is stopped")
+	}
+	result := []*kapiv1.Pod{}
+	seen := map[string]struct{}{}
+	for _, p := range toProbe {
+		seen[p.Name] = struct{}{}
+		h, ok := d.history[p.Name]
+		if !ok || h.uid != p.UID {
+			h = &probeHistory{uid: p.UID}
+			d.history[p.Name] = h
+		}
+		h.results = append(h.results, results[p.Name])
+		if len(h.results) > int(*d.Window) {
+			h.results = h.results[len(h.results)-int(*d.Window):]
+		}
+
+		failed, latency := 0, time.Duration(0)
+		for _, r := range h.results {
+			if r.failed {
+				failed++
+			}
+			latency += r.latency
+		}
+		probeSuccessRatioGauges.WithLabelValues(d.kubervisorName, d.namespace, d.strategyName, p.Name).Set(float64(len(h.results)-failed) / float64(len(h.results)))
+		probeLatencyGauges.WithLabelValues(d.kubervisorName, d.namespace, d.strategyName, p.Name).Set(latency.Seconds() / float64(len(h.results)))
+
+		if len(h.results) < int(*d.Window) {
+			continue
+		}
+		// compared without division: 1 failure out of 3 is above a tolerance of 33%
+		if failed*100 > int(*d.TolerancePercent)*len(h.results) {
+			d.logger.Sugar().Infof("the pod %s is out of bounds: %d failed probes out of %d", p.Name, failed, len(h.results))
+			result = append(result, p)
+		}
+	}
+
+	// forget the pods that disappeared or that are not probed anymore
+	for name := range d.history {
+		if _, ok := seen[name]; !ok {
+			d.deleteMetrics(name)
+			delete(d.history, name)
+		}
+	}
+	return result, nil
+}
+
+// probeAll probes the pods with a bounded concurrency
+func (d *ActiveProbeAnalyser) probeAll(pods []*kapiv1.Pod) map[string]probeResult {
+	timeout := time.Duration(*d.Timeout*1000) * time.Millisecond
+	results := map[string]probeResult{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, *d.MaxConcurrentProbes)
+	for _, p := range pods {
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(p *kapiv1.Pod) {
+			defer wg.Done()
+			defer func() { <-tokens }()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			start := time.Now()
+			err := d.prober.probe(ctx, d.address(p))
+			r := probeResult{latency: time.Since(start)}
+			if err != nil {
+				d.logger.Sugar().Debugf("probe of pod %s failed: %v", p.Name, err)
+				r.failed = true
+			} else if d.MaxLatency != nil && r.latency.Seconds() > *d.MaxLatency {
+				d.logger.Sugar().Debugf("probe of pod %s too slow: %v", p.Name, r.latency)
+				r.failed = true
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[p.Name] = r
+		}(p)
+	}
+	wg.Wait()
+	return results
+}
+
+func podProbeAddress(port int32) func(p *kapiv1.Pod) string {
+	return func(p *kapiv1.Pod) string {
+		return net.JoinHostPort(p.Status.PodIP, strconv.Itoa(int(port)))
+	}
+}
+
+type httpProber struct {
+	config    api.HTTPProbe
+	client    *http.Client
+	bodyRegex *regexp.Regexp
+}
+
+func newHTTPProber(config api.HTTPProbe) (*httpProber, error) {
+	h := &httpProber{
+		config: config,
+		// No keep-alive: a large fleet must not keep one idle connection per pod between two evaluations
+		client: &http.Client{Transport: &http.Transport{DisableKeepAlives: true}},
+	}
+	if config.BodyRegex != "" {
+		r, err := regexp.Compile(config.BodyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body regex: %v", err)
+		}
+		h.bodyRegex = r
+	}
+	return h, nil
+}
+
+func (h *httpProber) probe(ctx context.Context, addr string) error {
+	_, err := h.fetch(ctx, addr)
+	return err
+}
+
+// fetch sends the request and returns the response body when the status and the body are the expected ones
+func (h *httpProber) fetch(ctx context.Context, addr string) ([]byte, error) {
+	req, err := http.NewRequest(h.config.Method, "http://"+addr+h.config.Path, strings.NewReader(h.config.Body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range h.config.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if len(h.config.ExpectedStatus) == 0 {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+	} else if !containsInt(h.config.ExpectedStatus, resp.StatusCode) {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("can't read body: %v", err)
+	}
+	if h.bodyRegex != nil && !h.bodyRegex.Match(body) {
+		return nil, fmt.Errorf("the body does not match %q", h.config.BodyRegex)
+	}
+	return body, nil
+}
+
+func containsInt(list []int, value int) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type grpcHealthProber struct {
+	service string
+}
+
+func (g *grpcHealthProber) probe(ctx context.Context, addr string) error {
+	// the connection is not kept: the number of open connections stays bounded by the probe concurrency
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	resp, err := healthv1.NewHealthClient(conn).Check(ctx, &healthv1.HealthCheckRequest{Service: g.service})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthv1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("the serving status is %s", resp.Status)
+	}
+	return nil
+}
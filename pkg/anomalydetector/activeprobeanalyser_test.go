@@ -0,0 +1,342 @@
+package anomalydetector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func probedPodGen(name string) *kapiv1.Pod {
+	p := test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	p.Status.PodIP = "127.0.0.1"
+	return p
+}
+
+func TestActiveProbeAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	servers := map[string]*httptest.Server{
+		"A": httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "ok") })),
+		"B": httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) })),
+		"C": httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprint(w, "ok")
+		})),
+	}
+	for _, s := range servers {
+		defer s.Close()
+	}
+	pods := []*kapiv1.Pod{probedPodGen("A"), probedPodGen("B"), probedPodGen("C")}
+
+	config := *api.DefaultActiveProbe(&api.ActiveProbe{Port: 8080, HTTP: &api.HTTPProbe{}, Window: api.NewUInt(3), MaxLatency: api.NewFloat64(0.05)})
+	httpProber, _ := newHTTPProber(*config.HTTP)
+	d := &ActiveProbeAnalyser{
+		ActiveProbe: config,
+		selector:    labels.Everything(),
+		podLister:   test.NewTestPodNamespaceLister(pods, "test-ns"),
+		logger:      devlogger,
+		prober:      httpProber,
+		address:     func(p *kapiv1.Pod) string { return strings.TrimPrefix(servers[p.Name].URL, "http://") },
+		history:     map[string]*probeHistory{},
+	}
+
+	for i, want := range [][]string{{}, {}, {"B", "C"}} {
+		got, err := d.GetPodsOutOfBounds()
+		if err != nil {
+			t.Fatalf("tick %d: ActiveProbeAnalyser.GetPodsOutOfBounds() unexpected error: %v", i, err)
+		}
+		gotNames := []string{}
+		for _, p := range got {
+			gotNames = append(gotNames, p.Name)
+		}
+		sort.Strings(gotNames)
+		if strings.Join(gotNames, ",") != strings.Join(want, ",") {
+			t.Errorf("tick %d: ActiveProbeAnalyser.GetPodsOutOfBounds() = %v, want %v", i, gotNames, want)
+		}
+	}
+
+	// pod B disappears
+	d.podLister = test.NewTestPodNamespaceLister([]*kapiv1.Pod{probedPodGen("A")}, "test-ns")
+	if _, err := d.GetPodsOutOfBounds(); err != nil {
+		t.Fatalf("ActiveProbeAnalyser.GetPodsOutOfBounds() unexpected error: %v", err)
+	}
+	if _, ok := d.history["B"]; ok || len(d.history) != 1 {
+		t.Errorf("the history of the removed pods should be forgotten: %v", d.history)
+	}
+}
+
+type testConcurrencyProber struct {
+	sync.Mutex
+	inFlight, maxInFlight int
+}
+
+func (c *testConcurrencyProber) probe(ctx context.Context, addr string) error {
+	c.Lock()
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	c.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	c.Lock()
+	c.inFlight--
+	c.Unlock()
+	return nil
+}
+
+func TestActiveProbeAnalyser_concurrency(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	pods := []*kapiv1.Pod{}
+	for i := 0; i < 20; i++ {
+		pods = append(pods, probedPodGen(fmt.Sprintf("pod%d", i)))
+	}
+	p := &testConcurrencyProber{}
+	d := &ActiveProbeAnalyser{
+		ActiveProbe: *api.DefaultActiveProbe(&api.ActiveProbe{Port: 8080, GRPC: &api.GRPCHealthProbe{}, MaxConcurrentProbes: api.NewUInt(3)}),
+		selector:    labels.Everything(),
+		podLister:   test.NewTestPodNamespaceLister(pods, "test-ns"),
+		logger:      devlogger,
+		prober:      p,
+		address:     podProbeAddress(8080),
+		history:     map[string]*probeHistory{},
+	}
+	if _, err := d.GetPodsOutOfBounds(); err != nil {
+		t.Fatalf("ActiveProbeAnalyser.GetPodsOutOfBounds() unexpected error: %v", err)
+	}
+	if p.maxInFlight > 3 || p.maxInFlight == 0 {
+		t.Errorf("max probes in flight = %d, want between 1 and 3", p.maxInFlight)
+	}
+	if len(d.history) != 20 {
+		t.Errorf("all the pods should have been probed, got %d", len(d.history))
+	}
+}
+
+// testFailingProber fails all the probes if failing is set
+type testFailingProber struct {
+	failing bool
+}
+
+func (f *testFailingProber) probe(ctx context.Context, addr string) error {
+	if f.failing {
+		return fmt.Errorf("probe failed")
+	}
+	return nil
+}
+
+func TestActiveProbeAnalyser_tolerance(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	tests := []struct {
+		name      string
+		tolerance uint
+		want      bool
+	}{
+		{name: "1 failure of 3 above a tolerance of 33%", tolerance: 33, want: true},
+		{name: "1 failure of 3 below a tolerance of 34%", tolerance: 34, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prober := &testFailingProber{}
+			d := &ActiveProbeAnalyser{
+				ActiveProbe: *api.DefaultActiveProbe(&api.ActiveProbe{Port: 8080, GRPC: &api.GRPCHealthProbe{}, Window: api.NewUInt(3), TolerancePercent: api.NewUInt(tt.tolerance)}),
+				selector:    labels.Everything(),
+				podLister:   test.NewTestPodNamespaceLister([]*kapiv1.Pod{probedPodGen("A")}, "test-ns"),
+				logger:      devlogger,
+				prober:      prober,
+				address:     podProbeAddress(8080),
+				history:     map[string]*probeHistory{},
+			}
+			var got []*kapiv1.Pod
+			for i, failing := range []bool{true, false, false} {
+				prober.failing = failing
+				var err error
+				if got, err = d.GetPodsOutOfBounds(); err != nil {
+					t.Fatalf("tick %d: ActiveProbeAnalyser.GetPodsOutOfBounds() unexpected error: %v", i, err)
+				}
+			}
+			if (len(got) == 1) != tt.want {
+				t.Errorf("ActiveProbeAnalyser.GetPodsOutOfBounds() = %d pods out of bounds, want out of bounds: %v", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// gaugeValues returns the values of the series of the KubervisorService by "namespace/pod/strategy"
+func gaugeValues(t *testing.T, g *prometheus.GaugeVec, kubervisorName string) map[string]float64 {
+	ch := make(chan prometheus.Metric, 100)
+	g.Collect(ch)
+	close(ch)
+	values := map[string]float64{}
+	for m := range ch {
+		metric := &dto.Metric{}
+		if err := m.Write(metric); err != nil {
+			t.Fatalf("can't read the metric: %v", err)
+		}
+		labelValues := map[string]string{}
+		for _, l := range metric.Label {
+			labelValues[l.GetName()] = l.GetValue()
+		}
+		if labelValues["kubervisorservice"] != kubervisorName {
+			continue
+		}
+		values[labelValues["namespace"]+"/"+labelValues["pod"]+"/"+labelValues["strategy"]] = metric.Gauge.GetValue()
+	}
+	return values
+}
+
+func TestActiveProbeAnalyser_metrics(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	pods := []*kapiv1.Pod{probedPodGen("A")}
+	newAnalyser := func(strategy string, failing bool) *ActiveProbeAnalyser {
+		return &ActiveProbeAnalyser{
+			ActiveProbe:    *api.DefaultActiveProbe(&api.ActiveProbe{Port: 8080, GRPC: &api.GRPCHealthProbe{}, Window: api.NewUInt(1)}),
+			kubervisorName: "foo",
+			namespace:      "test-ns",
+			strategyName:   strategy,
+			selector:       labels.Everything(),
+			podLister:      test.NewTestPodNamespaceLister(pods, "test-ns"),
+			logger:         devlogger,
+			prober:         &testFailingProber{failing: failing},
+			address:        podProbeAddress(8080),
+			history:        map[string]*probeHistory{},
+		}
+	}
+
+	// two strategies probing the same pod have their own series
+	ok, failing := newAnalyser("ok", false), newAnalyser("failing", true)
+	stopOK, stopFailing := make(chan struct{}), make(chan struct{})
+	doneOK, doneFailing := make(chan struct{}), make(chan struct{})
+	go func() { ok.Run(stopOK); close(doneOK) }()
+	go func() { failing.Run(stopFailing); close(doneFailing) }()
+	for _, d := range []*ActiveProbeAnalyser{ok, failing} {
+		if _, err := d.GetPodsOutOfBounds(); err != nil {
+			t.Fatalf("ActiveProbeAnalyser.GetPodsOutOfBounds() unexpected error: %v", err)
+		}
+	}
+	want := map[string]float64{"test-ns/A/ok": 1, "test-ns/A/failing": 0}
+	if got := gaugeValues(t, probeSuccessRatioGauges, "foo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("kubervisor_active_probe_success_ratio = %v, want %v", got, want)
+	}
+
+	// the series of a strategy are deleted when its breaker stops
+	close(stopOK)
+	<-doneOK
+	want = map[string]float64{"test-ns/A/failing": 0}
+	if got := gaugeValues(t, probeSuccessRatioGauges, "foo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("kubervisor_active_probe_success_ratio after stop = %v, want %v", got, want)
+	}
+	if _, err := ok.GetPodsOutOfBounds(); err == nil {
+		t.Errorf("ActiveProbeAnalyser.GetPodsOutOfBounds() expected an error once stopped")
+	}
+	close(stopFailing)
+	<-doneFailing
+	if got := gaugeValues(t, probeLatencyGauges, "foo"); len(got) != 0 {
+		t.Errorf("kubervisor_active_probe_latency_seconds after stop = %v, want none", got)
+	}
+}
+
+func Test_httpProber_probe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Probe") != "kubervisor" || r.Host != "foo.local" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"status":"UP"}`)
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	base := api.HTTPProbe{Method: "POST", Path: "/health", Headers: map[string]string{"X-Probe": "kubervisor", "Host": "foo.local"}}
+	tests := []struct {
+		name    string
+		update  func(c *api.HTTPProbe)
+		wantErr bool
+	}{
+		{name: "any 2xx", update: func(c *api.HTTPProbe) {}},
+		{name: "expected status", update: func(c *api.HTTPProbe) { c.ExpectedStatus = []int{200, 202} }},
+		{name: "unexpected status", update: func(c *api.HTTPProbe) { c.ExpectedStatus = []int{200} }, wantErr: true},
+		{name: "bad request", update: func(c *api.HTTPProbe) { c.Headers = nil }, wantErr: true},
+		{name: "body match", update: func(c *api.HTTPProbe) { c.BodyRegex = `"status":"UP"` }},
+		{name: "body mismatch", update: func(c *api.HTTPProbe) { c.BodyRegex = `"status":"DOWN"` }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *base.DeepCopy()
+			tt.update(&config)
+			h, err := newHTTPProber(config)
+			if err != nil {
+				t.Fatalf("newHTTPProber() unexpected error: %v", err)
+			}
+			if err := h.probe(context.Background(), addr); (err != nil) != tt.wantErr {
+				t.Errorf("httpProber.probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// testHealthServer serves the status of the known services, NotFound for the others like the standard health server
+type testHealthServer struct {
+	statuses map[string]healthv1.HealthCheckResponse_ServingStatus
+}
+
+func (h *testHealthServer) Check(ctx context.Context, req *healthv1.HealthCheckRequest) (*healthv1.HealthCheckResponse, error) {
+	status, ok := h.statuses[req.Service]
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "unknown service")
+	}
+	return &healthv1.HealthCheckResponse{Status: status}, nil
+}
+
+func Test_grpcHealthProber_probe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %v", err)
+	}
+	server := grpc.NewServer()
+	healthv1.RegisterHealthServer(server, &testHealthServer{statuses: map[string]healthv1.HealthCheckResponse_ServingStatus{
+		"":    healthv1.HealthCheckResponse_SERVING,
+		"foo": healthv1.HealthCheckResponse_NOT_SERVING,
+	}})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	tests := []struct {
+		name    string
+		service string
+		wantErr bool
+	}{
+		{name: "serving", service: ""},
+		{name: "not serving", service: "foo", wantErr: true},
+		{name: "unknown service", service: "bar", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			g := &grpcHealthProber{service: tt.service}
+			if err := g.probe(ctx, listener.Addr().String()); (err != nil) != tt.wantErr {
+				t.Errorf("grpcHealthProber.probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package anomalydetector
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &AlertmanagerAnalyser{}
+var _ Triggered = &AlertmanagerAnalyser{}
+var _ Runner = &AlertmanagerAnalyser{}
+
+//AlertmanagerAnalyser anomalyDetector that returns the pods with a matching firing alert received on the Alertmanager webhook
+type AlertmanagerAnalyser struct {
+	api.Alertmanager
+	namespace string
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	store     *alertmanager.Store
+	logger    *zap.Logger
+
+	trigger     <-chan struct{}
+	unsubscribe func()
+}
+
+//Trigger implements interface Triggered: the channel receives when a notification arrives
+func (d *AlertmanagerAnalyser) Trigger() <-chan struct{} {
+	return d.trigger
+}
+
+//Run implements interface Runner: the subscription to the store ends when stop is closed
+func (d *AlertmanagerAnalyser) Run(stop <-chan struct{}) {
+	<-stop
+	d.unsubscribe()
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *AlertmanagerAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	alerts := d.store.Firing(d.match)
+	firing := map[string]alertmanager.Alert{}
+	for _, a := range alerts {
+		firing[a.Labels[d.PodLabel]] = a
+	}
+	if len(firing) == 0 {
+		return []*kapiv1.Pod{}, nil
+	}
+
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	result := []*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		a, ok := firing[p.Name]
+		if !ok {
+			continue
+		}
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			continue
+		}
+		d.logger.Sugar().Infof("the pod %s is out of bounds: alert %s firing since %v", p.Name, a.Labels["alertname"], a.StartsAt)
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// match returns true if the alert has the MatchLabels and a pod label, and is not from another namespace
+func (d *AlertmanagerAnalyser) match(alertLabels map[string]string) bool {
+	if alertLabels[d.PodLabel] == "" {
+		return false
+	}
+	if ns, ok := alertLabels[d.NamespaceLabel]; ok && ns != d.namespace {
+		return false
+	}
+	for k, v := range d.MatchLabels {
+		if alertLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package anomalydetector
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func TestAlertmanagerAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devLogger, _ := zap.NewDevelopment()
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo),
+		test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+	}
+	firing := func(alertname, pod, namespace string) alertmanager.Alert {
+		l := map[string]string{"alertname": alertname, "pod": pod}
+		if namespace != "" {
+			l["namespace"] = namespace
+		}
+		return alertmanager.Alert{Status: alertmanager.StatusFiring, Labels: l, StartsAt: time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name     string
+		config   api.Alertmanager
+		messages []alertmanager.Message
+		want     []string
+	}{
+		{
+			name:   "no alert",
+			config: *api.DefaultAlertmanager(&api.Alertmanager{}),
+			want:   []string{},
+		},
+		{
+			name:   "firing alerts",
+			config: *api.DefaultAlertmanager(&api.Alertmanager{MatchLabels: map[string]string{"alertname": "HighErrorRate"}}),
+			messages: []alertmanager.Message{{Alerts: []alertmanager.Alert{
+				firing("HighErrorRate", "A", "test-ns"),
+				firing("HighErrorRate", "B", ""),
+				firing("HighErrorRate", "C", "test-ns"),
+				firing("HighLatency", "D", "test-ns"),
+			}}},
+			want: []string{"A", "B"},
+		},
+		{
+			name:   "other namespace",
+			config: *api.DefaultAlertmanager(&api.Alertmanager{}),
+			messages: []alertmanager.Message{{Alerts: []alertmanager.Alert{
+				firing("HighErrorRate", "A", "other-ns"),
+			}}},
+			want: []string{},
+		},
+		{
+			name:   "resolved alert",
+			config: *api.DefaultAlertmanager(&api.Alertmanager{}),
+			messages: []alertmanager.Message{
+				{Alerts: []alertmanager.Alert{firing("HighErrorRate", "A", "test-ns"), firing("HighErrorRate", "D", "test-ns")}},
+				{Alerts: []alertmanager.Alert{{Status: alertmanager.StatusResolved, Labels: map[string]string{"alertname": "HighErrorRate", "pod": "A", "namespace": "test-ns"}}}},
+			},
+			want: []string{"D"},
+		},
+		{
+			name:   "custom pod label",
+			config: *api.DefaultAlertmanager(&api.Alertmanager{PodLabel: "kubernetes_pod_name"}),
+			messages: []alertmanager.Message{{Alerts: []alertmanager.Alert{
+				firing("HighErrorRate", "A", "test-ns"),
+				{Status: alertmanager.StatusFiring, Labels: map[string]string{"alertname": "HighErrorRate", "kubernetes_pod_name": "B"}},
+			}}},
+			want: []string{"B"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := alertmanager.NewStore(time.Hour)
+			for i := range tt.messages {
+				store.Update(&tt.messages[i])
+			}
+			d := &AlertmanagerAnalyser{
+				Alertmanager: tt.config,
+				namespace:    "test-ns",
+				selector:     labels.SelectorFromSet(map[string]string{"app": "foo"}),
+				podLister:    test.NewTestPodNamespaceLister(pods, "test-ns"),
+				store:        store,
+				logger:       devLogger,
+			}
+			got, err := d.GetPodsOutOfBounds()
+			if err != nil {
+				t.Fatalf("AlertmanagerAnalyser.GetPodsOutOfBounds() error = %v", err)
+			}
+			names := []string{}
+			for _, p := range got {
+				names = append(names, p.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("AlertmanagerAnalyser.GetPodsOutOfBounds() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertmanagerAnalyser_Trigger(t *testing.T) {
+	devLogger, _ := zap.NewDevelopment()
+	store := alertmanager.NewStore(time.Hour)
+	detector, err := newAlertmanagerAnalyser(Config{
+		Namespace:             "test-ns",
+		Logger:                devLogger,
+		AlertStore:            store,
+		BreakerStrategyConfig: api.BreakerStrategy{Alertmanager: api.DefaultAlertmanager(&api.Alertmanager{})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		detector.Run(stop)
+		close(done)
+	}()
+
+	store.Update(&alertmanager.Message{})
+	select {
+	case <-detector.Trigger():
+	case <-time.After(time.Second):
+		t.Fatalf("no trigger after a notification")
+	}
+
+	close(stop)
+	<-done
+	store.Update(&alertmanager.Message{})
+	select {
+	case <-detector.Trigger():
+		t.Errorf("trigger after stop")
+	default:
+	}
+}
@@ -5,9 +5,14 @@ import (
 
 	kapiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
 	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
 )
 
 //AnomalyDetector returns the list of pods that do not behave correctly according to the configuration
@@ -15,10 +20,33 @@ type AnomalyDetector interface {
 	GetPodsOutOfBounds() ([]*kapiv1.Pod, error)
 }
 
+//Runner optionally implemented by the AnomalyDetectors that need a background goroutine. Run returns when stop is closed
+type Runner interface {
+	Run(stop <-chan struct{})
+}
+
+//Triggered optionally implemented by the AnomalyDetectors that are evaluated each time the channel receives, in addition to every evaluation period
+type Triggered interface {
+	Trigger() <-chan struct{}
+}
+
+//FleetReporter optionally implemented by the AnomalyDetectors that measure the bad values ratio of the whole fleet of pods
+type FleetReporter interface {
+	// FleetErrorRatio returns the ratio measured by the last GetPodsOutOfBounds, false if there was no activity
+	FleetErrorRatio() (float64, bool)
+}
+
 //Config parameters required for the creation of an AnomalyDetector
 type Config struct {
+	KubervisorName        string
+	Namespace             string
 	BreakerStrategyConfig api.BreakerStrategy
 	Selector              labels.Selector
 	PodLister             kv1.PodNamespaceLister
+	PodIndexer            cache.Indexer
 	Logger                *zap.Logger
+	KubeClient            clientset.Interface
+	RemoteWriteStore      *remotewrite.Store
+	ReportLister          blisters.PodAnomalyReportNamespaceLister
+	AlertStore            *alertmanager.Store
 }
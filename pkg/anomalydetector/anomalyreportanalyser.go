@@ -0,0 +1,99 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &AnomalyReportAnalyser{}
+
+// severityLevels order of the PodAnomalyReport severities
+var severityLevels = map[api.PodAnomalyReportSeverity]int{
+	api.PodAnomalyReportSeverityInfo:     0,
+	api.PodAnomalyReportSeverityWarning:  1,
+	api.PodAnomalyReportSeverityCritical: 2,
+}
+
+//AnomalyReportAnalyser anomalyDetector that returns the pods with an unexpired PodAnomalyReport matching the source and severity filter
+type AnomalyReportAnalyser struct {
+	api.AnomalyReport
+	selector     labels.Selector
+	podLister    kv1.PodNamespaceLister
+	reportLister blisters.PodAnomalyReportNamespaceLister
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *AnomalyReportAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	reports, err := d.reportLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("can't list pod anomaly reports, error:%v", err)
+	}
+	now := d.now()
+	reported := map[string]*api.PodAnomalyReport{}
+	for _, r := range reports {
+		if !d.matches(r, now) {
+			continue
+		}
+		if previous, ok := reported[r.Spec.PodName]; !ok || severityLevels[r.Spec.Severity] > severityLevels[previous.Spec.Severity] {
+			reported[r.Spec.PodName] = r
+		}
+	}
+	if len(reported) == 0 {
+		return []*kapiv1.Pod{}, nil
+	}
+
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	result := []*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		r, ok := reported[p.Name]
+		if !ok {
+			continue
+		}
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			continue
+		}
+		d.logger.Sugar().Infof("the pod %s is out of bounds: %s anomaly reported by %s in %s: %s", p.Name, r.Spec.Severity, r.Spec.Source, r.Name, r.Spec.Reason)
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// matches returns true if the report is not expired and passes the source and severity filter
+func (d *AnomalyReportAnalyser) matches(r *api.PodAnomalyReport, now time.Time) bool {
+	if !r.Spec.Expiry.Time.After(now) {
+		return false
+	}
+	if len(d.Sources) > 0 && !ContainsString(d.Sources, r.Spec.Source) {
+		return false
+	}
+	severity, ok := severityLevels[r.Spec.Severity]
+	if !ok {
+		d.logger.Sugar().Warnf("the pod anomaly report %s has an invalid severity %q", r.Name, r.Spec.Severity)
+		return false
+	}
+	return severity >= severityLevels[d.MinSeverity]
+}
@@ -0,0 +1,125 @@
+package anomalydetector
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func reportGen(name, podName, source string, severity api.PodAnomalyReportSeverity, expiry time.Time) *api.PodAnomalyReport {
+	return &api.PodAnomalyReport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec: api.PodAnomalyReportSpec{
+			PodName:  podName,
+			Source:   source,
+			Severity: severity,
+			Reason:   "reported",
+			Expiry:   metav1.NewTime(expiry),
+		},
+	}
+}
+
+func TestAnomalyReportAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devLogger, _ := zap.NewDevelopment()
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo),
+		test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("E", "test-ns", map[string]string{"app": "bar"}, nil, true, true, labeling.LabelTrafficYes),
+	}
+
+	tests := []struct {
+		name    string
+		config  api.AnomalyReport
+		reports []*api.PodAnomalyReport
+		want    []string
+	}{
+		{
+			name:   "no report",
+			config: *api.DefaultAnomalyReport(&api.AnomalyReport{}),
+			want:   []string{},
+		},
+		{
+			name:   "unexpired reports",
+			config: *api.DefaultAnomalyReport(&api.AnomalyReport{}),
+			reports: []*api.PodAnomalyReport{
+				reportGen("r1", "A", "lb", api.PodAnomalyReportSeverityWarning, now.Add(time.Minute)),
+				reportGen("r2", "B", "lb", api.PodAnomalyReportSeverityCritical, now.Add(-time.Minute)),
+				reportGen("r3", "D", "synthetic", api.PodAnomalyReportSeverityCritical, now.Add(time.Minute)),
+			},
+			want: []string{"A", "D"},
+		},
+		{
+			name:   "no traffic, not selected or unknown pod",
+			config: *api.DefaultAnomalyReport(&api.AnomalyReport{}),
+			reports: []*api.PodAnomalyReport{
+				reportGen("r1", "C", "lb", api.PodAnomalyReportSeverityCritical, now.Add(time.Minute)),
+				reportGen("r2", "E", "lb", api.PodAnomalyReportSeverityCritical, now.Add(time.Minute)),
+				reportGen("r3", "Z", "lb", api.PodAnomalyReportSeverityCritical, now.Add(time.Minute)),
+			},
+			want: []string{},
+		},
+		{
+			name:   "source filter",
+			config: *api.DefaultAnomalyReport(&api.AnomalyReport{Sources: []string{"synthetic"}}),
+			reports: []*api.PodAnomalyReport{
+				reportGen("r1", "A", "lb", api.PodAnomalyReportSeverityCritical, now.Add(time.Minute)),
+				reportGen("r2", "D", "synthetic", api.PodAnomalyReportSeverityWarning, now.Add(time.Minute)),
+			},
+			want: []string{"D"},
+		},
+		{
+			name:   "severity filter",
+			config: *api.DefaultAnomalyReport(&api.AnomalyReport{MinSeverity: api.PodAnomalyReportSeverityCritical}),
+			reports: []*api.PodAnomalyReport{
+				reportGen("r1", "A", "lb", api.PodAnomalyReportSeverityWarning, now.Add(time.Minute)),
+				reportGen("r2", "B", "lb", api.PodAnomalyReportSeverityInfo, now.Add(time.Minute)),
+				reportGen("r3", "B", "lb", api.PodAnomalyReportSeverityCritical, now.Add(time.Minute)),
+				reportGen("r4", "D", "lb", "fatal", now.Add(time.Minute)),
+			},
+			want: []string{"B"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, r := range tt.reports {
+				index.Add(r)
+			}
+			d := &AnomalyReportAnalyser{
+				AnomalyReport: tt.config,
+				selector:      labels.SelectorFromSet(map[string]string{"app": "foo"}),
+				podLister:     test.NewTestPodNamespaceLister(pods, "test-ns"),
+				reportLister:  blisters.NewPodAnomalyReportLister(index).PodAnomalyReports("test-ns"),
+				logger:        devLogger,
+				now:           func() time.Time { return now },
+			}
+			got, err := d.GetPodsOutOfBounds()
+			if err != nil {
+				t.Fatalf("AnomalyReportAnalyser.GetPodsOutOfBounds() error = %v", err)
+			}
+			names := []string{}
+			for _, p := range got {
+				names = append(names, p.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("AnomalyReportAnalyser.GetPodsOutOfBounds() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
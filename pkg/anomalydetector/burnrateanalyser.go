@@ -0,0 +1,148 @@
+package anomalydetector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	promApi "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &BurnRateAnalyser{}
+
+// goodTotal number of good events and number of events
+type goodTotal struct {
+	good  float64
+	total float64
+}
+
+// burnRate returns the error ratio divided by the error budget, false if there is no event
+func (c goodTotal) burnRate(budget float64) (float64, bool) {
+	if c.total <= 0 {
+		return 0, false
+	}
+	errorRatio := 1 - c.good/c.total
+	if errorRatio < 0 {
+		// counters scraped at different times
+		errorRatio = 0
+	}
+	return errorRatio / budget, true
+}
+
+//BurnRateAnalyser anomalyDetector that checks the burn rate of the SLO error budget of each pod over a short and a long window
+type BurnRateAnalyser struct {
+	api.BurnRate
+	queryAPI  promApi.API
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	logger    *zap.Logger
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *BurnRateAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+	// only the pods with traffic are evaluated and part of the fleet
+	listOfPods, err = pod.KeepWithTrafficYesPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't keep pods with traffic, error:%v", err)
+	}
+
+	short, err := d.counters(d.ShortWindow)
+	if err != nil {
+		return nil, err
+	}
+	long, err := d.counters(d.LongWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := 1 - *d.Objective
+	var shortFleet, longFleet goodTotal
+	for _, p := range listOfPods {
+		shortFleet.good += short[p.Name].good
+		shortFleet.total += short[p.Name].total
+		longFleet.good += long[p.Name].good
+		longFleet.total += long[p.Name].total
+	}
+	shortFleetRate, _ := shortFleet.burnRate(budget)
+	longFleetRate, _ := longFleet.burnRate(budget)
+
+	result := []*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		if short[p.Name].total < float64(*d.MinimumActivityCount) {
+			continue
+		}
+		shortRate, ok := short[p.Name].burnRate(budget)
+		if !ok || shortRate <= *d.MaxBurnRate {
+			continue
+		}
+		longRate, ok := long[p.Name].burnRate(budget)
+		if !ok || longRate <= *d.MaxBurnRate {
+			continue
+		}
+		if d.MaxFleetRatio != nil && (shortRate <= *d.MaxFleetRatio*shortFleetRate || longRate <= *d.MaxFleetRatio*longFleetRate) {
+			d.logger.Sugar().Debugf("the pod %s burns the budget like the fleet: %.1f/%.1f for a fleet at %.1f/%.1f", p.Name, shortRate, longRate, shortFleetRate, longFleetRate)
+			continue
+		}
+		d.logger.Sugar().Infof("the pod %s is out of bounds: burn rate %.1f over %s and %.1f over %s", p.Name, shortRate, d.ShortWindow, longRate, d.LongWindow)
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// counters returns by pod name the good and total counts over the window
+func (d *BurnRateAnalyser) counters(window string) (map[string]goodTotal, error) {
+	good, err := d.query(strings.Replace(d.GoodQuery, api.BurnRateWindowPlaceholder, window, -1))
+	if err != nil {
+		return nil, fmt.Errorf("good query over %s: %v", window, err)
+	}
+	total, err := d.query(strings.Replace(d.TotalQuery, api.BurnRateWindowPlaceholder, window, -1))
+	if err != nil {
+		return nil, fmt.Errorf("total query over %s: %v", window, err)
+	}
+	result := map[string]goodTotal{}
+	for podName, value := range total {
+		result[podName] = goodTotal{good: good[podName], total: value}
+	}
+	return result, nil
+}
+
+// query runs the promQL and returns the value by pod name
+func (d *BurnRateAnalyser) query(promQL string) (map[string]float64, error) {
+	m, err := d.queryAPI.Query(context.Background(), promQL, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error processing prometheus query: %s", err)
+	}
+	vector, ok := m.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("the prometheus query did not return a result in the form of expected type 'model.Vector'")
+	}
+	result := map[string]float64{}
+	for _, sample := range vector {
+		value := float64(sample.Value)
+		if math.IsNaN(value) {
+			continue
+		}
+		result[string(sample.Metric[model.LabelName(d.PodNameKey)])] = value
+	}
+	return result, nil
+}
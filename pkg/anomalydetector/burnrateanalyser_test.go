@@ -0,0 +1,133 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	promApi "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func TestBurnRateAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+
+	pods := []*kapiv1.Pod{}
+	for _, name := range []string{"A", "B", "C", "D"} {
+		pods = append(pods, test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes))
+	}
+	pods = append(pods, test.PodGen("N", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo))
+
+	// budget 1%: B burns 20 then 15 times too fast, C only over the short window, D 15 then 20 times
+	values := map[string]model.Value{
+		`sum(increase(requests_total{code!~"5.."}[5m])) by (pod)`: podVector(map[string]float64{"A": 99, "B": 80, "C": 80, "D": 85, "N": 0}),
+		`sum(increase(requests_total[5m])) by (pod)`:              podVector(map[string]float64{"A": 100, "B": 100, "C": 100, "D": 100, "N": 100}),
+		`sum(increase(requests_total{code!~"5.."}[1h])) by (pod)`: podVector(map[string]float64{"A": 990, "B": 850, "C": 950, "D": 800}),
+		`sum(increase(requests_total[1h])) by (pod)`:              podVector(map[string]float64{"A": 1000, "B": 1000, "C": 1000, "D": 1000}),
+	}
+	config := *api.DefaultBurnRate(&api.BurnRate{
+		PrometheusService: "prometheus:9090",
+		GoodQuery:         `sum(increase(requests_total{code!~"5.."}[$window])) by (pod)`,
+		TotalQuery:        `sum(increase(requests_total[$window])) by (pod)`,
+		PodNameKey:        "pod",
+		Objective:         api.NewFloat64(0.99),
+		MaxBurnRate:       api.NewFloat64(10),
+	})
+	withFleetRatio := func(ratio float64) api.BurnRate {
+		c := *config.DeepCopy()
+		c.MaxFleetRatio = api.NewFloat64(ratio)
+		return c
+	}
+	withMinActivity := *config.DeepCopy()
+	withMinActivity.MinimumActivityCount = api.NewUInt(150)
+
+	tests := []struct {
+		name    string
+		config  api.BurnRate
+		qAPI    promApi.API
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "both windows",
+			config: config,
+			qAPI:   &testQueryPrometheusAPI{values: values},
+			want:   []string{"B", "D"},
+		},
+		{
+			name:   "faster than the fleet",
+			config: withFleetRatio(1.2),
+			qAPI:   &testQueryPrometheusAPI{values: values},
+			want:   []string{"B"},
+		},
+		{
+			name:   "fleet-wide burn",
+			config: withFleetRatio(2),
+			qAPI:   &testQueryPrometheusAPI{values: values},
+			want:   []string{},
+		},
+		{
+			name:   "minimum activity",
+			config: withMinActivity,
+			qAPI:   &testQueryPrometheusAPI{values: values},
+			want:   []string{},
+		},
+		{
+			name:    "query error",
+			config:  config,
+			qAPI:    &testQueryPrometheusAPI{testPrometheusAPI: testPrometheusAPI{err: fmt.Errorf("A prom Error")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &BurnRateAnalyser{
+				BurnRate:  tt.config,
+				queryAPI:  tt.qAPI,
+				selector:  labels.Everything(),
+				podLister: test.NewTestPodNamespaceLister(pods, "test-ns"),
+				logger:    devlogger,
+			}
+			got, err := d.GetPodsOutOfBounds()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BurnRateAnalyser.GetPodsOutOfBounds() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(podNames(got), tt.want) {
+				t.Errorf("BurnRateAnalyser.GetPodsOutOfBounds() = %v, want %v", podNames(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_goodTotal_burnRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		counters goodTotal
+		want     float64
+		wantOk   bool
+	}{
+		{name: "no event", counters: goodTotal{}, wantOk: false},
+		{name: "no error", counters: goodTotal{good: 10, total: 10}, want: 0, wantOk: true},
+		{name: "good counted after total", counters: goodTotal{good: 11, total: 10}, want: 0, wantOk: true},
+		{name: "half errors", counters: goodTotal{good: 5, total: 10}, want: 50, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.counters.burnRate(0.01)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("goodTotal.burnRate() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package anomalydetector
+
+import "math"
+
+// zScore returns the standard normal quantile of the two-sided confidence level. example: 0.95 -> 1.96
+func zScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// wilsonLowerBound returns the lower bound of the Wilson score interval of the proportion ko/n
+// Unlike ko/n, it stays low when there are few events: 3/5 gives 0.23 and 3000/5000 gives 0.59 at 95% confidence
+func wilsonLowerBound(ko, n uint, z float64) float64 {
+	if n == 0 {
+		return 0
+	}
+	total := float64(n)
+	p := float64(ko) / total
+	z2 := z * z
+	center := p + z2/(2*total)
+	margin := z * math.Sqrt(p*(1-p)/total+z2/(4*total*total))
+	return (center - margin) / (1 + z2/total)
+}
+
+// isSignificantlyWorse returns true if the ko proportion of the pod is greater than the one of the other pods, with a one-sided two-proportion z-test
+func isSignificantlyWorse(pod, others okkoCount, z float64) bool {
+	n1, n2 := float64(pod.ok+pod.ko), float64(others.ok+others.ko)
+	if n1 == 0 || n2 == 0 {
+		return false
+	}
+	p1, p2 := float64(pod.ko)/n1, float64(others.ko)/n2
+	if p1 <= p2 {
+		return false
+	}
+	pooled := float64(pod.ko+others.ko) / (n1 + n2)
+	stdErr := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if stdErr == 0 {
+		return false
+	}
+	return (p1-p2)/stdErr > z
+}
@@ -0,0 +1,68 @@
+package anomalydetector
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_zScore(t *testing.T) {
+	tests := []struct {
+		confidence float64
+		want       float64
+	}{
+		{confidence: 0.90, want: 1.645},
+		{confidence: 0.95, want: 1.960},
+		{confidence: 0.99, want: 2.576},
+	}
+	for _, tt := range tests {
+		if got := zScore(tt.confidence); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("zScore(%v) = %v, want %v", tt.confidence, got, tt.want)
+		}
+	}
+}
+
+func Test_wilsonLowerBound(t *testing.T) {
+	z := zScore(0.95)
+	tests := []struct {
+		name  string
+		ko, n uint
+		want  float64
+	}{
+		{name: "no event", ko: 0, n: 0, want: 0},
+		{name: "no error", ko: 0, n: 100, want: 0},
+		{name: "low volume", ko: 3, n: 5, want: 0.2307},
+		{name: "high volume", ko: 3000, n: 5000, want: 0.5864},
+		{name: "all errors", ko: 10, n: 10, want: 0.7225},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wilsonLowerBound(tt.ko, tt.n, z); math.Abs(got-tt.want) > 0.0001 {
+				t.Errorf("wilsonLowerBound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isSignificantlyWorse(t *testing.T) {
+	z := zScore(0.95)
+	tests := []struct {
+		name   string
+		pod    okkoCount
+		others okkoCount
+		want   bool
+	}{
+		{name: "no other event", pod: okkoCount{ok: 10, ko: 10}, others: okkoCount{}, want: false},
+		{name: "same rate", pod: okkoCount{ok: 90, ko: 10}, others: okkoCount{ok: 900, ko: 100}, want: false},
+		{name: "better", pod: okkoCount{ok: 100, ko: 0}, others: okkoCount{ok: 900, ko: 100}, want: false},
+		{name: "slightly worse low volume", pod: okkoCount{ok: 3, ko: 1}, others: okkoCount{ok: 900, ko: 100}, want: false},
+		{name: "significantly worse", pod: okkoCount{ok: 70, ko: 30}, others: okkoCount{ok: 900, ko: 100}, want: true},
+		{name: "all pods failing", pod: okkoCount{ok: 0, ko: 10}, others: okkoCount{ok: 0, ko: 100}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSignificantlyWorse(tt.pod, tt.others, z); got != tt.want {
+				t.Errorf("isSignificantlyWorse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
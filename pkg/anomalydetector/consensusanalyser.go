@@ -0,0 +1,215 @@
+package anomalydetector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &ConsensusAnalyser{}
+
+// fetcher sends one request to the address (host:port) of a pod and returns the response body
+type fetcher interface {
+	fetch(ctx context.Context, addr string) ([]byte, error)
+}
+
+//ConsensusAnalyser anomalyDetector that sends the same request to each pod and returns the pods that disagree with the majority answer
+type ConsensusAnalyser struct {
+	api.Consensus
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	logger    *zap.Logger
+	fetcher   fetcher
+	address   func(p *kapiv1.Pod) string
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *ConsensusAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	toRequest := []*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			d.logger.Sugar().Debugf("the pod %s is not requested as it is marked out of traffic\n", p.Name)
+			continue
+		}
+		if p.Status.PodIP == "" {
+			continue
+		}
+		toRequest = append(toRequest, p)
+	}
+	if len(toRequest) == 0 {
+		return []*kapiv1.Pod{}, nil
+	}
+
+	answers := d.requestAll(toRequest)
+
+	// pods without answer are not counted in any group
+	counts := map[string]int{}
+	majority, majorityCount := "", 0
+	for _, answer := range answers {
+		counts[answer]++
+		if counts[answer] > majorityCount {
+			majority, majorityCount = answer, counts[answer]
+		}
+	}
+	if uint(majorityCount*100) < *d.QuorumPercent*uint(len(toRequest)) {
+		d.logger.Sugar().Warnf("no clear majority: the most common answer is given by %d pods out of %d", majorityCount, len(toRequest))
+		return []*kapiv1.Pod{}, nil
+	}
+
+	result := []*kapiv1.Pod{}
+	for _, p := range toRequest {
+		answer, ok := answers[p.Name]
+		if !ok {
+			if d.BreakUnanswered {
+				d.logger.Sugar().Infof("the pod %s is out of bounds: no answer", p.Name)
+				result = append(result, p)
+			}
+			continue
+		}
+		if answer != majority {
+			d.logger.Sugar().Infof("the pod %s is out of bounds: its answer differs from the answer of %d pods out of %d", p.Name, majorityCount, len(toRequest))
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// requestAll requests the pods with a bounded concurrency and returns the normalized answers by pod name. Pods with a failed request have no answer
+func (d *ConsensusAnalyser) requestAll(pods []*kapiv1.Pod) map[string]string {
+	timeout := time.Duration(*d.Timeout*1000) * time.Millisecond
+	answers := map[string]string{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, *d.MaxConcurrentRequests)
+	for _, p := range pods {
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(p *kapiv1.Pod) {
+			defer wg.Done()
+			defer func() { <-tokens }()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			body, err := d.fetcher.fetch(ctx, d.address(p))
+			if err != nil {
+				d.logger.Sugar().Debugf("request of pod %s failed: %v", p.Name, err)
+				return
+			}
+			answer, err := normalizeAnswer(body, d.JSONPaths, d.RoundingDigits)
+			if err != nil {
+				d.logger.Sugar().Debugf("can't normalize the answer of pod %s: %v", p.Name, err)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			answers[p.Name] = answer
+		}(p)
+	}
+	wg.Wait()
+	return answers
+}
+
+// normalizeAnswer returns a canonical form of the body so that equivalent answers are equal.
+// Without JSON paths nor rounding a non-JSON body is compared as is, whitespaces trimmed
+func normalizeAnswer(body []byte, jsonPaths []string, roundingDigits *uint) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		if len(jsonPaths) == 0 && roundingDigits == nil {
+			return string(bytes.TrimSpace(body)), nil
+		}
+		return "", fmt.Errorf("invalid json answer: %v", err)
+	}
+
+	selected := []interface{}{value}
+	if len(jsonPaths) > 0 {
+		selected = make([]interface{}, 0, len(jsonPaths))
+		for _, path := range jsonPaths {
+			v, err := selectJSONPath(value, path)
+			if err != nil {
+				return "", err
+			}
+			selected = append(selected, v)
+		}
+	}
+	if roundingDigits != nil {
+		for i := range selected {
+			selected[i] = roundNumbers(selected[i], *roundingDigits)
+		}
+	}
+
+	// the keys of the JSON objects are sorted by the marshaller
+	canonical, err := json.Marshal(selected)
+	if err != nil {
+		return "", err
+	}
+	return string(canonical), nil
+}
+
+// selectJSONPath returns the value at the dot separated path. Array elements are selected with their index
+func selectJSONPath(value interface{}, path string) (interface{}, error) {
+	current := value
+	for _, field := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[field]
+			if !ok {
+				return nil, fmt.Errorf("no field %q for path %q", field, path)
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(field)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("invalid index %q for path %q", field, path)
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("can't select %q in a scalar value for path %q", field, path)
+		}
+	}
+	return current, nil
+}
+
+// roundNumbers rounds all the numbers of the JSON value to the given number of decimals
+func roundNumbers(value interface{}, digits uint) interface{} {
+	switch v := value.(type) {
+	case float64:
+		scale := math.Pow10(int(digits))
+		return math.Floor(v*scale+0.5) / scale
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = roundNumbers(item, digits)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = roundNumbers(item, digits)
+		}
+	}
+	return value
+}
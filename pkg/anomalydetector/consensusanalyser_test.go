@@ -0,0 +1,134 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func TestConsensusAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	answer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, body) }))
+	}
+	servers := map[string]*httptest.Server{
+		"A": answer(`{"requestInfo":{"id":"1"},"solutions":[{"price":10.001,"currency":"EUR"}]}`),
+		"B": answer(`{"solutions":[{"currency":"EUR","price":10.002}],"requestInfo":{"id":"2"}}`),
+		"E": answer(`{"requestInfo":{"id":"5"},"solutions":[{"currency":"EUR","price":10}]}`),
+		"C": answer(`{"requestInfo":{"id":"3"},"solutions":[{"price":12,"currency":"EUR"}]}`),
+		"D": httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) })),
+	}
+	for _, s := range servers {
+		defer s.Close()
+	}
+
+	tests := []struct {
+		name   string
+		pods   []string
+		config api.Consensus
+		want   []string
+	}{
+		{
+			name:   "minority answer and failed request",
+			pods:   []string{"A", "B", "C", "D", "E"},
+			config: api.Consensus{JSONPaths: []string{"solutions.0"}, RoundingDigits: api.NewUInt(2)},
+			want:   []string{"C"},
+		},
+		{
+			name:   "minority answer and failed request, break unanswered",
+			pods:   []string{"A", "B", "C", "D", "E"},
+			config: api.Consensus{JSONPaths: []string{"solutions.0"}, RoundingDigits: api.NewUInt(2), BreakUnanswered: true},
+			want:   []string{"C", "D"},
+		},
+		{
+			name:   "no rounding",
+			pods:   []string{"A", "B", "C"},
+			config: api.Consensus{JSONPaths: []string{"solutions.0"}},
+			want:   []string{},
+		},
+		{
+			name:   "quorum not reached",
+			pods:   []string{"A", "B", "C", "D", "E"},
+			config: api.Consensus{JSONPaths: []string{"solutions.0"}, RoundingDigits: api.NewUInt(2), QuorumPercent: api.NewUInt(75)},
+			want:   []string{},
+		},
+		{
+			name:   "whole answer",
+			pods:   []string{"A", "B", "C"},
+			config: api.Consensus{RoundingDigits: api.NewUInt(0)},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := []*kapiv1.Pod{}
+			for _, name := range tt.pods {
+				pods = append(pods, probedPodGen(name))
+			}
+			tt.config.Port = 8080
+			config := *api.DefaultConsensus(&tt.config)
+			f, _ := newHTTPProber(config.HTTP)
+			d := &ConsensusAnalyser{
+				Consensus: config,
+				selector:  labels.Everything(),
+				podLister: test.NewTestPodNamespaceLister(pods, "test-ns"),
+				logger:    devlogger,
+				fetcher:   f,
+				address:   func(p *kapiv1.Pod) string { return strings.TrimPrefix(servers[p.Name].URL, "http://") },
+			}
+			got, err := d.GetPodsOutOfBounds()
+			if err != nil {
+				t.Fatalf("ConsensusAnalyser.GetPodsOutOfBounds() unexpected error: %v", err)
+			}
+			gotNames := []string{}
+			for _, p := range got {
+				gotNames = append(gotNames, p.Name)
+			}
+			sort.Strings(gotNames)
+			if strings.Join(gotNames, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ConsensusAnalyser.GetPodsOutOfBounds() = %v, want %v", gotNames, tt.want)
+			}
+		})
+	}
+}
+
+func Test_normalizeAnswer(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		jsonPaths []string
+		digits    *uint
+		want      string
+		wantErr   bool
+	}{
+		{name: "plain text", body: " OK\n", want: "OK"},
+		{name: "keys order", body: `{"b":1,"a":[true,null]}`, want: `[{"a":[true,null],"b":1}]`},
+		{name: "paths", body: `{"a":{"b":[1,{"c":"x"}]},"d":2}`, jsonPaths: []string{"a.b.1.c", "d"}, want: `["x",2]`},
+		{name: "rounding", body: `{"a":[1.234,5.678]}`, digits: api.NewUInt(1), want: `[{"a":[1.2,5.7]}]`},
+		{name: "missing field", body: `{"a":1}`, jsonPaths: []string{"b"}, wantErr: true},
+		{name: "out of range index", body: `{"a":[1]}`, jsonPaths: []string{"a.1"}, wantErr: true},
+		{name: "field of a scalar", body: `{"a":1}`, jsonPaths: []string{"a.b"}, wantErr: true},
+		{name: "not json", body: `OK`, jsonPaths: []string{"a"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeAnswer([]byte(tt.body), tt.jsonPaths, tt.digits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeAnswer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeAnswer() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
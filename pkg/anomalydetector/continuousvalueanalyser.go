@@ -8,10 +8,10 @@ import (
 	kapiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
 	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
-	"github.com/amadeusitgroup/kubervisor/pkg/pod"
 )
 
 var _ AnomalyDetector = &ContinuousValueDeviationAnalyser{}
@@ -25,44 +25,29 @@ type continuousValueAnalyser interface {
 //ContinuousValueDeviationAnalyser anomalyDetector that check the deviation of a continous value compare to average
 type ContinuousValueDeviationAnalyser struct {
 	api.ContinuousValueDeviation
-	selector  labels.Selector
-	analyser  continuousValueAnalyser
-	podLister kv1.PodNamespaceLister
-	logger    *zap.Logger
+	selector   labels.Selector
+	analyser   continuousValueAnalyser
+	namespace  string
+	podLister  kv1.PodNamespaceLister
+	podIndexer cache.Indexer
+	logger     *zap.Logger
 }
 
 //GetPodsOutOfBounds implements interface AnomalyDetector
 func (d *ContinuousValueDeviationAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
-	listOfPods, err := d.podLister.List(d.selector)
+	index, err := newPodIndex(d.PodMatch, d.namespace, d.selector, d.podLister, d.podIndexer)
 	if err != nil {
-		return nil, fmt.Errorf("can't list pods, error:%v", err)
-	}
-	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
-	if err != nil {
-		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
-	}
-	podByName := map[string]*kapiv1.Pod{}
-	podWithNoTraffic := map[string]*kapiv1.Pod{}
-
-	for _, p := range listOfPods {
-		podByName[p.Name] = p
-		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
-		if err2 != nil {
-			return nil, err2
-		}
-		if !traffic {
-			podWithNoTraffic[p.Name] = p
-		}
+		return nil, err
 	}
 
 	result := []*kapiv1.Pod{}
-	deviationByPods, err := d.analyser.doAnalysis()
+	deviationByMetric, err := d.analyser.doAnalysis()
 	if err != nil {
 		return nil, err
 	}
-	d.logger.Sugar().Debugf("Number of PODs reporting metrics:%d\n", len(deviationByPods))
+	d.logger.Sugar().Debugf("Number of PODs reporting metrics:%d\n", len(deviationByMetric))
 
-	if len(deviationByPods) == 0 {
+	if len(deviationByMetric) == 0 {
 		return result, nil
 	}
 
@@ -72,18 +57,27 @@ func (d *ContinuousValueDeviationAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod,
 		return nil, fmt.Errorf("maxDeviation=0 for continuous value analysis")
 	}
 
-	for podName, deviation := range deviationByPods {
-		_, found := podWithNoTraffic[podName]
-		if found {
-			d.logger.Sugar().Infof("the pod %s metrics are ignored now has it is marked out of traffic\n", podName)
+	// several metric values can identify the same pod: the pod is out of bounds if one of them deviates too much
+	outOfBounds := map[string]bool{}
+	for metricValue, deviation := range deviationByMetric {
+		if math.Abs(1-deviation) <= maxDeviation {
 			continue
 		}
-
-		if math.Abs(1-deviation) > maxDeviation {
-			if p, ok := podByName[podName]; ok {
-				// Only keeping known pod with too hig deviation
-				result = append(result, p)
+		for _, p := range index.lookup(metricValue) {
+			if outOfBounds[p.Name] {
+				continue
+			}
+			traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+			if err2 != nil {
+				return nil, err2
+			}
+			if !traffic {
+				d.logger.Sugar().Infof("the pod %s metrics are ignored now has it is marked out of traffic\n", p.Name)
+				continue
 			}
+			// Only keeping known pod with too hig deviation
+			outOfBounds[p.Name] = true
+			result = append(result, p)
 		}
 	}
 	return result, nil
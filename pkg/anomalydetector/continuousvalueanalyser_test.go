@@ -120,6 +120,28 @@ func TestContinuousValueDeviationAnalyser_GetPodsOutOfBounds(t *testing.T) {
 			want:    []*kapiv1.Pod{test.PodGen("C", "test-ns", map[string]string{"app": "bar", "phase": "pdt"}, nil, true, true, labeling.LabelTrafficYes)},
 			wantErr: false,
 		},
+		{
+			name: "deviation by 70% match by label",
+			fields: fields{
+				ContinuousValueDeviation: *api.DefaultContinuousValueDeviation(&api.ContinuousValueDeviation{MaxDeviationPercent: api.NewFloat64(50.0), PodMatch: &api.PodMatch{By: api.PodMatchByLabel, Label: "instance"}}),
+				selector:                 labels.Everything(),
+				analyser: &testContinuousValueAnalyser{
+					deviationByPodName: deviationByPodName{
+						"a": 1.1,
+						"b": 1.2,
+						"c": 0.2,
+						"C": 0.2,
+					},
+				},
+				podLister: test.NewTestPodNamespaceLister(
+					[]*kapiv1.Pod{
+						test.PodGen("A", "test-ns", map[string]string{"instance": "a"}, nil, true, true, labeling.LabelTrafficYes),
+						test.PodGen("B", "test-ns", map[string]string{"instance": "b"}, nil, true, true, labeling.LabelTrafficYes),
+						test.PodGen("C", "test-ns", map[string]string{"instance": "c"}, nil, true, true, labeling.LabelTrafficYes)}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{test.PodGen("C", "test-ns", map[string]string{"instance": "c"}, nil, true, true, labeling.LabelTrafficYes)},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -127,7 +149,9 @@ func TestContinuousValueDeviationAnalyser_GetPodsOutOfBounds(t *testing.T) {
 				ContinuousValueDeviation: tt.fields.ContinuousValueDeviation,
 				selector:                 tt.fields.selector,
 				analyser:                 tt.fields.analyser,
+				namespace:                "test-ns",
 				podLister:                tt.fields.podLister,
+				podIndexer:               testPodIndexer(tt.fields.podLister),
 				logger:                   devlogger,
 			}
 			got, err := d.GetPodsOutOfBounds()
@@ -0,0 +1,84 @@
+package anomalydetector
+
+import (
+	"github.com/prometheus/common/model"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+)
+
+// counterSource provides the counters increase of the pods, without prometheus
+type counterSource interface {
+	// deltas returns by pod name the increase of the series with one of the given names
+	deltas(names ...string) (map[string]model.Vector, error)
+}
+
+// counterDiscreteValueOutOfListAnalyser classifies the increase of the counter series of each pod with the discrete value carried by the Key label
+type counterDiscreteValueOutOfListAnalyser struct {
+	config           api.DiscreteValueOutOfList
+	metric           string
+	source           counterSource
+	valueCheckerFunc func(value string) (ok bool)
+	rules            *discreteValueRules
+}
+
+func (s *counterDiscreteValueOutOfListAnalyser) doAnalysis() (okkoByPodName, error) {
+	deltasByPod, err := s.source.deltas(s.metric)
+	if err != nil {
+		return nil, err
+	}
+	countersByPods := okkoByPodName{}
+	for podName, vector := range deltasByPod {
+		counters := okkoCount{}
+		for _, sample := range vector {
+			counters.add(classifySample(sample.Metric, s.config.Key, s.valueCheckerFunc, s.rules), sample.Value)
+		}
+		countersByPods[podName] = counters
+	}
+	return countersByPods, nil
+}
+
+// counterContinuousValueDeviationAnalyser compares the average value of each pod, computed from the <metric>_sum and <metric>_count increase, to the fleet average
+type counterContinuousValueDeviationAnalyser struct {
+	config api.ContinuousValueDeviation
+	metric string
+	source counterSource
+}
+
+func (s *counterContinuousValueDeviationAnalyser) doAnalysis() (deviationByPodName, error) {
+	sumName, countName := model.LabelValue(s.metric+"_sum"), model.LabelValue(s.metric+"_count")
+	deltasByPod, err := s.source.deltas(string(sumName), string(countName))
+	if err != nil {
+		return nil, err
+	}
+
+	type sumCount struct{ sum, count float64 }
+	byPod := map[string]sumCount{}
+	fleet := sumCount{}
+	for podName, vector := range deltasByPod {
+		sc := sumCount{}
+		for _, sample := range vector {
+			switch sample.Metric[model.MetricNameLabel] {
+			case sumName:
+				sc.sum += float64(sample.Value)
+			case countName:
+				sc.count += float64(sample.Value)
+			}
+		}
+		if sc.count == 0 {
+			continue
+		}
+		byPod[podName] = sc
+		fleet.sum += sc.sum
+		fleet.count += sc.count
+	}
+
+	result := deviationByPodName{}
+	if fleet.count == 0 || fleet.sum == 0 {
+		return result, nil
+	}
+	fleetAverage := fleet.sum / fleet.count
+	for podName, sc := range byPod {
+		result[podName] = (sc.sum / sc.count) / fleetAverage
+	}
+	return result, nil
+}
@@ -0,0 +1,181 @@
+package anomalydetector
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	v2 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/v2"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+// maxCustomResponseSize maximum number of bytes read from the response of a custom detector
+const maxCustomResponseSize = 4 << 20
+
+// headersSecretTTL duration during which the headers read from the secret are reused
+const headersSecretTTL = time.Minute
+
+var _ AnomalyDetector = &CustomAnomalyDetectorV2{}
+
+//CustomAnomalyDetectorV2 call an external service with the v2 protocol: the service receives the managed pods and answers a verdict for each of them
+type CustomAnomalyDetectorV2 struct {
+	api.CustomDetector
+	kubervisorName string
+	namespace      string
+	strategyName   string
+	selector       labels.Selector
+	podLister      kv1.PodNamespaceLister
+	logger         *zap.Logger
+	client         *http.Client
+
+	// headers returns the headers added to each request
+	headers func() (map[string]string, error)
+}
+
+func newCustomAnomalyDetectorV2Client(config api.CustomDetector) *http.Client {
+	transport := new(http.Transport)
+	setDefaults(transport, http.DefaultTransport)
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	return &http.Client{
+		Timeout:   time.Duration(*config.Timeout*1000) * time.Millisecond,
+		Transport: transport,
+	}
+}
+
+// secretHeaders reads the headers from the secret at most once per headersSecretTTL, so that a rotated credential is used without restart
+func secretHeaders(kubeClient clientset.Interface, namespace, name string, now func() time.Time) func() (map[string]string, error) {
+	var lock sync.Mutex
+	var headers map[string]string
+	var readTime time.Time
+	return func() (map[string]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if headers != nil && now().Sub(readTime) < headersSecretTTL {
+			return headers, nil
+		}
+		secret, err := kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("can't get the headers secret %s/%s: %v", namespace, name, err)
+		}
+		headers = map[string]string{}
+		for k, v := range secret.Data {
+			headers[k] = string(v)
+		}
+		readTime = now()
+		return headers, nil
+	}
+}
+
+//GetPodsOutOfBounds implements the anomaly detector interface
+func (c *CustomAnomalyDetectorV2) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := c.podLister.List(c.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	request := v2.Request{
+		APIVersion:        v2.APIVersion,
+		Namespace:         c.namespace,
+		KubervisorService: c.kubervisorName,
+		Strategy:          c.strategyName,
+		Pods:              []v2.Pod{},
+	}
+	podsByName := map[string]*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			continue
+		}
+		podsByName[p.Name] = p
+		request.Pods = append(request.Pods, v2.Pod{Name: p.Name, IP: p.Status.PodIP})
+	}
+
+	response, err := c.call(request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*kapiv1.Pod{}
+	for _, v := range response.Pods {
+		p, ok := podsByName[v.Name]
+		if !ok {
+			c.logger.Sugar().Debugf("custom detector verdict for unknown pod %s is ignored", v.Name)
+			continue
+		}
+		switch v.Verdict {
+		case v2.VerdictOutOfBounds:
+			c.logger.Sugar().Infof("the pod %s is out of bounds: score %v, %s", v.Name, v.Score, v.Reason)
+			result = append(result, p)
+		case v2.VerdictOK, v2.VerdictUnknown:
+		default:
+			c.logger.Sugar().Warnf("custom detector returned an invalid verdict %q for pod %s", v.Verdict, v.Name)
+		}
+	}
+	return result, nil
+}
+
+func (c *CustomAnomalyDetectorV2) call(request v2.Request) (*v2.Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", v2.ContentType)
+	req.Header.Set("Accept", v2.ContentType)
+	if c.headers != nil {
+		headers, err := c.headers()
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error while contacting custom detector: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the custom detector did not respond Ok (200) but %d", resp.StatusCode)
+	}
+	responseBytes, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCustomResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("can't read response buffer %v", err)
+	}
+
+	response := &v2.Response{}
+	if err := json.Unmarshal(responseBytes, response); err != nil {
+		return nil, fmt.Errorf("decoding custom detector response failed: %v", err)
+	}
+	if response.APIVersion != v2.APIVersion {
+		return nil, fmt.Errorf("unsupported custom detector response apiVersion %q", response.APIVersion)
+	}
+	return response, nil
+}
@@ -0,0 +1,179 @@
+package anomalydetector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kfakeclient "k8s.io/client-go/kubernetes/fake"
+
+	v2 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/v2"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+type testDetectorV2 struct {
+	t        *testing.T
+	request  v2.Request
+	header   http.Header
+	response v2.Response
+}
+
+func (h *testDetectorV2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	h.header = r.Header
+	if err := json.NewDecoder(r.Body).Decode(&h.request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(h.response); err != nil {
+		h.t.Errorf("%v", err)
+	}
+}
+
+func TestCustomAnomalyDetectorV2_GetPodsOutOfBounds(t *testing.T) {
+	devLogger, _ := zap.NewDevelopment()
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo),
+	}
+	pods[0].Status.PodIP = "10.0.0.1"
+	pods[1].Status.PodIP = "10.0.0.2"
+
+	handler := &testDetectorV2{t: t}
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	kubeClient := kfakeclient.NewSimpleClientset(&kapiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "detector-auth", Namespace: "test-ns"},
+		Data:       map[string][]byte{"Authorization": []byte("Bearer token")},
+	})
+
+	config := *api.DefaultCustomDetector(&api.CustomDetector{URL: server.URL + "/analyse", InsecureSkipVerify: true})
+	c := &CustomAnomalyDetectorV2{
+		CustomDetector: config,
+		kubervisorName: "foo",
+		namespace:      "test-ns",
+		strategyName:   "strategy",
+		selector:       labels.Everything(),
+		podLister:      test.NewTestPodNamespaceLister(pods, "test-ns"),
+		logger:         devLogger,
+		client:         newCustomAnomalyDetectorV2Client(config),
+		headers:        secretHeaders(kubeClient, "test-ns", "detector-auth", time.Now),
+	}
+
+	tests := []struct {
+		name     string
+		response v2.Response
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "verdicts",
+			response: v2.Response{APIVersion: v2.APIVersion, Pods: []v2.PodVerdict{
+				{Name: "A", Verdict: v2.VerdictOK, Score: 0.1},
+				{Name: "B", Verdict: v2.VerdictOutOfBounds, Score: 0.9, Reason: "error rate 12%"},
+				{Name: "C", Verdict: v2.VerdictOutOfBounds},
+				{Name: "unknown", Verdict: v2.VerdictOutOfBounds},
+			}},
+			want: []string{"B"},
+		},
+		{
+			name:     "no verdict",
+			response: v2.Response{APIVersion: v2.APIVersion},
+			want:     []string{},
+		},
+		{
+			name:     "bad apiVersion",
+			response: v2.Response{APIVersion: "v1", Pods: []v2.PodVerdict{{Name: "B", Verdict: v2.VerdictOutOfBounds}}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler.response = tt.response
+			got, err := c.GetPodsOutOfBounds()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CustomAnomalyDetectorV2.GetPodsOutOfBounds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotNames := []string{}
+			for _, p := range got {
+				gotNames = append(gotNames, p.Name)
+			}
+			if !reflect.DeepEqual(gotNames, tt.want) {
+				t.Errorf("CustomAnomalyDetectorV2.GetPodsOutOfBounds() = %v, want %v", gotNames, tt.want)
+			}
+		})
+	}
+
+	wantRequest := v2.Request{
+		APIVersion:        v2.APIVersion,
+		Namespace:         "test-ns",
+		KubervisorService: "foo",
+		Strategy:          "strategy",
+		Pods:              []v2.Pod{{Name: "A", IP: "10.0.0.1"}, {Name: "B", IP: "10.0.0.2"}},
+	}
+	sort.Slice(handler.request.Pods, func(i, j int) bool { return handler.request.Pods[i].Name < handler.request.Pods[j].Name })
+	if !reflect.DeepEqual(handler.request, wantRequest) {
+		t.Errorf("request = %v, want %v", handler.request, wantRequest)
+	}
+	if got := handler.header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer token")
+	}
+
+	// the certificate of the detector is checked by default
+	c.client = newCustomAnomalyDetectorV2Client(*api.DefaultCustomDetector(&api.CustomDetector{URL: server.URL}))
+	if _, err := c.GetPodsOutOfBounds(); err == nil {
+		t.Errorf("CustomAnomalyDetectorV2.GetPodsOutOfBounds() expected a certificate error")
+	}
+}
+
+func Test_secretHeaders(t *testing.T) {
+	secret := &kapiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "detector-auth", Namespace: "test-ns"},
+		Data:       map[string][]byte{"Authorization": []byte("Bearer token")},
+	}
+	kubeClient := kfakeclient.NewSimpleClientset(secret)
+	now := time.Now()
+	headers := secretHeaders(kubeClient, "test-ns", "detector-auth", func() time.Time { return now })
+
+	check := func(want string) {
+		got, err := headers()
+		if err != nil {
+			t.Fatalf("secretHeaders() unexpected error: %v", err)
+		}
+		if got["Authorization"] != want {
+			t.Errorf("secretHeaders() Authorization = %q, want %q", got["Authorization"], want)
+		}
+	}
+
+	check("Bearer token")
+	rotated := secret.DeepCopy()
+	rotated.Data["Authorization"] = []byte("Bearer rotated")
+	if _, err := kubeClient.CoreV1().Secrets("test-ns").Update(rotated); err != nil {
+		t.Fatalf("can't update the secret: %v", err)
+	}
+	// the secret is not read again before the end of the TTL
+	check("Bearer token")
+	if gets := len(kubeClient.Actions()); gets != 2 {
+		t.Errorf("%d actions on the client, want 2 (get, update)", gets)
+	}
+	now = now.Add(headersSecretTTL)
+	check("Bearer rotated")
+}
@@ -1,16 +1,14 @@
 package anomalydetector
 
 import (
-	"fmt"
-
 	"go.uber.org/zap"
 	kapiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
 	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
-	"github.com/amadeusitgroup/kubervisor/pkg/pod"
 )
 
 type okkoCount struct {
@@ -24,66 +22,101 @@ type discreteValueAnalyser interface {
 }
 
 var _ AnomalyDetector = &DiscreteValueOutOfListAnalyser{}
+var _ FleetReporter = &DiscreteValueOutOfListAnalyser{}
 
 //DiscreteValueOutOfListAnalyser anomalyDetector that check the ratio of good/bad value and return the pods that exceed a given threshold for that ratio
 type DiscreteValueOutOfListAnalyser struct {
 	api.DiscreteValueOutOfList
-	selector  labels.Selector
-	analyser  discreteValueAnalyser
-	podLister kv1.PodNamespaceLister
-	logger    *zap.Logger
+	selector   labels.Selector
+	analyser   discreteValueAnalyser
+	namespace  string
+	podLister  kv1.PodNamespaceLister
+	podIndexer cache.Indexer
+	logger     *zap.Logger
+
+	// fleet counters of the last evaluation
+	fleet okkoCount
 }
 
 //GetPodsOutOfBounds implements interface AnomalyDetector
 func (d *DiscreteValueOutOfListAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
-	listOfPods, err := d.podLister.List(d.selector)
+	d.fleet = okkoCount{}
+	index, err := newPodIndex(d.PodMatch, d.namespace, d.selector, d.podLister, d.podIndexer)
 	if err != nil {
-		return nil, fmt.Errorf("can't list pods, error:%v", err)
+		return nil, err
 	}
 
-	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	result := []*kapiv1.Pod{}
+	countersByMetric, err := d.analyser.doAnalysis()
 	if err != nil {
-		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+		return nil, err
 	}
+
+	d.logger.Sugar().Debugf("Number of PODs reporting metrics:%d\n", len(countersByMetric))
+
+	// several metric values can identify the same pod, for example ip:port values of different ports
+	countersByPods := okkoByPodName{}
 	podByName := map[string]*kapiv1.Pod{}
-	podWithNoTraffic := map[string]*kapiv1.Pod{}
+	for metricValue, counter := range countersByMetric {
+		for _, p := range index.lookup(metricValue) {
+			podCounter := countersByPods[p.Name]
+			podCounter.ok += counter.ok
+			podCounter.ko += counter.ko
+			countersByPods[p.Name] = podCounter
+			podByName[p.Name] = p
+		}
+	}
 
-	for _, p := range listOfPods {
-		podByName[p.Name] = p
-		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+	fleet := okkoCount{}
+	for podName, counter := range countersByPods {
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(podByName[podName])
 		if err2 != nil {
 			return nil, err2
 		}
 		if !traffic {
-			podWithNoTraffic[p.Name] = p
+			d.logger.Sugar().Infof("the pod %s metrics are ignored now has it is marked out of traffic\n", podName)
+			delete(countersByPods, podName)
+			continue
 		}
+		fleet.ok += counter.ok
+		fleet.ko += counter.ko
 	}
-
-	result := []*kapiv1.Pod{}
-	countersByPods, err := d.analyser.doAnalysis()
-	if err != nil {
-		return nil, err
-	}
-
-	d.logger.Sugar().Debugf("Number of PODs reporting metrics:%d\n", len(countersByPods))
+	d.fleet = fleet
 
 	for podName, counter := range countersByPods {
-		_, found := podWithNoTraffic[podName]
-		if found {
-			d.logger.Sugar().Infof("the pod %s metrics are ignored now has it is marked out of traffic\n", podName)
+		sum := counter.ok + counter.ko
+		if sum < *d.MinimumActivityCount {
 			continue
 		}
-
-		sum := counter.ok + counter.ko
-		if sum >= *d.MinimumActivityCount {
-			ratio := counter.ko * 100 / sum
-			if ratio > *d.TolerancePercent {
-				if p, ok := podByName[podName]; ok {
-					// Only keeping known pod with ratio superior to Tolerance
-					result = append(result, p)
-				}
+		if d.Confidence != nil {
+			if d.isOutOfConfidence(counter, fleet) {
+				result = append(result, podByName[podName])
 			}
+			continue
+		}
+		if counter.ko*100 > *d.TolerancePercent*sum {
+			// Only keeping known pod with ratio superior to Tolerance
+			result = append(result, podByName[podName])
 		}
 	}
 	return result, nil
 }
+
+// isOutOfConfidence returns true if the error rate of the pod exceeds the tolerance, or the error rate of the rest of the fleet, with the configured confidence
+func (d *DiscreteValueOutOfListAnalyser) isOutOfConfidence(counter, fleet okkoCount) bool {
+	z := zScore(*d.Confidence)
+	if wilsonLowerBound(counter.ko, counter.ok+counter.ko, z) > float64(*d.TolerancePercent)/100.0 {
+		return true
+	}
+	others := okkoCount{ok: fleet.ok - counter.ok, ko: fleet.ko - counter.ko}
+	return isSignificantlyWorse(counter, others, z)
+}
+
+//FleetErrorRatio implements interface FleetReporter
+func (d *DiscreteValueOutOfListAnalyser) FleetErrorRatio() (float64, bool) {
+	sum := d.fleet.ok + d.fleet.ko
+	if sum == 0 {
+		return 0, false
+	}
+	return float64(d.fleet.ko) / float64(sum), true
+}
@@ -137,6 +137,53 @@ func TestDiscreteValueOutOfListAnalyser_GetPodsOutOfBounds(t *testing.T) {
 			want:    []*kapiv1.Pod{test.PodGen("B", "test-ns", map[string]string{"app": "bar", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes)},
 			wantErr: false,
 		},
+		{
+			name: "10% match by ip and port",
+			fields: fields{
+				DiscreteValueOutOfList: *api.DefaultDiscreteValueOutOfList(&api.DiscreteValueOutOfList{TolerancePercent: api.NewUInt(10), PodMatch: &api.PodMatch{By: api.PodMatchByIP, StripPort: true}}),
+				selector:               labels.Everything(),
+				analyser:               &testDiscreateValueAnalyser{okkoByPodName: okkoByPodName{"10.0.0.1:80": {10, 0}, "10.0.0.2:80": {10, 0}, "10.0.0.2:8080": {0, 10}, "10.0.0.9:80": {0, 10}}},
+				podLister: test.NewTestPodNamespaceLister(
+					[]*kapiv1.Pod{
+						podGenWithIP("A", "10.0.0.1", map[string]string{"app": "foo"}),
+						podGenWithIP("B", "10.0.0.2", map[string]string{"app": "foo"}),
+					}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{podGenWithIP("B", "10.0.0.2", map[string]string{"app": "foo"})},
+			wantErr: false,
+		},
+		{
+			name: "confidence low volume",
+			fields: fields{
+				DiscreteValueOutOfList: *api.DefaultDiscreteValueOutOfList(&api.DiscreteValueOutOfList{TolerancePercent: api.NewUInt(30), Confidence: api.NewFloat64(0.95)}),
+				selector:               labels.Everything(),
+				analyser:               &testDiscreateValueAnalyser{okkoByPodName: okkoByPodName{"A": {2, 3}, "B": {2000, 3000}, "C": {2, 3}}},
+				podLister: test.NewTestPodNamespaceLister(
+					[]*kapiv1.Pod{
+						test.PodGen("A", "test-ns", map[string]string{"app": "foo", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes),
+						test.PodGen("B", "test-ns", map[string]string{"app": "bar", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes),
+						test.PodGen("C", "test-ns", map[string]string{"app": "bar", "phase": "pdt"}, nil, true, true, labeling.LabelTrafficYes),
+					}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{test.PodGen("B", "test-ns", map[string]string{"app": "bar", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes)},
+			wantErr: false,
+		},
+		{
+			name: "confidence worse than fleet",
+			fields: fields{
+				DiscreteValueOutOfList: *api.DefaultDiscreteValueOutOfList(&api.DiscreteValueOutOfList{TolerancePercent: api.NewUInt(50), Confidence: api.NewFloat64(0.95)}),
+				selector:               labels.Everything(),
+				analyser:               &testDiscreateValueAnalyser{okkoByPodName: okkoByPodName{"A": {990, 10}, "B": {700, 300}, "C": {985, 15}}},
+				podLister: test.NewTestPodNamespaceLister(
+					[]*kapiv1.Pod{
+						test.PodGen("A", "test-ns", map[string]string{"app": "foo", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes),
+						test.PodGen("B", "test-ns", map[string]string{"app": "bar", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes),
+						test.PodGen("C", "test-ns", map[string]string{"app": "bar", "phase": "pdt"}, nil, true, true, labeling.LabelTrafficYes),
+					}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{test.PodGen("B", "test-ns", map[string]string{"app": "bar", "phase": "prd"}, nil, true, true, labeling.LabelTrafficYes)},
+			wantErr: false,
+		},
 		{
 			name: "Not Ready pod C",
 			fields: fields{
@@ -179,7 +226,9 @@ func TestDiscreteValueOutOfListAnalyser_GetPodsOutOfBounds(t *testing.T) {
 				DiscreteValueOutOfList: tt.fields.DiscreteValueOutOfList,
 				selector:               tt.fields.selector,
 				analyser:               tt.fields.analyser,
+				namespace:              "test-ns",
 				podLister:              tt.fields.podLister,
+				podIndexer:             testPodIndexer(tt.fields.podLister),
 				logger:                 devlogger,
 			}
 			got, err := d.GetPodsOutOfBounds()
@@ -204,6 +253,40 @@ func TestDiscreteValueOutOfListAnalyser_GetPodsOutOfBounds(t *testing.T) {
 
 }
 
+func TestDiscreteValueOutOfListAnalyser_FleetErrorRatio(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	d := &DiscreteValueOutOfListAnalyser{
+		DiscreteValueOutOfList: *api.DefaultDiscreteValueOutOfList(&api.DiscreteValueOutOfList{}),
+		selector:               labels.Everything(),
+		analyser:               &testDiscreateValueAnalyser{okkoByPodName: okkoByPodName{"A": {6, 4}, "B": {9, 1}, "N": {0, 10}}},
+		podLister: test.NewTestPodNamespaceLister(
+			[]*kapiv1.Pod{
+				test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+				test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+				test.PodGen("N", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo),
+			}, "test-ns"),
+		logger: devlogger,
+	}
+	if _, active := d.FleetErrorRatio(); active {
+		t.Errorf("FleetErrorRatio() active before any evaluation")
+	}
+	if _, err := d.GetPodsOutOfBounds(); err != nil {
+		t.Fatalf("GetPodsOutOfBounds() error = %v", err)
+	}
+	// the pod without traffic is not part of the fleet
+	if ratio, active := d.FleetErrorRatio(); !active || ratio != 0.25 {
+		t.Errorf("FleetErrorRatio() = %v, %v, want 0.25, true", ratio, active)
+	}
+
+	d.analyser = &testErrorDiscreateValueAnalyser{}
+	if _, err := d.GetPodsOutOfBounds(); err == nil {
+		t.Fatalf("GetPodsOutOfBounds() expected error")
+	}
+	if _, active := d.FleetErrorRatio(); active {
+		t.Errorf("FleetErrorRatio() active after a failed evaluation")
+	}
+}
+
 type testErrorDiscreateValueAnalyser struct{}
 
 func (t *testErrorDiscreateValueAnalyser) doAnalysis() (okkoByPodName, error) {
@@ -0,0 +1,92 @@
+package anomalydetector
+
+import (
+	"github.com/prometheus/common/model"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+)
+
+// valueClass class of a sample of a DiscreteValueOutOfList
+type valueClass int
+
+const (
+	valueClassGood valueClass = iota
+	valueClassBad
+	valueClassIgnored
+)
+
+// discreteValueRules classifies the samples with the rules of a DiscreteValueOutOfList
+type discreteValueRules struct {
+	rules        []discreteValueRule
+	defaultClass valueClass
+}
+
+type discreteValueRule struct {
+	class    valueClass
+	matchers map[model.LabelName]func(value string) bool
+}
+
+func newDiscreteValueRules(rules []api.DiscreteValueRule, defaultClass api.DiscreteValueClass) (*discreteValueRules, error) {
+	result := &discreteValueRules{defaultClass: toValueClass(defaultClass)}
+	for _, rule := range rules {
+		r := discreteValueRule{class: toValueClass(rule.Class), matchers: map[model.LabelName]func(value string) bool{}}
+		for name, matcher := range rule.Labels {
+			match, err := api.CompileValueMatcher(matcher)
+			if err != nil {
+				return nil, err
+			}
+			r.matchers[model.LabelName(name)] = match
+		}
+		result.rules = append(result.rules, r)
+	}
+	return result, nil
+}
+
+// toValueClass converts the class of the API, an empty class is good
+func toValueClass(c api.DiscreteValueClass) valueClass {
+	switch c {
+	case api.DiscreteValueClassBad:
+		return valueClassBad
+	case api.DiscreteValueClassIgnored:
+		return valueClassIgnored
+	}
+	return valueClassGood
+}
+
+// classify returns the class of the first rule matching the labels of the sample, a missing label has an empty value
+func (r *discreteValueRules) classify(metric model.Metric) valueClass {
+	for _, rule := range r.rules {
+		matched := true
+		for name, match := range rule.matchers {
+			if !match(string(metric[name])) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule.class
+		}
+	}
+	return r.defaultClass
+}
+
+// classifySample returns the class of the sample: with the rules when defined, else with the value of the key label
+func classifySample(metric model.Metric, key string, valueCheckerFunc func(value string) bool, rules *discreteValueRules) valueClass {
+	if rules != nil {
+		return rules.classify(metric)
+	}
+	if valueCheckerFunc(string(metric[model.LabelName(key)])) {
+		return valueClassGood
+	}
+	return valueClassBad
+}
+
+// add counts the sample value in the counter of its class
+func (c *okkoCount) add(class valueClass, value model.SampleValue) {
+	switch class {
+	case valueClassGood:
+		c.ok += uint(value)
+	case valueClassBad:
+		c.ko += uint(value)
+	}
+}
@@ -0,0 +1,64 @@
+package anomalydetector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/common/model"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+)
+
+func Test_discreteValueRules_classify(t *testing.T) {
+	rules, err := newDiscreteValueRules([]api.DiscreteValueRule{
+		{Class: api.DiscreteValueClassIgnored, Labels: map[string]string{"code": "429"}},
+		{Class: api.DiscreteValueClassBad, Labels: map[string]string{"code": "5.."}},
+		{Class: api.DiscreteValueClassBad, Labels: map[string]string{"code": "200", "grpc_status": "[1,16]"}},
+	}, "")
+	if err != nil {
+		t.Fatalf("newDiscreteValueRules() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		metric model.Metric
+		want   valueClass
+	}{
+		{name: "ignored", metric: model.Metric{"code": "429"}, want: valueClassIgnored},
+		{name: "regex", metric: model.Metric{"code": "503"}, want: valueClassBad},
+		{name: "regex matches the whole value", metric: model.Metric{"code": "5000"}, want: valueClassGood},
+		{name: "grpc error", metric: model.Metric{"code": "200", "grpc_status": "14"}, want: valueClassBad},
+		{name: "grpc ok", metric: model.Metric{"code": "200", "grpc_status": "0"}, want: valueClassGood},
+		{name: "missing label", metric: model.Metric{"code": "200"}, want: valueClassGood},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.classify(tt.metric); got != tt.want {
+				t.Errorf("discreteValueRules.classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_promDiscreteValueOutOfListAnalyser_buildCountersWithRules(t *testing.T) {
+	rules, err := newDiscreteValueRules([]api.DiscreteValueRule{
+		{Class: api.DiscreteValueClassGood, Labels: map[string]string{"code": "[200,399]"}},
+		{Class: api.DiscreteValueClassBad, Labels: map[string]string{"code": "5.."}},
+	}, api.DiscreteValueClassIgnored)
+	if err != nil {
+		t.Fatalf("newDiscreteValueRules() error = %v", err)
+	}
+	p := &promDiscreteValueOutOfListAnalyser{
+		config: api.DiscreteValueOutOfList{PodNameKey: "podname"},
+		rules:  rules,
+	}
+	vector := model.Vector{
+		&model.Sample{Metric: model.Metric{"code": "200", "podname": "david"}, Value: 10.0},
+		&model.Sample{Metric: model.Metric{"code": "302", "podname": "david"}, Value: 2.0},
+		&model.Sample{Metric: model.Metric{"code": "404", "podname": "david"}, Value: 6.0},
+		&model.Sample{Metric: model.Metric{"code": "502", "podname": "david"}, Value: 3.0},
+	}
+	want := okkoByPodName{"david": {12, 3}}
+	if got := p.buildCounters(vector); !reflect.DeepEqual(got, want) {
+		t.Errorf("promDiscreteValueOutOfListAnalyser.buildCounters() = %v, want %v", got, want)
+	}
+}
@@ -2,10 +2,16 @@ package anomalydetector
 
 import (
 	"fmt"
+	"io"
+	"time"
 
 	promClient "github.com/prometheus/client_golang/api"
 	promApi "github.com/prometheus/client_golang/api/prometheus/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	kapiv1 "k8s.io/api/core/v1"
 
+	pluginv1 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/plugin/v1"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
 )
 
@@ -27,8 +33,34 @@ func New(cfg FactoryConfig) (AnomalyDetector, error) {
 		return newDiscreteValueOutOfListAnalyser(cfg.Config)
 	case cfg.BreakerStrategyConfig.ContinuousValueDeviation != nil:
 		return newContinuousValueDeviation(cfg.Config)
+	case cfg.BreakerStrategyConfig.LatencyPercentile != nil:
+		return newLatencyPercentileAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.PodStatusAnomaly != nil:
+		return newPodStatusAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.PodLogPattern != nil:
+		return newPodLogAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.ActiveProbe != nil:
+		return newActiveProbeAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.Consensus != nil:
+		return newConsensusAnalyser(cfg.Config)
 	case cfg.BreakerStrategyConfig.CustomService != "":
 		return newCustomAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.CustomDetector != nil:
+		return newCustomAnalyserV2(cfg.Config)
+	case cfg.BreakerStrategyConfig.GRPCService != nil:
+		return newGRPCServiceAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.AnomalyReport != nil:
+		return newAnomalyReportAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.Alertmanager != nil:
+		return newAlertmanagerAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.MetricsExpression != nil:
+		return newMetricsExpressionAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.Preset != nil:
+		return newPresetAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.ResourceUsage != nil:
+		return newResourceUsageAnalyser(cfg.Config)
+	case cfg.BreakerStrategyConfig.BurnRate != nil:
+		return newBurnRateAnalyser(cfg.Config)
 	case cfg.customFactory != nil:
 		return cfg.customFactory(cfg)
 	default:
@@ -46,6 +78,202 @@ func newCustomAnalyser(cfg Config) (*CustomAnomalyDetector, error) {
 	return c, nil
 }
 
+func newCustomAnalyserV2(cfg Config) (*CustomAnomalyDetectorV2, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.CustomDetector
+
+	if err := api.ValidateCustomDetector(analyserCfg); err != nil {
+		return nil, err
+	}
+	c := &CustomAnomalyDetectorV2{
+		CustomDetector: analyserCfg,
+		kubervisorName: cfg.KubervisorName,
+		namespace:      cfg.Namespace,
+		strategyName:   cfg.BreakerStrategyConfig.Name,
+		selector:       cfg.Selector,
+		podLister:      cfg.PodLister,
+		logger:         cfg.Logger,
+		client:         newCustomAnomalyDetectorV2Client(analyserCfg),
+	}
+	if analyserCfg.HeadersSecretName != "" {
+		if cfg.KubeClient == nil {
+			return nil, fmt.Errorf("missing kubernetes client to read the headers secret")
+		}
+		c.headers = secretHeaders(cfg.KubeClient, cfg.Namespace, analyserCfg.HeadersSecretName, time.Now)
+	}
+	return c, nil
+}
+
+func newGRPCServiceAnalyser(cfg Config) (*GRPCServiceAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.GRPCService
+
+	if err := api.ValidateGRPCService(analyserCfg); err != nil {
+		return nil, err
+	}
+	dialOption := grpc.WithInsecure()
+	if analyserCfg.TLS != nil {
+		tlsConfig, err := grpcServiceTLSConfig(cfg.KubeClient, cfg.Namespace, analyserCfg)
+		if err != nil {
+			return nil, err
+		}
+		dialOption = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	// the connection is established in the background, the calls fail while the plugin is not reachable
+	conn, err := grpc.Dial(analyserCfg.Address, dialOption)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCServiceAnalyser{
+		GRPCService:    analyserCfg,
+		kubervisorName: cfg.KubervisorName,
+		namespace:      cfg.Namespace,
+		strategyName:   cfg.BreakerStrategyConfig.Name,
+		selector:       cfg.Selector,
+		podLister:      cfg.PodLister,
+		logger:         cfg.Logger,
+		conn:           conn,
+		client:         pluginv1.NewAnomalyDetectorClient(conn),
+		retryPeriod:    grpcWatchRetryPeriod,
+		restart:        make(chan struct{}, 1),
+		trigger:        make(chan struct{}, 1),
+	}, nil
+}
+
+func newAnomalyReportAnalyser(cfg Config) (*AnomalyReportAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.AnomalyReport
+
+	if err := api.ValidateAnomalyReport(analyserCfg); err != nil {
+		return nil, err
+	}
+	if cfg.ReportLister == nil {
+		return nil, fmt.Errorf("missing PodAnomalyReport lister")
+	}
+	return &AnomalyReportAnalyser{
+		AnomalyReport: analyserCfg,
+		selector:      cfg.Selector,
+		podLister:     cfg.PodLister,
+		reportLister:  cfg.ReportLister,
+		logger:        cfg.Logger,
+		now:           time.Now,
+	}, nil
+}
+
+func newAlertmanagerAnalyser(cfg Config) (*AlertmanagerAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.Alertmanager
+
+	if err := api.ValidateAlertmanager(analyserCfg); err != nil {
+		return nil, err
+	}
+	if cfg.AlertStore == nil {
+		return nil, fmt.Errorf("the alertmanager webhook receiver is not enabled, see the --alertmanager-webhook flag of the controller")
+	}
+	trigger, unsubscribe := cfg.AlertStore.Subscribe()
+	return &AlertmanagerAnalyser{
+		Alertmanager: analyserCfg,
+		namespace:    cfg.Namespace,
+		selector:     cfg.Selector,
+		podLister:    cfg.PodLister,
+		store:        cfg.AlertStore,
+		logger:       cfg.Logger,
+		trigger:      trigger,
+		unsubscribe:  unsubscribe,
+	}, nil
+}
+
+func newMetricsExpressionAnalyser(cfg Config) (*MetricsExpressionAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.MetricsExpression
+
+	if err := api.ValidateMetricsExpression(analyserCfg); err != nil {
+		return nil, err
+	}
+	expr, err := api.CompileMetricsExpression(analyserCfg)
+	if err != nil {
+		return nil, err
+	}
+	queryAPI, err := newPrometheusQueryAPI(analyserCfg.PrometheusService)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricsExpressionAnalyser{
+		MetricsExpression: analyserCfg,
+		expr:              expr,
+		queryAPI:          queryAPI,
+		selector:          cfg.Selector,
+		podLister:         cfg.PodLister,
+		logger:            cfg.Logger,
+	}, nil
+}
+
+// newPresetAnalyser builds the DiscreteValueOutOfListAnalyser generated by the preset
+func newPresetAnalyser(cfg Config) (*DiscreteValueOutOfListAnalyser, error) {
+	preset := *cfg.BreakerStrategyConfig.Preset
+
+	if err := api.ValidateDetectorPreset(preset); err != nil {
+		return nil, err
+	}
+	discreteCfg, err := presetDiscreteValueOutOfList(preset, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	cfg.BreakerStrategyConfig.Preset = nil
+	cfg.BreakerStrategyConfig.DiscreteValueOutOfList = discreteCfg
+	return newDiscreteValueOutOfListAnalyser(cfg)
+}
+
+func newResourceUsageAnalyser(cfg Config) (*ResourceUsageAnalyser, error) {
+	analyserCfg := *api.DefaultResourceUsage(cfg.BreakerStrategyConfig.ResourceUsage)
+
+	if err := api.ValidateResourceUsage(analyserCfg); err != nil {
+		return nil, err
+	}
+	a := &ResourceUsageAnalyser{
+		ResourceUsage: analyserCfg,
+		selector:      cfg.Selector,
+		podLister:     cfg.PodLister,
+		logger:        cfg.Logger,
+		overSince:     map[string]time.Time{},
+		now:           time.Now,
+	}
+	switch {
+	case analyserCfg.PrometheusService != "":
+		queryAPI, err := newPrometheusQueryAPI(analyserCfg.PrometheusService)
+		if err != nil {
+			return nil, err
+		}
+		a.source = &promResourceUsageSource{config: analyserCfg, namespace: cfg.Namespace, queryAPI: queryAPI}
+	case analyserCfg.MetricsAPIService != "":
+		a.source = &metricsAPIUsageSource{resourceName: analyserCfg.Resource, namespace: cfg.Namespace, selector: cfg.Selector, get: httpMetricsAPIGet(analyserCfg.MetricsAPIService)}
+	default:
+		if cfg.KubeClient == nil {
+			return nil, fmt.Errorf("a kubernetes client is required to read the metrics API")
+		}
+		restClient := cfg.KubeClient.Core().RESTClient()
+		get := func(path string, labelSelector string) ([]byte, error) {
+			return restClient.Get().AbsPath(path).Param("labelSelector", labelSelector).DoRaw()
+		}
+		a.source = &metricsAPIUsageSource{resourceName: analyserCfg.Resource, namespace: cfg.Namespace, selector: cfg.Selector, get: get}
+	}
+	return a, nil
+}
+
+func newBurnRateAnalyser(cfg Config) (*BurnRateAnalyser, error) {
+	analyserCfg := *api.DefaultBurnRate(cfg.BreakerStrategyConfig.BurnRate)
+
+	if err := api.ValidateBurnRate(analyserCfg); err != nil {
+		return nil, err
+	}
+	queryAPI, err := newPrometheusQueryAPI(analyserCfg.PrometheusService)
+	if err != nil {
+		return nil, err
+	}
+	return &BurnRateAnalyser{
+		BurnRate:  analyserCfg,
+		queryAPI:  queryAPI,
+		selector:  cfg.Selector,
+		podLister: cfg.PodLister,
+		logger:    cfg.Logger,
+	}, nil
+}
+
 func newDiscreteValueOutOfListAnalyser(cfg Config) (*DiscreteValueOutOfListAnalyser, error) {
 	analyserCfg := *cfg.BreakerStrategyConfig.DiscreteValueOutOfList
 
@@ -58,21 +286,48 @@ func newDiscreteValueOutOfListAnalyser(cfg Config) (*DiscreteValueOutOfListAnaly
 	if len(good) == 0 && len(bad) != 0 {
 		valueCheckerFunc = func(value string) bool { return !ContainsString(bad, value) }
 	}
+	var rules *discreteValueRules
+	if len(analyserCfg.Rules) != 0 {
+		var err error
+		if rules, err = newDiscreteValueRules(analyserCfg.Rules, analyserCfg.DefaultClass); err != nil {
+			return nil, err
+		}
+	}
 
-	a := &DiscreteValueOutOfListAnalyser{DiscreteValueOutOfList: analyserCfg, selector: cfg.Selector, podLister: cfg.PodLister, logger: cfg.Logger}
+	a := &DiscreteValueOutOfListAnalyser{DiscreteValueOutOfList: analyserCfg, selector: cfg.Selector, namespace: cfg.Namespace, podLister: cfg.PodLister, podIndexer: cfg.PodIndexer, logger: cfg.Logger}
 	switch {
 	case analyserCfg.PromQL != "":
 
 		analyser := &promDiscreteValueOutOfListAnalyser{config: analyserCfg, logger: cfg.Logger}
 
 		analyser.valueCheckerFunc = valueCheckerFunc
-		promconfig := promClient.Config{Address: "http://" + analyserCfg.PrometheusService}
-		prometheusClient, err := promClient.NewClient(promconfig)
+		analyser.rules = rules
+		queryAPI, err := newPrometheusQueryAPI(analyserCfg.PrometheusService)
 		if err != nil {
 			return nil, err
 		}
-		analyser.queyrAPI = promApi.NewAPI(prometheusClient)
+		analyser.queyrAPI = queryAPI
 		a.analyser = analyser
+	case analyserCfg.PodMetricsScrape != nil:
+		a.analyser = &counterDiscreteValueOutOfListAnalyser{
+			config:           analyserCfg,
+			metric:           analyserCfg.PodMetricsScrape.Metric,
+			source:           newPodMetricsScraper(*analyserCfg.PodMetricsScrape, cfg),
+			valueCheckerFunc: valueCheckerFunc,
+			rules:            rules,
+		}
+	case analyserCfg.RemoteWrite != nil:
+		source, err := newRemoteWriteCounterSource(*analyserCfg.RemoteWrite, cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.analyser = &counterDiscreteValueOutOfListAnalyser{
+			config:           analyserCfg,
+			metric:           analyserCfg.RemoteWrite.Metric,
+			source:           source,
+			valueCheckerFunc: valueCheckerFunc,
+			rules:            rules,
+		}
 	default:
 		return nil, fmt.Errorf("missing parameter to create DiscreteValueOutOfListAnalyser")
 	}
@@ -85,18 +340,33 @@ func newContinuousValueDeviation(cfg Config) (*ContinuousValueDeviationAnalyser,
 	if err := api.ValidateContinuousValueDeviation(analyserCfg); err != nil {
 		return nil, err
 	}
-	a := &ContinuousValueDeviationAnalyser{ContinuousValueDeviation: analyserCfg, selector: cfg.Selector, podLister: cfg.PodLister, logger: cfg.Logger}
+	a := &ContinuousValueDeviationAnalyser{ContinuousValueDeviation: analyserCfg, selector: cfg.Selector, namespace: cfg.Namespace, podLister: cfg.PodLister, podIndexer: cfg.PodIndexer, logger: cfg.Logger}
 	switch {
 	case analyserCfg.PromQL != "":
 
 		analyser := &promContinuousValueDeviationAnalyser{config: analyserCfg, logger: cfg.Logger}
-		promconfig := promClient.Config{Address: "http://" + analyserCfg.PrometheusService}
-		prometheusClient, err := promClient.NewClient(promconfig)
+		queryAPI, err := newPrometheusQueryAPI(analyserCfg.PrometheusService)
 		if err != nil {
 			return nil, err
 		}
-		analyser.queryAPI = promApi.NewAPI(prometheusClient)
+		analyser.queryAPI = queryAPI
 		a.analyser = analyser
+	case analyserCfg.PodMetricsScrape != nil:
+		a.analyser = &counterContinuousValueDeviationAnalyser{
+			config: analyserCfg,
+			metric: analyserCfg.PodMetricsScrape.Metric,
+			source: newPodMetricsScraper(*analyserCfg.PodMetricsScrape, cfg),
+		}
+	case analyserCfg.RemoteWrite != nil:
+		source, err := newRemoteWriteCounterSource(*analyserCfg.RemoteWrite, cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.analyser = &counterContinuousValueDeviationAnalyser{
+			config: analyserCfg,
+			metric: analyserCfg.RemoteWrite.Metric,
+			source: source,
+		}
 	default:
 		return nil, fmt.Errorf("missing parameter to create ContinuousValueDeviationAnalyser")
 	}
@@ -104,6 +374,46 @@ func newContinuousValueDeviation(cfg Config) (*ContinuousValueDeviationAnalyser,
 
 }
 
+func newLatencyPercentileAnalyser(cfg Config) (*LatencyPercentileAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.LatencyPercentile
+
+	if err := api.ValidateLatencyPercentile(analyserCfg); err != nil {
+		return nil, err
+	}
+	queryAPI, err := newPrometheusQueryAPI(analyserCfg.PrometheusService)
+	if err != nil {
+		return nil, err
+	}
+	analyser := &promLatencyPercentileAnalyser{config: analyserCfg, queryAPI: queryAPI, logger: cfg.Logger}
+	return &LatencyPercentileAnalyser{LatencyPercentile: analyserCfg, selector: cfg.Selector, podLister: cfg.PodLister, analyser: analyser, logger: cfg.Logger}, nil
+}
+
+func newPodStatusAnalyser(cfg Config) (*PodStatusAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.PodStatusAnomaly
+
+	if err := api.ValidatePodStatusAnomaly(analyserCfg); err != nil {
+		return nil, err
+	}
+	return &PodStatusAnalyser{
+		PodStatusAnomaly: analyserCfg,
+		selector:         cfg.Selector,
+		podLister:        cfg.PodLister,
+		logger:           cfg.Logger,
+		history:          map[string]*podStatusHistory{},
+		now:              time.Now,
+	}, nil
+}
+
+// newPrometheusQueryAPI returns a query client for the prometheus service
+func newPrometheusQueryAPI(prometheusService string) (promApi.API, error) {
+	promconfig := promClient.Config{Address: "http://" + prometheusService}
+	prometheusClient, err := promClient.NewClient(promconfig)
+	if err != nil {
+		return nil, err
+	}
+	return promApi.NewAPI(prometheusClient), nil
+}
+
 // ContainsString checks if the slice has the contains value in it.
 func ContainsString(slice []string, contains string) bool {
 	for _, value := range slice {
@@ -113,3 +423,94 @@ func ContainsString(slice []string, contains string) bool {
 	}
 	return false
 }
+
+func newPodLogAnalyser(cfg Config) (*DiscreteValueOutOfListAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.PodLogPattern
+
+	if err := api.ValidatePodLogPattern(analyserCfg); err != nil {
+		return nil, err
+	}
+	if cfg.KubeClient == nil {
+		return nil, fmt.Errorf("missing kubernetes client to read the pod logs")
+	}
+	good, err := compilePatterns(analyserCfg.GoodPatterns)
+	if err != nil {
+		return nil, err
+	}
+	bad, err := compilePatterns(analyserCfg.BadPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeClient := cfg.KubeClient
+	analyser := &podLogAnalyser{
+		config:    analyserCfg,
+		good:      good,
+		bad:       bad,
+		selector:  cfg.Selector,
+		podLister: cfg.PodLister,
+		logger:    cfg.Logger,
+		streamLogs: func(p *kapiv1.Pod, opts *kapiv1.PodLogOptions) (io.ReadCloser, error) {
+			return kubeClient.CoreV1().Pods(p.Namespace).GetLogs(p.Name, opts).Stream()
+		},
+		now:       time.Now,
+		positions: map[string]podLogPosition{},
+	}
+
+	// the tolerance and minimum activity are checked like for DiscreteValueOutOfList
+	discreteCfg := api.DiscreteValueOutOfList{
+		TolerancePercent:     analyserCfg.TolerancePercent,
+		MinimumActivityCount: analyserCfg.MinimumActivityCount,
+	}
+	return &DiscreteValueOutOfListAnalyser{DiscreteValueOutOfList: discreteCfg, selector: cfg.Selector, namespace: cfg.Namespace, podLister: cfg.PodLister, podIndexer: cfg.PodIndexer, logger: cfg.Logger, analyser: analyser}, nil
+}
+
+func newActiveProbeAnalyser(cfg Config) (*ActiveProbeAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.ActiveProbe
+
+	if err := api.ValidateActiveProbe(analyserCfg); err != nil {
+		return nil, err
+	}
+	a := &ActiveProbeAnalyser{
+		ActiveProbe:    analyserCfg,
+		kubervisorName: cfg.KubervisorName,
+		namespace:      cfg.Namespace,
+		strategyName:   cfg.BreakerStrategyConfig.Name,
+		selector:       cfg.Selector,
+		podLister:      cfg.PodLister,
+		logger:         cfg.Logger,
+		address:        podProbeAddress(analyserCfg.Port),
+		history:        map[string]*probeHistory{},
+	}
+	switch {
+	case analyserCfg.HTTP != nil:
+		p, err := newHTTPProber(*analyserCfg.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		a.prober = p
+	case analyserCfg.GRPC != nil:
+		a.prober = &grpcHealthProber{service: analyserCfg.GRPC.Service}
+	}
+	return a, nil
+}
+
+func newConsensusAnalyser(cfg Config) (*ConsensusAnalyser, error) {
+	analyserCfg := *cfg.BreakerStrategyConfig.Consensus
+
+	if err := api.ValidateConsensus(analyserCfg); err != nil {
+		return nil, err
+	}
+	f, err := newHTTPProber(analyserCfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsensusAnalyser{
+		Consensus: analyserCfg,
+		selector:  cfg.Selector,
+		podLister: cfg.PodLister,
+		logger:    cfg.Logger,
+		fetcher:   f,
+		address:   podProbeAddress(analyserCfg.Port),
+	}, nil
+}
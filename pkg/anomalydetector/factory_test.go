@@ -3,11 +3,16 @@ package anomalydetector
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 	kapiv1 "k8s.io/api/core/v1"
+	kfakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
 )
 
 type emptyCustomAnomalyDetectorT struct {
@@ -167,6 +172,569 @@ func TestNew(t *testing.T) {
 			wantErr: false,
 			want:    nil,
 		},
+		{
+			name: "podMetricsScrape",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							DiscreteValueOutOfList: &api.DiscreteValueOutOfList{
+								GoodValues:       []string{"200"},
+								Key:              "code",
+								PodMetricsScrape: api.DefaultPodMetricsScrape(&api.PodMetricsScrape{Port: 8080, Metric: "rpc_count"}),
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "remoteWrite_NoStore",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ContinuousValueDeviation: api.DefaultContinuousValueDeviation(&api.ContinuousValueDeviation{
+								RemoteWrite: &api.RemoteWriteSource{Metric: "price"},
+							}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "latencyPercentile",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							LatencyPercentile: api.DefaultLatencyPercentile(&api.LatencyPercentile{
+								PrometheusService: "PrometheusService",
+								HistogramMetric:   "rpc_duration_seconds",
+								PodNameKey:        "pod",
+							}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "latencyPercentile_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							LatencyPercentile: &api.LatencyPercentile{},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "podStatusAnomaly",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							PodStatusAnomaly: api.DefaultPodStatusAnomaly(&api.PodStatusAnomaly{}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "podStatusAnomaly_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							PodStatusAnomaly: &api.PodStatusAnomaly{},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "podLogPattern",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:     devLogger,
+						PodLister:  nil,
+						KubeClient: kfakeclient.NewSimpleClientset(),
+						BreakerStrategyConfig: api.BreakerStrategy{
+							PodLogPattern: api.DefaultPodLogPattern(&api.PodLogPattern{BadPatterns: []string{"pool exhausted"}}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "podLogPattern_NoKubeClient",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							PodLogPattern: api.DefaultPodLogPattern(&api.PodLogPattern{BadPatterns: []string{"pool exhausted"}}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "activeProbe",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ActiveProbe: api.DefaultActiveProbe(&api.ActiveProbe{Port: 8080, GRPC: &api.GRPCHealthProbe{}}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "activeProbe_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ActiveProbe: &api.ActiveProbe{Port: 8080},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "consensus",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Consensus: api.DefaultConsensus(&api.Consensus{Port: 8080, JSONPaths: []string{"solutions.0.price"}}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "consensus_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Consensus: &api.Consensus{Port: 8080},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "customDetector",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							CustomDetector: api.DefaultCustomDetector(&api.CustomDetector{URL: "https://detector:8443/analyse"}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "customDetector_MissingKubeClient",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							CustomDetector: api.DefaultCustomDetector(&api.CustomDetector{URL: "https://detector:8443/analyse", HeadersSecretName: "auth"}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "customDetector_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							CustomDetector: &api.CustomDetector{URL: "detector:8443"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "grpcService",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							GRPCService: api.DefaultGRPCService(&api.GRPCService{Address: "detector:9000", TLS: &api.GRPCServiceTLS{}}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "grpcService_MissingKubeClient",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							GRPCService: api.DefaultGRPCService(&api.GRPCService{Address: "detector:9000", TLS: &api.GRPCServiceTLS{SecretName: "tls"}}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "grpcService_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							GRPCService: &api.GRPCService{Address: "detector:9000"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "anomalyReport",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:       devLogger,
+						PodLister:    nil,
+						ReportLister: blisters.NewPodAnomalyReportLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})).PodAnomalyReports("test-ns"),
+						BreakerStrategyConfig: api.BreakerStrategy{
+							AnomalyReport: api.DefaultAnomalyReport(&api.AnomalyReport{Sources: []string{"lb"}}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "anomalyReport_MissingLister",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							AnomalyReport: api.DefaultAnomalyReport(&api.AnomalyReport{}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "anomalyReport_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							AnomalyReport: &api.AnomalyReport{},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "alertmanager",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:     devLogger,
+						PodLister:  nil,
+						AlertStore: alertmanager.NewStore(time.Hour),
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Alertmanager: api.DefaultAlertmanager(&api.Alertmanager{MatchLabels: map[string]string{"alertname": "HighErrorRate"}}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "alertmanager_MissingStore",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Alertmanager: api.DefaultAlertmanager(&api.Alertmanager{}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "alertmanager_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:     devLogger,
+						PodLister:  nil,
+						AlertStore: alertmanager.NewStore(time.Hour),
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Alertmanager: &api.Alertmanager{},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "metricsExpression",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							MetricsExpression: &api.MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: map[string]string{"errors": "e"}, Expression: "errors > 2 * fleet_avg_errors"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "metricsExpression_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							MetricsExpression: &api.MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: map[string]string{"errors": "e"}, Expression: "errors >"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "preset",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						Namespace: "test-ns",
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Preset: api.DefaultDetectorPreset(&api.DetectorPreset{Kind: api.DetectorPresetIstioHTTP5xx, PrometheusService: "prometheus:9090", Workload: "foo"}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "preset_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							Preset: api.DefaultDetectorPreset(&api.DetectorPreset{Kind: "haproxy", PrometheusService: "prometheus:9090", Workload: "foo"}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "resourceUsage_metricsAPI",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						Namespace: "test-ns",
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ResourceUsage: &api.ResourceUsage{Resource: kapiv1.ResourceCPU, MetricsAPIService: "metrics-server:443"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "resourceUsage_prometheus",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						Namespace: "test-ns",
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ResourceUsage: &api.ResourceUsage{Resource: kapiv1.ResourceMemory, PrometheusService: "prometheus:9090", MaxLimitPercent: api.NewUInt(90)},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "resourceUsage_noKubeClient",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						Namespace: "test-ns",
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ResourceUsage: &api.ResourceUsage{Resource: kapiv1.ResourceCPU},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "resourceUsage_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						Namespace: "test-ns",
+						BreakerStrategyConfig: api.BreakerStrategy{
+							ResourceUsage: &api.ResourceUsage{Resource: kapiv1.ResourceStorage, MetricsAPIService: "metrics-server:443"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
+		{
+			name: "burnRate",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							BurnRate: api.DefaultBurnRate(&api.BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[$window]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: api.NewFloat64(0.999)}),
+						},
+					},
+				},
+			},
+			wantErr: false,
+			want:    nil,
+		},
+		{
+			name: "burnRate_ValidationError",
+			args: args{
+				cfg: FactoryConfig{
+					Config: Config{
+						Logger:    devLogger,
+						PodLister: nil,
+						BreakerStrategyConfig: api.BreakerStrategy{
+							BurnRate: api.DefaultBurnRate(&api.BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[5m]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: api.NewFloat64(0.999)}),
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    nil,
+		},
 		{
 			name: "customService",
 			args: args{
@@ -0,0 +1,286 @@
+package anomalydetector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	pluginv1 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/plugin/v1"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+// grpcWatchRetryPeriod delay before a new Watch call when the previous one failed
+const grpcWatchRetryPeriod = 5 * time.Second
+
+var _ AnomalyDetector = &GRPCServiceAnalyser{}
+var _ Runner = &GRPCServiceAnalyser{}
+var _ Triggered = &GRPCServiceAnalyser{}
+
+//GRPCServiceAnalyser anomalyDetector that gets the verdicts of the pods from a gRPC plugin
+type GRPCServiceAnalyser struct {
+	api.GRPCService
+	kubervisorName string
+	namespace      string
+	strategyName   string
+	selector       labels.Selector
+	podLister      kv1.PodNamespaceLister
+	logger         *zap.Logger
+	conn           *grpc.ClientConn
+	client         pluginv1.AnomalyDetectorClient
+	retryPeriod    time.Duration
+
+	// watch mode: the last verdicts pushed by the plugin, nil if the stream is broken.
+	// They are written by Run and read by the breaker goroutine
+	mutex       sync.Mutex
+	verdicts    []*pluginv1.PodVerdict
+	watchedPods string
+	restart     chan struct{}
+	// trigger receives each time the plugin pushes verdicts
+	trigger chan struct{}
+}
+
+//Trigger implements interface Triggered: in watch mode the breaker is evaluated each time the plugin pushes verdicts
+func (d *GRPCServiceAnalyser) Trigger() <-chan struct{} {
+	return d.trigger
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *GRPCServiceAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	req, podsByName, err := d.request()
+	if err != nil {
+		return nil, err
+	}
+
+	var verdicts []*pluginv1.PodVerdict
+	if d.Mode == api.GRPCServiceModeWatch {
+		if verdicts, err = d.lastVerdicts(podsKey(req)); err != nil {
+			return nil, err
+		}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*d.Timeout*1000)*time.Millisecond)
+		defer cancel()
+		resp, err := d.client.Evaluate(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("Error while calling the gRPC plugin: %v", err)
+		}
+		verdicts = resp.Pods
+	}
+
+	result := []*kapiv1.Pod{}
+	for _, v := range verdicts {
+		p, ok := podsByName[v.Name]
+		if !ok {
+			continue
+		}
+		if v.Verdict == pluginv1.Verdict_VERDICT_OUT_OF_BOUNDS {
+			d.logger.Sugar().Infof("the pod %s is out of bounds: score %v, %s", v.Name, v.Score, v.Reason)
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// request returns the request describing the managed pods that receive traffic
+func (d *GRPCServiceAnalyser) request() (*pluginv1.EvaluateRequest, map[string]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	req := &pluginv1.EvaluateRequest{
+		Namespace:         d.namespace,
+		KubervisorService: d.kubervisorName,
+		Strategy:          d.strategyName,
+	}
+	podsByName := map[string]*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, nil, err2
+		}
+		if !traffic {
+			continue
+		}
+		podsByName[p.Name] = p
+		req.Pods = append(req.Pods, &pluginv1.Pod{Name: p.Name, Ip: p.Status.PodIP})
+	}
+	return req, podsByName, nil
+}
+
+// podsKey identifies the set of pods of the request
+func podsKey(req *pluginv1.EvaluateRequest) string {
+	keys := make([]string, 0, len(req.Pods))
+	for _, p := range req.Pods {
+		keys = append(keys, p.Name+"/"+p.Ip)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// lastVerdicts returns the last verdicts pushed by the plugin. The Watch call is restarted if the pods changed
+func (d *GRPCServiceAnalyser) lastVerdicts(key string) ([]*pluginv1.PodVerdict, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if key != d.watchedPods {
+		select {
+		case d.restart <- struct{}{}:
+		default:
+		}
+	}
+	if d.verdicts == nil {
+		return nil, fmt.Errorf("no verdict received from the gRPC plugin")
+	}
+	return d.verdicts, nil
+}
+
+func (d *GRPCServiceAnalyser) setVerdicts(verdicts []*pluginv1.PodVerdict) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.verdicts = verdicts
+}
+
+// signal notifies the breaker without blocking: a pending signal already covers the new verdicts
+func (d *GRPCServiceAnalyser) signal() {
+	select {
+	case d.trigger <- struct{}{}:
+	default:
+	}
+}
+
+//Run implements interface Runner: in watch mode it keeps a Watch call open on the plugin. The connection is closed when stop is closed
+func (d *GRPCServiceAnalyser) Run(stop <-chan struct{}) {
+	defer d.conn.Close()
+	if d.Mode != api.GRPCServiceModeWatch {
+		<-stop
+		return
+	}
+	for {
+		err := d.watchUntilRestart(stop)
+		if err == nil {
+			return
+		}
+		if err != errWatchRestart {
+			d.logger.Sugar().Warnf("watch of the gRPC plugin %s failed: %v", d.Address, err)
+			d.setVerdicts(nil)
+			select {
+			case <-stop:
+				return
+			case <-time.After(d.retryPeriod):
+			}
+		}
+	}
+}
+
+var errWatchRestart = fmt.Errorf("the managed pods changed")
+
+// watchUntilRestart returns nil when stop is closed, errWatchRestart when the pods changed, or the error of the Watch call
+func (d *GRPCServiceAnalyser) watchUntilRestart(stop <-chan struct{}) error {
+	req, _, err := d.request()
+	if err != nil {
+		return err
+	}
+	key := podsKey(req)
+	d.mutex.Lock()
+	d.watchedPods = key
+	d.mutex.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- d.watch(ctx, req) }()
+	select {
+	case <-stop:
+		cancel()
+		<-done
+		return nil
+	case <-d.restart:
+		cancel()
+		<-done
+		return errWatchRestart
+	case err := <-done:
+		return err
+	}
+}
+
+func (d *GRPCServiceAnalyser) watch(ctx context.Context, req *pluginv1.EvaluateRequest) error {
+	// the call ends when ctx is canceled
+	stream, err := d.client.Watch(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("the plugin ended the stream")
+		}
+		if err != nil {
+			return err
+		}
+		verdicts := resp.Pods
+		if verdicts == nil {
+			verdicts = []*pluginv1.PodVerdict{}
+		}
+		d.setVerdicts(verdicts)
+		d.signal()
+	}
+}
+
+// grpcServiceTLSConfig builds the TLS configuration, the CA and the client certificate are read from the secret
+func grpcServiceTLSConfig(kubeClient clientset.Interface, namespace string, config api.GRPCService) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.TLS.ServerName,
+		InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+	}
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(config.Address)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ServerName = host
+	}
+	if config.TLS.SecretName == "" {
+		return tlsConfig, nil
+	}
+	if kubeClient == nil {
+		return nil, fmt.Errorf("missing kubernetes client to read the TLS secret")
+	}
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(config.TLS.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("can't get the TLS secret %s/%s: %v", namespace, config.TLS.SecretName, err)
+	}
+	if ca, ok := secret.Data["ca.crt"]; ok {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid ca.crt in the TLS secret")
+		}
+	}
+	if cert, ok := secret.Data["tls.crt"]; ok {
+		certificate, err := tls.X509KeyPair(cert, secret.Data["tls.key"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate in the TLS secret: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
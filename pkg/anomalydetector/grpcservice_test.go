@@ -0,0 +1,236 @@
+package anomalydetector
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kfakeclient "k8s.io/client-go/kubernetes/fake"
+	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	pluginv1 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/plugin/v1"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+// testPlugin returns the same verdict for all the pods of the request. Watch pushes the verdicts at each update
+type testPlugin struct {
+	sync.Mutex
+	verdict  pluginv1.Verdict
+	requests []*pluginv1.EvaluateRequest
+	updates  chan struct{}
+}
+
+func (p *testPlugin) Evaluate(ctx context.Context, req *pluginv1.EvaluateRequest) (*pluginv1.EvaluateResponse, error) {
+	p.Lock()
+	defer p.Unlock()
+	p.requests = append(p.requests, req)
+	resp := &pluginv1.EvaluateResponse{}
+	for _, pod := range req.Pods {
+		resp.Pods = append(resp.Pods, &pluginv1.PodVerdict{Name: pod.Name, Verdict: p.verdict, Reason: "test"})
+	}
+	return resp, nil
+}
+
+func (p *testPlugin) Watch(ctx context.Context, req *pluginv1.EvaluateRequest, send func(*pluginv1.EvaluateResponse) error) error {
+	for {
+		resp, _ := p.Evaluate(ctx, req)
+		if err := send(resp); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.updates:
+		}
+	}
+}
+
+func (p *testPlugin) setVerdict(v pluginv1.Verdict) {
+	p.Lock()
+	defer p.Unlock()
+	p.verdict = v
+}
+
+func (p *testPlugin) requestCount() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.requests)
+}
+
+func newTestGRPCServiceAnalyser(t *testing.T, mode api.GRPCServiceMode, plugin *testPlugin, pods []*kapiv1.Pod) (*GRPCServiceAnalyser, func()) {
+	devlogger, _ := zap.NewDevelopment()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %v", err)
+	}
+	server := pluginv1.NewServer(plugin)
+	go server.Serve(listener)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	config := *api.DefaultGRPCService(&api.GRPCService{Address: listener.Addr().String(), Mode: mode})
+	d := &GRPCServiceAnalyser{
+		GRPCService:    config,
+		kubervisorName: "foo",
+		namespace:      "test-ns",
+		strategyName:   "strategy",
+		selector:       labels.Everything(),
+		podLister:      test.NewTestPodNamespaceLister(pods, "test-ns"),
+		logger:         devlogger,
+		conn:           conn,
+		client:         pluginv1.NewAnomalyDetectorClient(conn),
+		retryPeriod:    10 * time.Millisecond,
+		restart:        make(chan struct{}, 1),
+		trigger:        make(chan struct{}, 1),
+	}
+	return d, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func podNames(pods []*kapiv1.Pod) []string {
+	names := []string{}
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGRPCServiceAnalyser_Evaluate(t *testing.T) {
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo),
+	}
+	plugin := &testPlugin{verdict: pluginv1.Verdict_VERDICT_OUT_OF_BOUNDS}
+	d, stop := newTestGRPCServiceAnalyser(t, api.GRPCServiceModeEvaluate, plugin, pods)
+	defer stop()
+
+	got, err := d.GetPodsOutOfBounds()
+	if err != nil {
+		t.Fatalf("GRPCServiceAnalyser.GetPodsOutOfBounds() unexpected error: %v", err)
+	}
+	if names := podNames(got); !reflect.DeepEqual(names, []string{"A"}) {
+		t.Errorf("GRPCServiceAnalyser.GetPodsOutOfBounds() = %v, want [A]", names)
+	}
+	wantRequest := &pluginv1.EvaluateRequest{Namespace: "test-ns", KubervisorService: "foo", Strategy: "strategy", Pods: []*pluginv1.Pod{{Name: "A"}}}
+	if !reflect.DeepEqual(plugin.requests[0], wantRequest) {
+		t.Errorf("request = %v, want %v", plugin.requests[0], wantRequest)
+	}
+
+	// the plugin is not reachable
+	stop()
+	if _, err := d.GetPodsOutOfBounds(); err == nil {
+		t.Errorf("GRPCServiceAnalyser.GetPodsOutOfBounds() expected an error when the plugin is not reachable")
+	}
+}
+
+func TestGRPCServiceAnalyser_Watch(t *testing.T) {
+	pods := []*kapiv1.Pod{test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)}
+	plugin := &testPlugin{verdict: pluginv1.Verdict_VERDICT_OK, updates: make(chan struct{})}
+	d, stopPlugin := newTestGRPCServiceAnalyser(t, api.GRPCServiceModeWatch, plugin, pods)
+	defer stopPlugin()
+	// the indexer is safe to update while Run lists the pods
+	index := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	index.Add(pods[0])
+	d.podLister = kv1.NewPodLister(index).Pods("test-ns")
+
+	// waitFor calls GetPodsOutOfBounds until the verdicts pushed by the plugin are received
+	waitFor := func(want []string) {
+		var names []string
+		for i := 0; i < 100; i++ {
+			got, err := d.GetPodsOutOfBounds()
+			names = podNames(got)
+			if err == nil && reflect.DeepEqual(names, want) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("GRPCServiceAnalyser.GetPodsOutOfBounds() = %v, want %v", names, want)
+	}
+
+	if _, err := d.GetPodsOutOfBounds(); err == nil {
+		t.Errorf("GRPCServiceAnalyser.GetPodsOutOfBounds() expected an error before the first verdicts")
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go d.Run(stop)
+	// the breaker is triggered each time the plugin pushes verdicts
+	waitForTrigger := func() {
+		select {
+		case <-d.Trigger():
+		case <-time.After(time.Second):
+			t.Fatalf("GRPCServiceAnalyser.Trigger() not signaled after the verdicts pushed by the plugin")
+		}
+	}
+	waitForTrigger()
+	waitFor([]string{})
+
+	plugin.setVerdict(pluginv1.Verdict_VERDICT_OUT_OF_BOUNDS)
+	plugin.updates <- struct{}{}
+	waitForTrigger()
+	waitFor([]string{"A"})
+
+	// a new pod restarts the Watch call
+	count := plugin.requestCount()
+	index.Add(test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes))
+	waitFor([]string{"A", "B"})
+	if plugin.requestCount() <= count {
+		t.Errorf("the Watch call should be restarted when the pods change")
+	}
+}
+
+func Test_grpcServiceTLSConfig(t *testing.T) {
+	ts := httptest.NewTLSServer(nil)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	ts.Close()
+	kubeClient := kfakeclient.NewSimpleClientset(
+		&kapiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "test-ns"}, Data: map[string][]byte{"ca.crt": caPEM}},
+		&kapiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bad", Namespace: "test-ns"}, Data: map[string][]byte{"tls.crt": []byte("foo")}},
+	)
+
+	tests := []struct {
+		name           string
+		tls            api.GRPCServiceTLS
+		wantServerName string
+		wantRootCAs    bool
+		wantErr        bool
+	}{
+		{name: "default server name", wantServerName: "detector.ns.svc"},
+		{name: "server name", tls: api.GRPCServiceTLS{ServerName: "detector"}, wantServerName: "detector"},
+		{name: "ca", tls: api.GRPCServiceTLS{SecretName: "ca"}, wantServerName: "detector.ns.svc", wantRootCAs: true},
+		{name: "invalid client certificate", tls: api.GRPCServiceTLS{SecretName: "bad"}, wantErr: true},
+		{name: "missing secret", tls: api.GRPCServiceTLS{SecretName: "missing"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsSpec := tt.tls
+			got, err := grpcServiceTLSConfig(kubeClient, "test-ns", api.GRPCService{Address: "detector.ns.svc:9000", TLS: &tlsSpec})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("grpcServiceTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.ServerName != tt.wantServerName || (got.RootCAs != nil) != tt.wantRootCAs {
+				t.Errorf("grpcServiceTLSConfig() = server name %q, root CAs %v", got.ServerName, got.RootCAs != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &LatencyPercentileAnalyser{}
+
+//latencyByPodName latency percentile value by pod name, in the unit of the histogram
+type latencyByPodName map[string]float64
+type latencyAnalyser interface {
+	// doAnalysis returns the percentile by pod and the percentile for the whole fleet
+	doAnalysis() (latencyByPodName, float64, error)
+}
+
+//LatencyPercentileAnalyser anomalyDetector that check the latency percentile of each pod against the fleet percentile or a fixed SLO
+type LatencyPercentileAnalyser struct {
+	api.LatencyPercentile
+	selector  labels.Selector
+	analyser  latencyAnalyser
+	podLister kv1.PodNamespaceLister
+	logger    *zap.Logger
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *LatencyPercentileAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+	podByName := map[string]*kapiv1.Pod{}
+	podWithNoTraffic := map[string]*kapiv1.Pod{}
+
+	for _, p := range listOfPods {
+		podByName[p.Name] = p
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			podWithNoTraffic[p.Name] = p
+		}
+	}
+
+	result := []*kapiv1.Pod{}
+	latencyByPods, fleetLatency, err := d.analyser.doAnalysis()
+	if err != nil {
+		return nil, err
+	}
+	d.logger.Sugar().Debugf("Number of PODs reporting metrics:%d\n", len(latencyByPods))
+
+	maxLatency, err := d.maxLatency(fleetLatency)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(maxLatency) {
+		d.logger.Sugar().Debugf("no fleet latency available, skipping analysis")
+		return result, nil
+	}
+
+	for podName, latency := range latencyByPods {
+		_, found := podWithNoTraffic[podName]
+		if found {
+			d.logger.Sugar().Infof("the pod %s metrics are ignored now has it is marked out of traffic\n", podName)
+			continue
+		}
+		// NaN is returned by histogram_quantile when the pod has no activity
+		if math.IsNaN(latency) {
+			continue
+		}
+
+		if latency > maxLatency {
+			if p, ok := podByName[podName]; ok {
+				// Only keeping known pod with too high latency
+				result = append(result, p)
+			}
+		}
+	}
+	return result, nil
+}
+
+// maxLatency returns the latency threshold over which a pod is considered out of SLA
+func (d *LatencyPercentileAnalyser) maxLatency(fleetLatency float64) (float64, error) {
+	if d.MaxLatency != nil {
+		return *d.MaxLatency, nil
+	}
+	if d.MaxDeviationPercent == nil {
+		return 0, fmt.Errorf("missing Max Deviation percent or Max Latency for latency percentile analysis")
+	}
+	return fleetLatency * (1 + *d.MaxDeviationPercent/100.0), nil
+}
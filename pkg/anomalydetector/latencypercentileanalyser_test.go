@@ -0,0 +1,130 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func TestLatencyPercentileAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+
+	podA := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podB := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podC := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podCNoTraffic := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo)
+
+	type fields struct {
+		LatencyPercentile api.LatencyPercentile
+		analyser          latencyAnalyser
+		podLister         kv1.PodNamespaceLister
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []*kapiv1.Pod
+		wantErr bool
+	}{
+		{
+			name: "analysis error",
+			fields: fields{
+				LatencyPercentile: *api.DefaultLatencyPercentile(&api.LatencyPercentile{}),
+				analyser:          &testLatencyAnalyser{err: fmt.Errorf("error")},
+				podLister:         test.NewTestPodNamespaceLister(nil, "test-ns"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "no pod, no error",
+			fields: fields{
+				LatencyPercentile: *api.DefaultLatencyPercentile(&api.LatencyPercentile{}),
+				analyser:          &testLatencyAnalyser{latencyByPodName: latencyByPodName{}, fleet: 1.0},
+				podLister:         test.NewTestPodNamespaceLister(nil, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{},
+			wantErr: false,
+		},
+		{
+			name: "fleet comparison",
+			fields: fields{
+				LatencyPercentile: *api.DefaultLatencyPercentile(&api.LatencyPercentile{MaxDeviationPercent: api.NewFloat64(50)}),
+				analyser:          &testLatencyAnalyser{latencyByPodName: latencyByPodName{"A": 0.1, "B": 0.14, "C": 0.16}, fleet: 0.1},
+				podLister:         test.NewTestPodNamespaceLister([]*kapiv1.Pod{podA, podB, podC}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{podC},
+			wantErr: false,
+		},
+		{
+			name: "fleet comparison, no fleet value",
+			fields: fields{
+				LatencyPercentile: *api.DefaultLatencyPercentile(&api.LatencyPercentile{MaxDeviationPercent: api.NewFloat64(50)}),
+				analyser:          &testLatencyAnalyser{latencyByPodName: latencyByPodName{"A": 0.1, "B": 0.14, "C": 0.16}, fleet: math.NaN()},
+				podLister:         test.NewTestPodNamespaceLister([]*kapiv1.Pod{podA, podB, podC}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{},
+			wantErr: false,
+		},
+		{
+			name: "fixed SLO",
+			fields: fields{
+				LatencyPercentile: *api.DefaultLatencyPercentile(&api.LatencyPercentile{MaxLatency: api.NewFloat64(0.12)}),
+				analyser:          &testLatencyAnalyser{latencyByPodName: latencyByPodName{"A": 0.1, "B": 0.14, "C": math.NaN()}, fleet: math.NaN()},
+				podLister:         test.NewTestPodNamespaceLister([]*kapiv1.Pod{podA, podB, podC}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{podB},
+			wantErr: false,
+		},
+		{
+			name: "fixed SLO, pod without traffic ignored",
+			fields: fields{
+				LatencyPercentile: *api.DefaultLatencyPercentile(&api.LatencyPercentile{MaxLatency: api.NewFloat64(0.12)}),
+				analyser:          &testLatencyAnalyser{latencyByPodName: latencyByPodName{"A": 0.1, "B": 0.14, "C": 0.5}, fleet: math.NaN()},
+				podLister:         test.NewTestPodNamespaceLister([]*kapiv1.Pod{podA, podB, podCNoTraffic}, "test-ns"),
+			},
+			want:    []*kapiv1.Pod{podB},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &LatencyPercentileAnalyser{
+				LatencyPercentile: tt.fields.LatencyPercentile,
+				selector:          labels.Everything(),
+				analyser:          tt.fields.analyser,
+				podLister:         tt.fields.podLister,
+				logger:            devlogger,
+			}
+			got, err := d.GetPodsOutOfBounds()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LatencyPercentileAnalyser.GetPodsOutOfBounds() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			sort.SliceStable(got, func(i, j int) bool { return got[i].Name < got[j].Name })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LatencyPercentileAnalyser.GetPodsOutOfBounds() = %v,\n want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testLatencyAnalyser struct {
+	latencyByPodName
+	fleet float64
+	err   error
+}
+
+func (t *testLatencyAnalyser) doAnalysis() (latencyByPodName, float64, error) {
+	return t.latencyByPodName, t.fleet, t.err
+}
@@ -0,0 +1,169 @@
+package anomalydetector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	promApi "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/expression"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &MetricsExpressionAnalyser{}
+
+//MetricsExpressionAnalyser anomalyDetector that evaluates a boolean expression for each pod over the results of several promQL queries
+type MetricsExpressionAnalyser struct {
+	api.MetricsExpression
+	expr      *expression.Expression
+	queryAPI  promApi.API
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	logger    *zap.Logger
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *MetricsExpressionAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+	// only the pods with traffic are evaluated and used for the fleet aggregates
+	podByName := map[string]*kapiv1.Pod{}
+	for _, p := range listOfPods {
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if traffic {
+			podByName[p.Name] = p
+		}
+	}
+
+	podVariables := []string{}
+	fleetVariables := map[string]api.FleetVariable{}
+	queries := map[string]struct{}{}
+	for _, name := range d.expr.Variables() {
+		if _, ok := d.Queries[name]; ok {
+			podVariables = append(podVariables, name)
+			queries[name] = struct{}{}
+			continue
+		}
+		if v, ok := api.ParseFleetVariable(name, d.Queries); ok {
+			fleetVariables[name] = v
+			queries[v.Query] = struct{}{}
+		}
+	}
+
+	valuesByQuery := map[string]map[string]float64{}
+	for name := range queries {
+		values, err := d.query(d.Queries[name], podByName)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %v", name, err)
+		}
+		valuesByQuery[name] = values
+	}
+
+	fleet := map[string]float64{}
+	for name, v := range fleetVariables {
+		if value, ok := fleetAggregate(v, valuesByQuery[v.Query]); ok {
+			fleet[name] = value
+		}
+	}
+
+	result := []*kapiv1.Pod{}
+	for podName, p := range podByName {
+		vars := map[string]float64{}
+		for name, value := range fleet {
+			vars[name] = value
+		}
+		for _, name := range podVariables {
+			if value, ok := valuesByQuery[name][podName]; ok {
+				vars[name] = value
+			}
+		}
+		outOfBounds, err := d.expr.Eval(vars)
+		if err != nil {
+			d.logger.Sugar().Debugf("the pod %s is not evaluated: %v", podName, err)
+			continue
+		}
+		if outOfBounds {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// query runs the promQL and returns the value by pod name, for the known pods only
+func (d *MetricsExpressionAnalyser) query(promQL string, podByName map[string]*kapiv1.Pod) (map[string]float64, error) {
+	m, err := d.queryAPI.Query(context.Background(), promQL, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error processing prometheus query: %s", err)
+	}
+	vector, ok := m.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("the prometheus query did not return a result in the form of expected type 'model.Vector'")
+	}
+	result := map[string]float64{}
+	for _, sample := range vector {
+		podName := string(sample.Metric[model.LabelName(d.PodNameKey)])
+		value := float64(sample.Value)
+		if _, found := podByName[podName]; !found || math.IsNaN(value) {
+			continue
+		}
+		result[podName] = value
+	}
+	return result, nil
+}
+
+// fleetAggregate computes the aggregate over the values of the pods, false if there is no value
+func fleetAggregate(v api.FleetVariable, values map[string]float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sorted := make([]float64, 0, len(values))
+	for _, value := range values {
+		sorted = append(sorted, value)
+	}
+	sort.Float64s(sorted)
+
+	switch v.Aggregate {
+	case "avg":
+		sum := 0.0
+		for _, value := range sorted {
+			sum += value
+		}
+		return sum / float64(len(sorted)), true
+	case "min":
+		return sorted[0], true
+	case "max":
+		return sorted[len(sorted)-1], true
+	case "median":
+		return percentile(sorted, 0.5), true
+	case "percentile":
+		return percentile(sorted, v.Percentile), true
+	}
+	return 0, false
+}
+
+// percentile of sorted values, with linear interpolation between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(rank)), int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
@@ -0,0 +1,147 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func podVector(values map[string]float64) model.Vector {
+	vector := model.Vector{}
+	for podName, value := range values {
+		vector = append(vector, &model.Sample{
+			Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{"pod": model.LabelValue(podName)})),
+			Value:  model.SampleValue(value),
+		})
+	}
+	return vector
+}
+
+func TestMetricsExpressionAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+
+	podA := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podB := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podC := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podD := test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	podDNoTraffic := test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo)
+
+	queries := map[string]string{"errors": "errors_query", "requests": "requests_query", "latency": "latency_query"}
+	values := map[string]model.Value{
+		"errors_query":   podVector(map[string]float64{"A": 10, "B": 1, "C": 20, "D": 50}),
+		"requests_query": podVector(map[string]float64{"A": 150, "B": 150, "C": 50, "D": 150}),
+		"latency_query":  podVector(map[string]float64{"A": 0.1, "B": 0.1, "C": 0.1, "D": 1}),
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		pods       []*kapiv1.Pod
+		qAPI       *testQueryPrometheusAPI
+		want       []*kapiv1.Pod
+		wantErr    bool
+	}{
+		{
+			name:       "prometheus error",
+			expression: "errors > 0",
+			pods:       []*kapiv1.Pod{podA},
+			qAPI:       &testQueryPrometheusAPI{testPrometheusAPI: testPrometheusAPI{err: fmt.Errorf("A prom Error")}},
+			wantErr:    true,
+		},
+		{
+			name:       "error rate with enough requests",
+			expression: "errors / requests > 0.05 && requests > 100",
+			pods:       []*kapiv1.Pod{podA, podB, podC, podD},
+			qAPI:       &testQueryPrometheusAPI{values: values},
+			want:       []*kapiv1.Pod{podA, podD},
+		},
+		{
+			name:       "fleet percentile",
+			expression: "latency > 2 * fleet_median_latency",
+			pods:       []*kapiv1.Pod{podA, podB, podC, podD},
+			qAPI:       &testQueryPrometheusAPI{values: values},
+			want:       []*kapiv1.Pod{podD},
+		},
+		{
+			name:       "pod without traffic ignored and excluded from the fleet",
+			expression: "latency >= fleet_max_latency",
+			pods:       []*kapiv1.Pod{podA, podB, podC, podDNoTraffic},
+			qAPI:       &testQueryPrometheusAPI{values: values},
+			want:       []*kapiv1.Pod{podA, podB, podC},
+		},
+		{
+			name:       "pod with a missing value not evaluated",
+			expression: "errors > 0",
+			pods:       []*kapiv1.Pod{podA, podB},
+			qAPI: &testQueryPrometheusAPI{values: map[string]model.Value{
+				"errors_query": podVector(map[string]float64{"A": 10}),
+			}},
+			want: []*kapiv1.Pod{podA},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := api.MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: queries, Expression: tt.expression}
+			expr, err := api.CompileMetricsExpression(cfg)
+			if err != nil {
+				t.Fatalf("CompileMetricsExpression() error = %v", err)
+			}
+			d := &MetricsExpressionAnalyser{
+				MetricsExpression: cfg,
+				expr:              expr,
+				queryAPI:          tt.qAPI,
+				selector:          labels.SelectorFromSet(map[string]string{"app": "foo"}),
+				podLister:         test.NewTestPodNamespaceLister(tt.pods, "test-ns"),
+				logger:            devlogger,
+			}
+			got, err := d.GetPodsOutOfBounds()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MetricsExpressionAnalyser.GetPodsOutOfBounds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MetricsExpressionAnalyser.GetPodsOutOfBounds() = %v, want %v", podNames(got), podNames(tt.want))
+			}
+		})
+	}
+}
+
+func Test_fleetAggregate(t *testing.T) {
+	values := map[string]float64{"A": 1, "B": 2, "C": 3, "D": 4, "E": 10}
+	tests := []struct {
+		name   string
+		v      api.FleetVariable
+		values map[string]float64
+		want   float64
+		wantOk bool
+	}{
+		{name: "no value", v: api.FleetVariable{Aggregate: "avg"}, values: nil, wantOk: false},
+		{name: "avg", v: api.FleetVariable{Aggregate: "avg"}, values: values, want: 4, wantOk: true},
+		{name: "min", v: api.FleetVariable{Aggregate: "min"}, values: values, want: 1, wantOk: true},
+		{name: "max", v: api.FleetVariable{Aggregate: "max"}, values: values, want: 10, wantOk: true},
+		{name: "median", v: api.FleetVariable{Aggregate: "median"}, values: values, want: 3, wantOk: true},
+		{name: "p90", v: api.FleetVariable{Aggregate: "percentile", Percentile: 0.9}, values: values, want: 7.6, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fleetAggregate(tt.v, tt.values)
+			if ok != tt.wantOk {
+				t.Fatalf("fleetAggregate() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && (got-tt.want > 1e-9 || tt.want-got > 1e-9) {
+				t.Errorf("fleetAggregate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
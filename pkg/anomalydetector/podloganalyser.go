@@ -0,0 +1,166 @@
+package anomalydetector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ discreteValueAnalyser = &podLogAnalyser{}
+
+// podLogPosition timestamp of the last log line read on a pod
+type podLogPosition struct {
+	uid  types.UID
+	last time.Time
+}
+
+// podLogAnalyser counts the good and bad lines logged by each pod since the previous evaluation
+type podLogAnalyser struct {
+	config    api.PodLogPattern
+	good      []*regexp.Regexp
+	bad       []*regexp.Regexp
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	logger    *zap.Logger
+
+	streamLogs func(p *kapiv1.Pod, opts *kapiv1.PodLogOptions) (io.ReadCloser, error)
+	now        func() time.Time
+
+	// positions by pod name, only accessed by the breaker goroutine
+	positions map[string]podLogPosition
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, line string) bool {
+	for _, r := range patterns {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *podLogAnalyser) doAnalysis() (okkoByPodName, error) {
+	listOfPods, err := a.podLister.List(a.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	now := a.now()
+	result := okkoByPodName{}
+	positions := map[string]podLogPosition{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, maxConcurrentScrapes)
+	for _, p := range listOfPods {
+		position, ok := a.positions[p.Name]
+		if !ok || position.uid != p.UID {
+			// first time the pod is seen: its logs are read starting from now
+			positions[p.Name] = podLogPosition{uid: p.UID, last: now}
+			continue
+		}
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(p *kapiv1.Pod, position podLogPosition) {
+			defer wg.Done()
+			defer func() { <-tokens }()
+			counters, last, err := a.readLogs(p, position.last, now)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				a.logger.Sugar().Warnf("can't read the logs of pod %s: %v", p.Name, err)
+				positions[p.Name] = position
+				return
+			}
+			positions[p.Name] = podLogPosition{uid: p.UID, last: last}
+			result[p.Name] = counters
+		}(p, position)
+	}
+	wg.Wait()
+	a.positions = positions
+	return result, nil
+}
+
+// readLogs counts the lines logged after since. It returns the timestamp of the last line read, or now if the logs were truncated
+func (a *podLogAnalyser) readLogs(p *kapiv1.Pod, since, now time.Time) (okkoCount, time.Time, error) {
+	counters := okkoCount{}
+	sinceTime := metav1.NewTime(since)
+	limit := *a.config.MaxBytesPerPod
+	stream, err := a.streamLogs(p, &kapiv1.PodLogOptions{
+		Container:  a.config.Container,
+		SinceTime:  &sinceTime,
+		Timestamps: true,
+		LimitBytes: &limit,
+	})
+	if err != nil {
+		return counters, since, err
+	}
+	defer stream.Close()
+
+	last := since
+	var read int64
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		read += int64(len(line))
+		if err != nil && err != io.EOF {
+			return counters, since, err
+		}
+		if err == io.EOF && read >= limit {
+			// the logs were truncated: the last line may be partial and the next evaluation starts from now
+			a.logger.Sugar().Debugf("the logs of pod %s exceed %d bytes, they are truncated", p.Name, limit)
+			return counters, now, nil
+		}
+		if line != "" {
+			// the API prefixes each line with its RFC3339Nano timestamp
+			timestamp, message := line, ""
+			if i := strings.IndexByte(line, ' '); i >= 0 {
+				timestamp, message = line[:i], line[i+1:]
+			}
+			t, errParse := time.Parse(time.RFC3339Nano, timestamp)
+			// SinceTime has a precision of one second: the lines already counted are skipped
+			if errParse == nil && t.After(last) {
+				last = t
+				message = strings.TrimRight(message, "\r\n")
+				switch {
+				case matchAny(a.bad, message):
+					counters.ko++
+				case len(a.good) == 0 || matchAny(a.good, message):
+					counters.ok++
+				}
+			}
+		}
+		if err == io.EOF {
+			return counters, last, nil
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package anomalydetector
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+type testLogLine struct {
+	at      time.Time
+	message string
+}
+
+// testLogs emulates the pod log API: the lines are filtered with SinceTime (second precision) and LimitBytes
+func testLogs(logs map[string][]testLogLine) func(p *kapiv1.Pod, opts *kapiv1.PodLogOptions) (io.ReadCloser, error) {
+	return func(p *kapiv1.Pod, opts *kapiv1.PodLogOptions) (io.ReadCloser, error) {
+		lines, ok := logs[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("no logs for pod %s", p.Name)
+		}
+		var b bytes.Buffer
+		for _, l := range lines {
+			if l.at.Before(opts.SinceTime.Time.Truncate(time.Second)) {
+				continue
+			}
+			fmt.Fprintf(&b, "%s %s\n", l.at.Format(time.RFC3339Nano), l.message)
+		}
+		s := b.String()
+		if opts.LimitBytes != nil && int64(len(s)) > *opts.LimitBytes {
+			s = s[:*opts.LimitBytes]
+		}
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+}
+
+func Test_podLogAnalyser_doAnalysis(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	t0 := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	pods := []*kapiv1.Pod{}
+	for _, name := range []string{"A", "B"} {
+		p := test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+		p.UID = types.UID("uid-" + name)
+		pods = append(pods, p)
+	}
+
+	config := *api.DefaultPodLogPattern(&api.PodLogPattern{BadPatterns: []string{"pool exhausted", "^ERROR"}})
+	bad, _ := compilePatterns(config.BadPatterns)
+	a := &podLogAnalyser{
+		config:    config,
+		bad:       bad,
+		selector:  labels.Everything(),
+		podLister: test.NewTestPodNamespaceLister(pods, "test-ns"),
+		logger:    devlogger,
+		positions: map[string]podLogPosition{},
+	}
+
+	logs := map[string][]testLogLine{
+		"A": {
+			{t0.Add(-time.Second), "ERROR before the first evaluation"},
+			{t0.Add(100 * time.Millisecond), "request served"},
+			{t0.Add(200 * time.Millisecond), "connection pool exhausted"},
+			{t0.Add(3 * time.Second), "request served"},
+		},
+		"B": {
+			{t0.Add(100 * time.Millisecond), "ERROR something"},
+			{t0.Add(200 * time.Millisecond), "request served"},
+		},
+	}
+	a.streamLogs = testLogs(logs)
+
+	ticks := []struct {
+		at   time.Time
+		want okkoByPodName
+	}{
+		{at: t0, want: okkoByPodName{}},
+		{at: t0.Add(5 * time.Second), want: okkoByPodName{"A": {ok: 2, ko: 1}, "B": {ok: 1, ko: 1}}},
+		{at: t0.Add(10 * time.Second), want: okkoByPodName{"A": {}, "B": {}}},
+	}
+	for i, tick := range ticks {
+		at := tick.at
+		a.now = func() time.Time { return at }
+		got, err := a.doAnalysis()
+		if err != nil {
+			t.Fatalf("tick %d: podLogAnalyser.doAnalysis() unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, tick.want) {
+			t.Errorf("tick %d: podLogAnalyser.doAnalysis() = %v, want %v", i, got, tick.want)
+		}
+	}
+
+	// new lines logged in the same second than the last line read
+	logs["A"] = append(logs["A"], testLogLine{t0.Add(3500 * time.Millisecond), "ERROR late"})
+	a.now = func() time.Time { return t0.Add(15 * time.Second) }
+	if got, _ := a.doAnalysis(); got["A"] != (okkoCount{ko: 1}) {
+		t.Errorf("podLogAnalyser.doAnalysis() = %v, want A: {0 1}", got)
+	}
+
+	// truncated logs: the next evaluation starts from now
+	for i := 0; i < 100; i++ {
+		logs["B"] = append(logs["B"], testLogLine{t0.Add(16*time.Second + time.Duration(i)*time.Millisecond), "request served"})
+	}
+	logs["B"] = append(logs["B"], testLogLine{t0.Add(19 * time.Second), "ERROR after truncation"})
+	a.config.MaxBytesPerPod = api.NewInt64(500)
+	a.now = func() time.Time { return t0.Add(20 * time.Second) }
+	got, _ := a.doAnalysis()
+	if got["B"].ko != 0 || got["B"].ok == 0 || got["B"].ok >= 100 {
+		t.Errorf("podLogAnalyser.doAnalysis() with truncation = %v", got)
+	}
+	if last := a.positions["B"].last; !last.Equal(t0.Add(20 * time.Second)) {
+		t.Errorf("position after truncation = %v, want %v", last, t0.Add(20*time.Second))
+	}
+}
+
+func Test_podLogAnalyser_GoodPatterns(t *testing.T) {
+	good, _ := compilePatterns([]string{"served"})
+	bad, _ := compilePatterns([]string{"ERROR"})
+	a := &podLogAnalyser{good: good, bad: bad, config: *api.DefaultPodLogPattern(&api.PodLogPattern{})}
+	t0 := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	a.streamLogs = testLogs(map[string][]testLogLine{"A": {
+		{t0.Add(time.Second), "request served"},
+		{t0.Add(2 * time.Second), "debug trace"},
+		{t0.Add(3 * time.Second), "ERROR request not served"},
+	}})
+	p := test.PodGen("A", "test-ns", nil, nil, true, true, labeling.LabelTrafficYes)
+	got, last, err := a.readLogs(p, t0, t0.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("podLogAnalyser.readLogs() unexpected error: %v", err)
+	}
+	if got != (okkoCount{ok: 1, ko: 1}) {
+		t.Errorf("podLogAnalyser.readLogs() = %v, want {1 1}", got)
+	}
+	if !last.Equal(t0.Add(3 * time.Second)) {
+		t.Errorf("podLogAnalyser.readLogs() last = %v, want %v", last, t0.Add(3*time.Second))
+	}
+}
@@ -0,0 +1,137 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+const (
+	// PodIndexByIP name of the pod informer index on the pod IP
+	PodIndexByIP = "kubervisor/podIP"
+	// PodIndexByLabel name of the pod informer index on each label of the pod
+	PodIndexByLabel = "kubervisor/podLabel"
+)
+
+// PodIndexers indexers to add to the pod informer for the lookups of the PodMatch
+var PodIndexers = cache.Indexers{
+	PodIndexByIP:    indexPodByIP,
+	PodIndexByLabel: indexPodByLabel,
+}
+
+func indexPodByIP(obj interface{}) ([]string, error) {
+	p, ok := obj.(*kapiv1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("not a pod: %T", obj)
+	}
+	if p.Status.PodIP == "" {
+		return []string{}, nil
+	}
+	return []string{podIndexKey(p.Namespace, p.Status.PodIP)}, nil
+}
+
+func indexPodByLabel(obj interface{}) ([]string, error) {
+	p, ok := obj.(*kapiv1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("not a pod: %T", obj)
+	}
+	keys := []string{}
+	for k, v := range p.Labels {
+		if v != "" {
+			keys = append(keys, podIndexKey(p.Namespace, k+"="+v))
+		}
+	}
+	return keys, nil
+}
+
+func podIndexKey(namespace, value string) string {
+	return namespace + "/" + value
+}
+
+// podIndex finds the pods identified by the value of a metric label, as defined by a PodMatch
+// The lookups use the indexes of the pod informer, only the ready pods matching the selector are returned
+type podIndex struct {
+	match     api.PodMatch
+	namespace string
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	indexer   cache.Indexer
+}
+
+// newPodIndex looks up the pods by the attribute used by the match, the pod name if match is nil
+func newPodIndex(match *api.PodMatch, namespace string, selector labels.Selector, podLister kv1.PodNamespaceLister, indexer cache.Indexer) (*podIndex, error) {
+	index := &podIndex{match: api.PodMatch{By: api.PodMatchByName}, namespace: namespace, selector: selector, podLister: podLister, indexer: indexer}
+	if match != nil {
+		index.match = *match
+	}
+	if name := index.indexName(); name != "" {
+		if indexer == nil {
+			return nil, fmt.Errorf("no pod indexer for the pod match by %s", index.match.By)
+		}
+		if _, ok := indexer.GetIndexers()[name]; !ok {
+			return nil, fmt.Errorf("the pod indexer has no index %s", name)
+		}
+	}
+	return index, nil
+}
+
+// indexName returns the name of the informer index used by the match, empty for a match by name
+func (i *podIndex) indexName() string {
+	switch i.match.By {
+	case api.PodMatchByIP:
+		return PodIndexByIP
+	case api.PodMatchByLabel:
+		return PodIndexByLabel
+	default:
+		return ""
+	}
+}
+
+// lookup returns the pods identified by the metric label value. Several pods are returned when they share the label value of a label match
+func (i *podIndex) lookup(value string) []*kapiv1.Pod {
+	candidates := []*kapiv1.Pod{}
+	switch i.match.By {
+	case api.PodMatchByIP:
+		if i.match.StripPort {
+			if host, _, err := net.SplitHostPort(value); err == nil {
+				value = host
+			}
+		}
+		candidates = i.byIndex(PodIndexByIP, value)
+	case api.PodMatchByLabel:
+		candidates = i.byIndex(PodIndexByLabel, i.match.Label+"="+value)
+	default:
+		if p, err := i.podLister.Get(value); err == nil {
+			candidates = append(candidates, p)
+		}
+	}
+
+	var result []*kapiv1.Pod
+	for _, p := range candidates {
+		if i.selector.Matches(labels.Set(p.Labels)) && pod.IsReady(p) {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Name < result[b].Name })
+	return result
+}
+
+func (i *podIndex) byIndex(indexName, value string) []*kapiv1.Pod {
+	// the index existence is checked by newPodIndex
+	objs, _ := i.indexer.ByIndex(indexName, podIndexKey(i.namespace, value))
+	pods := []*kapiv1.Pod{}
+	for _, obj := range objs {
+		if p, ok := obj.(*kapiv1.Pod); ok {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
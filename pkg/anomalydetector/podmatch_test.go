@@ -0,0 +1,88 @@
+package anomalydetector
+
+import (
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func podGenWithIP(name, ip string, labels map[string]string) *kapiv1.Pod {
+	p := test.PodGen(name, "test-ns", labels, nil, true, true, labeling.LabelTrafficYes)
+	p.Status.PodIP = ip
+	return p
+}
+
+// testPodIndexer returns an indexer with the PodIndexers containing the pods of the lister
+func testPodIndexer(podLister kv1.PodNamespaceLister) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, PodIndexers)
+	pods, _ := podLister.List(labels.Everything())
+	for _, p := range pods {
+		indexer.Add(p)
+	}
+	return indexer
+}
+
+func Test_podIndex_lookup(t *testing.T) {
+	podA := podGenWithIP("A", "10.0.0.1", map[string]string{"instance": "a", "pod-template-hash": "1234"})
+	podB := podGenWithIP("B", "10.0.0.2", map[string]string{"instance": "b", "pod-template-hash": "1234"})
+	podV6 := podGenWithIP("V6", "fd00::1", nil)
+	notReady := podGenWithIP("N", "10.0.0.3", map[string]string{"instance": "n"})
+	notReady.Status.Conditions = nil
+	otherNamespace := podGenWithIP("O", "10.0.0.4", map[string]string{"instance": "o"})
+	otherNamespace.Namespace = "other-ns"
+	podLister := test.NewTestPodNamespaceLister([]*kapiv1.Pod{podA, podB, podV6, notReady, otherNamespace}, "test-ns")
+	indexer := testPodIndexer(test.NewTestPodLister([]*kapiv1.Pod{podA, podB, podV6, notReady, otherNamespace}).Pods(""))
+
+	tests := []struct {
+		name  string
+		match *api.PodMatch
+		value string
+		want  []*kapiv1.Pod
+	}{
+		{name: "default by name", match: nil, value: "A", want: []*kapiv1.Pod{podA}},
+		{name: "unknown name", match: nil, value: "Z", want: nil},
+		{name: "by ip", match: &api.PodMatch{By: api.PodMatchByIP}, value: "10.0.0.2", want: []*kapiv1.Pod{podB}},
+		{name: "by ip with port not stripped", match: &api.PodMatch{By: api.PodMatchByIP}, value: "10.0.0.2:8080", want: nil},
+		{name: "by ip with port stripped", match: &api.PodMatch{By: api.PodMatchByIP, StripPort: true}, value: "10.0.0.2:8080", want: []*kapiv1.Pod{podB}},
+		{name: "by ip without port stripped", match: &api.PodMatch{By: api.PodMatchByIP, StripPort: true}, value: "10.0.0.1", want: []*kapiv1.Pod{podA}},
+		{name: "by ipv6 with port stripped", match: &api.PodMatch{By: api.PodMatchByIP, StripPort: true}, value: "[fd00::1]:8080", want: []*kapiv1.Pod{podV6}},
+		{name: "by label", match: &api.PodMatch{By: api.PodMatchByLabel, Label: "instance"}, value: "b", want: []*kapiv1.Pod{podB}},
+		{name: "by shared label", match: &api.PodMatch{By: api.PodMatchByLabel, Label: "pod-template-hash"}, value: "1234", want: []*kapiv1.Pod{podA, podB}},
+		{name: "by label empty value", match: &api.PodMatch{By: api.PodMatchByLabel, Label: "instance"}, value: "", want: nil},
+		{name: "not ready", match: &api.PodMatch{By: api.PodMatchByLabel, Label: "instance"}, value: "n", want: nil},
+		{name: "other namespace", match: &api.PodMatch{By: api.PodMatchByIP}, value: "10.0.0.4", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := newPodIndex(tt.match, "test-ns", labels.Everything(), podLister, indexer)
+			if err != nil {
+				t.Fatalf("newPodIndex() unexpected error: %v", err)
+			}
+			if got := index.lookup(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("podIndex.lookup() = %v, want %v", podNames(got), podNames(tt.want))
+			}
+		})
+	}
+}
+
+func Test_newPodIndex(t *testing.T) {
+	podLister := test.NewTestPodNamespaceLister(nil, "test-ns")
+	if _, err := newPodIndex(nil, "test-ns", labels.Everything(), podLister, nil); err != nil {
+		t.Errorf("newPodIndex() by name without indexer, unexpected error: %v", err)
+	}
+	if _, err := newPodIndex(&api.PodMatch{By: api.PodMatchByIP}, "test-ns", labels.Everything(), podLister, nil); err == nil {
+		t.Errorf("newPodIndex() by ip without indexer, expected an error")
+	}
+	noIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if _, err := newPodIndex(&api.PodMatch{By: api.PodMatchByLabel, Label: "instance"}, "test-ns", labels.Everything(), podLister, noIndex); err == nil {
+		t.Errorf("newPodIndex() by label without the label index, expected an error")
+	}
+}
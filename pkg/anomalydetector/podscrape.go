@@ -0,0 +1,163 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+// maxConcurrentScrapes limits the number of pods scraped in parallel
+const maxConcurrentScrapes = 10
+
+type seriesValues map[model.Fingerprint]model.SampleValue
+
+var _ counterSource = &podMetricsScraper{}
+
+// podMetricsScraper reads the metrics exposed by each pod and computes the counters increase between two evaluations
+type podMetricsScraper struct {
+	config    api.PodMetricsScrape
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	client    *http.Client
+	logger    *zap.Logger
+	podURL    func(p *kapiv1.Pod) string
+
+	// previous values by pod name, only accessed by the breaker goroutine
+	previous map[string]seriesValues
+}
+
+func newPodMetricsScraper(config api.PodMetricsScrape, cfg Config) *podMetricsScraper {
+	s := &podMetricsScraper{
+		config:    config,
+		selector:  cfg.Selector,
+		podLister: cfg.PodLister,
+		logger:    cfg.Logger,
+		client:    &http.Client{Timeout: time.Duration(*config.Timeout*1000) * time.Millisecond},
+		previous:  map[string]seriesValues{},
+	}
+	s.podURL = func(p *kapiv1.Pod) string {
+		return fmt.Sprintf("http://%s:%d%s", p.Status.PodIP, s.config.Port, s.config.Path)
+	}
+	return s
+}
+
+// deltas returns by pod name the increase since the previous call of the series with one of the given names
+// A pod scraped for the first time is not part of the result. A counter reset is handled like prometheus does.
+func (s *podMetricsScraper) deltas(names ...string) (map[string]model.Vector, error) {
+	current, err := s.scrapeAll()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[model.LabelValue]struct{}{}
+	for _, n := range names {
+		wanted[model.LabelValue(n)] = struct{}{}
+	}
+
+	result := map[string]model.Vector{}
+	newPrevious := map[string]seriesValues{}
+	for podName, vector := range current {
+		previous, known := s.previous[podName]
+		values := seriesValues{}
+		deltas := model.Vector{}
+		for _, sample := range vector {
+			if _, ok := wanted[sample.Metric[model.MetricNameLabel]]; !ok {
+				continue
+			}
+			fp := sample.Metric.Fingerprint()
+			values[fp] = sample.Value
+			if !known {
+				continue
+			}
+			delta := sample.Value
+			if prev, ok := previous[fp]; ok && sample.Value >= prev {
+				delta = sample.Value - prev
+			}
+			deltas = append(deltas, &model.Sample{Metric: sample.Metric, Value: delta, Timestamp: sample.Timestamp})
+		}
+		newPrevious[podName] = values
+		if known {
+			result[podName] = deltas
+		}
+	}
+	s.previous = newPrevious
+	return result, nil
+}
+
+// scrapeAll scrapes all the ready pods with a bounded concurrency. Pods that can't be scraped are skipped.
+func (s *podMetricsScraper) scrapeAll() (map[string]model.Vector, error) {
+	listOfPods, err := s.podLister.List(s.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+
+	result := map[string]model.Vector{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, maxConcurrentScrapes)
+	for _, p := range listOfPods {
+		if p.Status.PodIP == "" {
+			continue
+		}
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(p *kapiv1.Pod) {
+			defer wg.Done()
+			defer func() { <-tokens }()
+			vector, err := s.scrape(p)
+			if err != nil {
+				s.logger.Sugar().Warnf("can't scrape metrics of pod %s: %v", p.Name, err)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			result[p.Name] = vector
+		}(p)
+	}
+	wg.Wait()
+	return result, nil
+}
+
+func (s *podMetricsScraper) scrape(p *kapiv1.Pod) (model.Vector, error) {
+	response, err := s.client.Get(s.podURL(p))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the pod did not respond Ok (200) but %d", response.StatusCode)
+	}
+
+	decoder := &expfmt.SampleDecoder{
+		Dec:  expfmt.NewDecoder(response.Body, expfmt.ResponseFormat(response.Header)),
+		Opts: &expfmt.DecodeOptions{Timestamp: model.Now()},
+	}
+	result := model.Vector{}
+	for {
+		var vector model.Vector
+		if err := decoder.Decode(&vector); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("can't decode metrics: %v", err)
+		}
+		result = append(result, vector...)
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+// testMetricsHandler serves the metrics of each pod on /<podname>
+type testMetricsHandler struct {
+	sync.Mutex
+	metrics map[string]string
+}
+
+func (h *testMetricsHandler) set(metrics map[string]string) {
+	h.Lock()
+	defer h.Unlock()
+	h.metrics = metrics
+}
+
+func (h *testMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Lock()
+	defer h.Unlock()
+	m, ok := h.metrics[strings.TrimPrefix(r.URL.Path, "/")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, m)
+}
+
+func newTestScraper(t *testing.T, serverURL string, metric string) *podMetricsScraper {
+	devlogger, _ := zap.NewDevelopment()
+	pods := []*kapiv1.Pod{}
+	for _, name := range []string{"A", "B", "C"} {
+		p := test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+		p.Status.PodIP = "127.0.0.1"
+		pods = append(pods, p)
+	}
+	s := newPodMetricsScraper(*api.DefaultPodMetricsScrape(&api.PodMetricsScrape{Port: 8080, Metric: metric}), Config{
+		Selector:  labels.Everything(),
+		PodLister: test.NewTestPodNamespaceLister(pods, "test-ns"),
+		Logger:    devlogger,
+	})
+	s.podURL = func(p *kapiv1.Pod) string { return serverURL + "/" + p.Name }
+	return s
+}
+
+func Test_counterDiscreteValueOutOfListAnalyser_doAnalysis(t *testing.T) {
+	handler := &testMetricsHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	a := &counterDiscreteValueOutOfListAnalyser{
+		config:           api.DiscreteValueOutOfList{Key: "code", PodMetricsScrape: &api.PodMetricsScrape{Metric: "rpc_count"}},
+		metric:           "rpc_count",
+		source:           newTestScraper(t, server.URL, "rpc_count"),
+		valueCheckerFunc: func(value string) bool { return ContainsString([]string{"200"}, value) },
+	}
+
+	steps := []struct {
+		metrics map[string]string
+		want    okkoByPodName
+	}{
+		{
+			metrics: map[string]string{
+				"A": "rpc_count{code=\"200\"} 10\nrpc_count{code=\"500\"} 1\nother_count 12\n",
+				"B": "rpc_count{code=\"200\"} 10\nrpc_count{code=\"500\"} 1\n",
+			},
+			want: okkoByPodName{},
+		},
+		{
+			metrics: map[string]string{
+				"A": "rpc_count{code=\"200\"} 20\nrpc_count{code=\"500\"} 1\nother_count 50\n",
+				"B": "rpc_count{code=\"200\"} 12\nrpc_count{code=\"500\"} 9\n",
+				"C": "rpc_count{code=\"200\"} 10\n",
+			},
+			want: okkoByPodName{"A": {10, 0}, "B": {2, 8}},
+		},
+		{
+			// B restarted: counter reset
+			metrics: map[string]string{
+				"A": "rpc_count{code=\"200\"} 25\nrpc_count{code=\"500\"} 1\n",
+				"B": "rpc_count{code=\"200\"} 3\nrpc_count{code=\"404\"} 2\n",
+				"C": "rpc_count{code=\"200\"} 10\n",
+			},
+			want: okkoByPodName{"A": {5, 0}, "B": {3, 2}, "C": {0, 0}},
+		},
+	}
+	for i, step := range steps {
+		handler.set(step.metrics)
+		got, err := a.doAnalysis()
+		if err != nil {
+			t.Fatalf("step %d: counterDiscreteValueOutOfListAnalyser.doAnalysis() unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d: counterDiscreteValueOutOfListAnalyser.doAnalysis() = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func Test_counterContinuousValueDeviationAnalyser_doAnalysis(t *testing.T) {
+	handler := &testMetricsHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	a := &counterContinuousValueDeviationAnalyser{
+		config: api.ContinuousValueDeviation{PodMetricsScrape: &api.PodMetricsScrape{Metric: "price"}},
+		metric: "price",
+		source: newTestScraper(t, server.URL, "price"),
+	}
+
+	handler.set(map[string]string{
+		"A": "# TYPE price summary\nprice_sum 100\nprice_count 10\n",
+		"B": "# TYPE price summary\nprice_sum 100\nprice_count 10\n",
+		"C": "# TYPE price summary\nprice_sum 100\nprice_count 10\n",
+	})
+	if got, err := a.doAnalysis(); err != nil || len(got) != 0 {
+		t.Fatalf("first scrape: counterContinuousValueDeviationAnalyser.doAnalysis() = %v, %v", got, err)
+	}
+
+	handler.set(map[string]string{
+		"A": "# TYPE price summary\nprice_sum 200\nprice_count 20\n",
+		"B": "# TYPE price summary\nprice_sum 400\nprice_count 20\n",
+		"C": "# TYPE price summary\nprice_sum 100\nprice_count 10\n",
+	})
+	got, err := a.doAnalysis()
+	if err != nil {
+		t.Fatalf("counterContinuousValueDeviationAnalyser.doAnalysis() unexpected error: %v", err)
+	}
+	// fleet average: 400/20=20; A: 10, B: 30
+	want := deviationByPodName{"A": 0.5, "B": 1.5}
+	if len(got) != len(want) {
+		t.Fatalf("counterContinuousValueDeviationAnalyser.doAnalysis() = %v, want %v", got, want)
+	}
+	for pod, w := range want {
+		if math.Abs(got[pod]-w) > 1e-9 {
+			t.Errorf("counterContinuousValueDeviationAnalyser.doAnalysis() = %v, want %v", got, want)
+		}
+	}
+}
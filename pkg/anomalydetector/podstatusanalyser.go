@@ -0,0 +1,168 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &PodStatusAnalyser{}
+
+type restartSample struct {
+	at    time.Time
+	count int32
+}
+
+// podStatusHistory what was observed on a pod during the previous evaluations
+type podStatusHistory struct {
+	uid                 types.UID
+	restarts            []restartSample
+	lastReadyTransition metav1.Time
+	readyFlips          []time.Time
+}
+
+//PodStatusAnalyser anomalyDetector that only relies on the pod status: container restarts, termination reasons and Ready condition flapping
+type PodStatusAnalyser struct {
+	api.PodStatusAnomaly
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	logger    *zap.Logger
+
+	// history is only accessed by the breaker goroutine that calls GetPodsOutOfBounds
+	history map[string]*podStatusHistory
+	now     func() time.Time
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *PodStatusAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	// Not ready pods are kept: a pod with a flapping readiness is not ready half of the time
+	listOfPods, err = pod.KeepRunningPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't keep running pods, error:%v", err)
+	}
+
+	now := d.now()
+	windowStart := now.Add(-time.Duration(*d.Window*1000) * time.Millisecond)
+	seen := map[string]struct{}{}
+	result := []*kapiv1.Pod{}
+
+	for _, p := range listOfPods {
+		seen[p.Name] = struct{}{}
+		h := d.updateHistory(p, now, windowStart)
+
+		traffic, _, err2 := labeling.IsPodTrafficLabelOkOrPause(p)
+		if err2 != nil {
+			return nil, err2
+		}
+		if !traffic {
+			d.logger.Sugar().Debugf("the pod %s status is ignored now has it is marked out of traffic\n", p.Name)
+			continue
+		}
+
+		if reason := d.anomalyReason(p, h, windowStart); reason != "" {
+			d.logger.Sugar().Infof("the pod %s is out of bounds: %s", p.Name, reason)
+			result = append(result, p)
+		}
+	}
+
+	// forget the pods that disappeared
+	for name := range d.history {
+		if _, ok := seen[name]; !ok {
+			delete(d.history, name)
+		}
+	}
+	return result, nil
+}
+
+// updateHistory records the current status of the pod and drops the samples that are out of the window
+func (d *PodStatusAnalyser) updateHistory(p *kapiv1.Pod, now, windowStart time.Time) *podStatusHistory {
+	h, ok := d.history[p.Name]
+	if !ok || h.uid != p.UID {
+		h = &podStatusHistory{uid: p.UID}
+		d.history[p.Name] = h
+		if ready := getReadyCondition(p); ready != nil {
+			// The first observed transition is the pod startup, not a flip
+			h.lastReadyTransition = ready.LastTransitionTime
+		}
+	} else if ready := getReadyCondition(p); ready != nil && !ready.LastTransitionTime.Equal(&h.lastReadyTransition) {
+		h.lastReadyTransition = ready.LastTransitionTime
+		h.readyFlips = append(h.readyFlips, now)
+	}
+
+	h.restarts = append(h.restarts, restartSample{at: now, count: restartCount(p)})
+
+	// keep the last sample taken before the window as reference for the restart count
+	firstInWindow := 0
+	for i, s := range h.restarts {
+		if !s.at.Before(windowStart) {
+			break
+		}
+		firstInWindow = i
+	}
+	h.restarts = h.restarts[firstInWindow:]
+
+	flips := h.readyFlips[:0]
+	for _, f := range h.readyFlips {
+		if !f.Before(windowStart) {
+			flips = append(flips, f)
+		}
+	}
+	h.readyFlips = flips
+	return h
+}
+
+// anomalyReason returns a non empty string if the pod is out of bounds
+func (d *PodStatusAnalyser) anomalyReason(p *kapiv1.Pod, h *podStatusHistory, windowStart time.Time) string {
+	if len(h.restarts) > 1 {
+		increase := h.restarts[len(h.restarts)-1].count - h.restarts[0].count
+		if increase > int32(*d.MaxRestartCount) {
+			return fmt.Sprintf("%d container restarts", increase)
+		}
+	}
+
+	for _, c := range p.Status.ContainerStatuses {
+		terminated := c.LastTerminationState.Terminated
+		if terminated == nil || terminated.FinishedAt.Time.Before(windowStart) {
+			continue
+		}
+		if ContainsString(d.TerminationReasons, terminated.Reason) {
+			return fmt.Sprintf("container %s terminated with reason %s", c.Name, terminated.Reason)
+		}
+	}
+
+	if uint(len(h.readyFlips)) > *d.MaxReadyFlipCount {
+		return fmt.Sprintf("%d Ready condition transitions", len(h.readyFlips))
+	}
+	return ""
+}
+
+func restartCount(p *kapiv1.Pod) int32 {
+	var count int32
+	for _, c := range p.Status.ContainerStatuses {
+		count += c.RestartCount
+	}
+	return count
+}
+
+func getReadyCondition(p *kapiv1.Pod) *kapiv1.PodCondition {
+	for i := range p.Status.Conditions {
+		if p.Status.Conditions[i].Type == kapiv1.PodReady {
+			return &p.Status.Conditions[i]
+		}
+	}
+	return nil
+}
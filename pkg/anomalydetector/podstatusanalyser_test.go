@@ -0,0 +1,110 @@
+package anomalydetector
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func statusPodGen(name string, restarts int32, readyTransition time.Time, terminated *kapiv1.ContainerStateTerminated) *kapiv1.Pod {
+	p := test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	p.UID = types.UID("uid-" + name)
+	p.Status.Conditions[0].LastTransitionTime = metav1.NewTime(readyTransition)
+	p.Status.ContainerStatuses = []kapiv1.ContainerStatus{{Name: "main", RestartCount: restarts}}
+	if terminated != nil {
+		p.Status.ContainerStatuses[0].LastTerminationState.Terminated = terminated
+	}
+	return p
+}
+
+func TestPodStatusAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	t0 := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	type tick struct {
+		at   time.Time
+		pods []*kapiv1.Pod
+		want []string
+	}
+	tests := []struct {
+		name   string
+		config api.PodStatusAnomaly
+		ticks  []tick
+	}{
+		{
+			name:   "healthy pods",
+			config: *api.DefaultPodStatusAnomaly(&api.PodStatusAnomaly{}),
+			ticks: []tick{
+				{at: t0, pods: []*kapiv1.Pod{statusPodGen("A", 0, t0.Add(-time.Hour), nil)}, want: []string{}},
+				{at: t0.Add(10 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 0, t0.Add(-time.Hour), nil)}, want: []string{}},
+			},
+		},
+		{
+			name:   "restart in window",
+			config: *api.DefaultPodStatusAnomaly(&api.PodStatusAnomaly{Window: api.NewFloat64(60), MaxRestartCount: api.NewUInt(1)}),
+			ticks: []tick{
+				{at: t0, pods: []*kapiv1.Pod{statusPodGen("A", 3, t0.Add(-time.Hour), nil), statusPodGen("B", 0, t0.Add(-time.Hour), nil)}, want: []string{}},
+				{at: t0.Add(10 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 4, t0.Add(-time.Hour), nil), statusPodGen("B", 0, t0.Add(-time.Hour), nil)}, want: []string{}},
+				{at: t0.Add(20 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 5, t0.Add(-time.Hour), nil), statusPodGen("B", 0, t0.Add(-time.Hour), nil)}, want: []string{"A"}},
+				{at: t0.Add(65 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 5, t0.Add(-time.Hour), nil), statusPodGen("B", 0, t0.Add(-time.Hour), nil)}, want: []string{"A"}},
+				{at: t0.Add(90 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 5, t0.Add(-time.Hour), nil), statusPodGen("B", 0, t0.Add(-time.Hour), nil)}, want: []string{}},
+			},
+		},
+		{
+			name:   "OOMKilled",
+			config: *api.DefaultPodStatusAnomaly(&api.PodStatusAnomaly{Window: api.NewFloat64(60), MaxRestartCount: api.NewUInt(5)}),
+			ticks: []tick{
+				{at: t0, pods: []*kapiv1.Pod{
+					statusPodGen("A", 1, t0.Add(-time.Hour), &kapiv1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: metav1.NewTime(t0.Add(-10 * time.Second))}),
+					statusPodGen("B", 1, t0.Add(-time.Hour), &kapiv1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: metav1.NewTime(t0.Add(-10 * time.Minute))}),
+					statusPodGen("C", 1, t0.Add(-time.Hour), &kapiv1.ContainerStateTerminated{Reason: "Completed", FinishedAt: metav1.NewTime(t0.Add(-10 * time.Second))}),
+				}, want: []string{"A"}},
+			},
+		},
+		{
+			name:   "ready flapping",
+			config: *api.DefaultPodStatusAnomaly(&api.PodStatusAnomaly{Window: api.NewFloat64(60), MaxReadyFlipCount: api.NewUInt(1)}),
+			ticks: []tick{
+				{at: t0, pods: []*kapiv1.Pod{statusPodGen("A", 0, t0.Add(-time.Second), nil)}, want: []string{}},
+				{at: t0.Add(10 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 0, t0.Add(5*time.Second), nil)}, want: []string{}},
+				{at: t0.Add(20 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 0, t0.Add(15*time.Second), nil)}, want: []string{"A"}},
+				{at: t0.Add(80 * time.Second), pods: []*kapiv1.Pod{statusPodGen("A", 0, t0.Add(15*time.Second), nil)}, want: []string{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &PodStatusAnalyser{
+				PodStatusAnomaly: tt.config,
+				selector:         labels.Everything(),
+				logger:           devlogger,
+				history:          map[string]*podStatusHistory{},
+			}
+			for i, tick := range tt.ticks {
+				d.podLister = test.NewTestPodNamespaceLister(tick.pods, "test-ns")
+				at := tick.at
+				d.now = func() time.Time { return at }
+				got, err := d.GetPodsOutOfBounds()
+				if err != nil {
+					t.Fatalf("tick %d: PodStatusAnalyser.GetPodsOutOfBounds() unexpected error %v", i, err)
+				}
+				gotNames := []string{}
+				for _, p := range got {
+					gotNames = append(gotNames, p.Name)
+				}
+				if len(gotNames) != len(tick.want) || (len(gotNames) > 0 && gotNames[0] != tick.want[0]) {
+					t.Errorf("tick %d: PodStatusAnalyser.GetPodsOutOfBounds() = %v, want %v", i, gotNames, tick.want)
+				}
+			}
+		})
+	}
+}
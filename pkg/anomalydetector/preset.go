@@ -0,0 +1,45 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+)
+
+// presetDiscreteValueOutOfList returns the DiscreteValueOutOfList generated by the preset for the workload of the namespace
+func presetDiscreteValueOutOfList(preset api.DetectorPreset, namespace string) (*api.DiscreteValueOutOfList, error) {
+	if preset.Namespace != "" {
+		namespace = preset.Namespace
+	}
+	d := &api.DiscreteValueOutOfList{
+		PrometheusService:    preset.PrometheusService,
+		TolerancePercent:     preset.TolerancePercent,
+		MinimumActivityCount: preset.MinimumActivityCount,
+	}
+	switch preset.Kind {
+	case api.DetectorPresetIstioHTTP5xx:
+		// the response codes are grouped by class (2xx, 5xx...) so that any 5xx is bad
+		d.PromQL = fmt.Sprintf(`label_replace(sum(increase(istio_requests_total{reporter="destination",destination_workload_namespace="%s",destination_workload="%s"}[%s])) by (response_code,pod), "response_class", "${1}xx", "response_code", "(.).*")`, namespace, preset.Workload, preset.Window)
+		d.Key = "response_class"
+		d.PodNameKey = "pod"
+		d.BadValues = []string{"5xx"}
+	case api.DetectorPresetLinkerdSuccessRate:
+		d.PromQL = fmt.Sprintf(`sum(increase(response_total{direction="inbound",namespace="%s",deployment="%s"}[%s])) by (classification,pod)`, namespace, preset.Workload, preset.Window)
+		d.Key = "classification"
+		d.PodNameKey = "pod"
+		d.GoodValues = []string{"success"}
+	case api.DetectorPresetNginxUpstream5xx:
+		// the vts upstreams are named <namespace>-<service>-<port>, the servers are the pod ip:port
+		upstream := strconv.Quote(regexp.QuoteMeta(namespace+"-"+preset.Workload) + "-[0-9]+")
+		d.PromQL = fmt.Sprintf(`sum(increase(nginx_upstream_responses_total{upstream=~%s}[%s])) by (code,backend)`, upstream, preset.Window)
+		d.Key = "code"
+		d.PodNameKey = "backend"
+		d.BadValues = []string{"5xx"}
+		d.PodMatch = &api.PodMatch{By: api.PodMatchByIP, StripPort: true}
+	default:
+		return nil, fmt.Errorf("unknown preset kind %q", preset.Kind)
+	}
+	return api.DefaultDiscreteValueOutOfList(d), nil
+}
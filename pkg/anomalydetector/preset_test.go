@@ -0,0 +1,123 @@
+package anomalydetector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func Test_presetDiscreteValueOutOfList(t *testing.T) {
+	tests := []struct {
+		name           string
+		preset         api.DetectorPreset
+		wantPromQL     string
+		wantKey        string
+		wantPodNameKey string
+		wantErr        bool
+	}{
+		{
+			name:           "istio",
+			preset:         api.DetectorPreset{Kind: api.DetectorPresetIstioHTTP5xx, Workload: "foo"},
+			wantPromQL:     `label_replace(sum(increase(istio_requests_total{reporter="destination",destination_workload_namespace="test-ns",destination_workload="foo"}[1m])) by (response_code,pod), "response_class", "${1}xx", "response_code", "(.).*")`,
+			wantKey:        "response_class",
+			wantPodNameKey: "pod",
+		},
+		{
+			name:           "linkerd other namespace",
+			preset:         api.DetectorPreset{Kind: api.DetectorPresetLinkerdSuccessRate, Namespace: "other", Workload: "foo", Window: "5m"},
+			wantPromQL:     `sum(increase(response_total{direction="inbound",namespace="other",deployment="foo"}[5m])) by (classification,pod)`,
+			wantKey:        "classification",
+			wantPodNameKey: "pod",
+		},
+		{
+			name:           "nginx",
+			preset:         api.DetectorPreset{Kind: api.DetectorPresetNginxUpstream5xx, Workload: "foo"},
+			wantPromQL:     `sum(increase(nginx_upstream_responses_total{upstream=~"test-ns-foo-[0-9]+"}[1m])) by (code,backend)`,
+			wantKey:        "code",
+			wantPodNameKey: "backend",
+		},
+		{
+			name:           "nginx regex metacharacters",
+			preset:         api.DetectorPreset{Kind: api.DetectorPresetNginxUpstream5xx, Workload: "foo.bar"},
+			wantPromQL:     `sum(increase(nginx_upstream_responses_total{upstream=~"test-ns-foo\\.bar-[0-9]+"}[1m])) by (code,backend)`,
+			wantKey:        "code",
+			wantPodNameKey: "backend",
+		},
+		{
+			name:    "unknown kind",
+			preset:  api.DetectorPreset{Kind: "haproxy", Workload: "foo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preset := api.DefaultDetectorPreset(&tt.preset)
+			preset.PrometheusService = "prometheus:9090"
+			got, err := presetDiscreteValueOutOfList(*preset, "test-ns")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("presetDiscreteValueOutOfList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := api.ValidateDiscreteValueOutOfList(*got); err != nil {
+				t.Errorf("the generated DiscreteValueOutOfList is invalid: %v", err)
+			}
+			if got.PromQL != tt.wantPromQL {
+				t.Errorf("PromQL = %s, want %s", got.PromQL, tt.wantPromQL)
+			}
+			if got.Key != tt.wantKey || got.PodNameKey != tt.wantPodNameKey {
+				t.Errorf("Key, PodNameKey = %s, %s, want %s, %s", got.Key, got.PodNameKey, tt.wantKey, tt.wantPodNameKey)
+			}
+		})
+	}
+}
+
+func TestPresetAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+
+	podA := podGenWithIP("A", "10.0.0.1", map[string]string{"app": "foo"})
+	podB := podGenWithIP("B", "10.0.0.2", map[string]string{"app": "foo"})
+	sample := func(code, backend string, value float64) *model.Sample {
+		return &model.Sample{
+			Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{"code": model.LabelValue(code), "backend": model.LabelValue(backend)})),
+			Value:  model.SampleValue(value),
+		}
+	}
+	vector := model.Vector{
+		sample("2xx", "10.0.0.1:8080", 100),
+		sample("5xx", "10.0.0.1:8080", 1),
+		sample("2xx", "10.0.0.2:8080", 10),
+		sample("5xx", "10.0.0.2:8080", 90),
+	}
+
+	preset := api.DefaultDetectorPreset(&api.DetectorPreset{Kind: api.DetectorPresetNginxUpstream5xx, PrometheusService: "prometheus:9090", Workload: "foo"})
+	podLister := test.NewTestPodNamespaceLister([]*kapiv1.Pod{podA, podB}, "test-ns")
+	a, err := newPresetAnalyser(Config{
+		Namespace:             "test-ns",
+		BreakerStrategyConfig: api.BreakerStrategy{Preset: preset},
+		Selector:              labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		PodLister:             podLister,
+		PodIndexer:            testPodIndexer(podLister),
+		Logger:                devlogger,
+	})
+	if err != nil {
+		t.Fatalf("newPresetAnalyser() error = %v", err)
+	}
+	a.analyser.(*promDiscreteValueOutOfListAnalyser).queyrAPI = &testPrometheusAPI{value: vector}
+
+	got, err := a.GetPodsOutOfBounds()
+	if err != nil {
+		t.Fatalf("GetPodsOutOfBounds() error = %v", err)
+	}
+	if want := []*kapiv1.Pod{podB}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPodsOutOfBounds() = %v, want %v", podNames(got), podNames(want))
+	}
+}
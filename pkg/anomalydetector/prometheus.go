@@ -3,11 +3,14 @@ package anomalydetector
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	promApi "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
 
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
 )
@@ -17,6 +20,7 @@ type promDiscreteValueOutOfListAnalyser struct {
 	queyrAPI         promApi.API
 	logger           *zap.Logger
 	valueCheckerFunc func(value string) (ok bool)
+	rules            *discreteValueRules
 }
 
 func (p *promDiscreteValueOutOfListAnalyser) doAnalysis() (okkoByPodName, error) {
@@ -47,12 +51,7 @@ func (p *promDiscreteValueOutOfListAnalyser) buildCounters(vector model.Vector)
 		podName := string(metrics[model.LabelName(p.config.PodNameKey)])
 		counters := countersByPods[podName]
 
-		discreteValue := metrics[model.LabelName(p.config.Key)]
-		if p.valueCheckerFunc(string(discreteValue)) {
-			counters.ok += uint(sample.Value)
-		} else {
-			counters.ko += uint(sample.Value)
-		}
+		counters.add(classifySample(metrics, p.config.Key, p.valueCheckerFunc, p.rules), sample.Value)
 		countersByPods[podName] = counters
 	}
 	return countersByPods
@@ -87,5 +86,182 @@ func (p *promContinuousValueDeviationAnalyser) doAnalysis() (deviationByPodName,
 		deviation := sample.Value
 		result[podName] = float64(deviation)
 	}
+	if p.config.Baseline == nil || len(result) == 0 {
+		return result, nil
+	}
+
+	// baseline mode: the query returns the value of each pod, the deviation is computed against the history of the fleet
+	baseline, err := p.baseline(ctx, tsNow)
+	if err != nil {
+		return nil, err
+	}
+	if baseline == 0 {
+		return nil, fmt.Errorf("the baseline of the fleet is 0, can't compute the deviation")
+	}
+	for podName, value := range result {
+		result[podName] = value / baseline
+	}
+	return result, nil
+}
+
+// baseline returns the average of the fleet Offset ago, or the median of this average over the MedianWindow
+func (p *promContinuousValueDeviationAnalyser) baseline(ctx context.Context, tsNow time.Time) (float64, error) {
+	var offset model.Duration
+	if p.config.Baseline.Offset != "" {
+		var err error
+		if offset, err = model.ParseDuration(p.config.Baseline.Offset); err != nil {
+			return 0, err
+		}
+	}
+	end := tsNow.Add(-time.Duration(offset))
+
+	if p.config.Baseline.MedianWindow == "" {
+		m, err := p.queryAPI.Query(ctx, p.config.PromQL, end)
+		if err != nil {
+			return 0, fmt.Errorf("error processing prometheus baseline query: %s", err)
+		}
+		vector, ok := m.(model.Vector)
+		if !ok {
+			return 0, fmt.Errorf("the prometheus baseline query did not return a result in the form of expected type 'model.Vector'")
+		}
+		if len(vector) == 0 {
+			return 0, fmt.Errorf("no baseline value %s ago", p.config.Baseline.Offset)
+		}
+		sum := 0.0
+		for _, sample := range vector {
+			sum += float64(sample.Value)
+		}
+		return sum / float64(len(vector)), nil
+	}
+
+	window, err := model.ParseDuration(p.config.Baseline.MedianWindow)
+	if err != nil {
+		return 0, err
+	}
+	step, err := model.ParseDuration(p.config.Baseline.MedianStep)
+	if err != nil {
+		return 0, err
+	}
+	m, err := p.queryAPI.QueryRange(ctx, p.config.PromQL, promApi.Range{Start: end.Add(-time.Duration(window)), End: end, Step: time.Duration(step)})
+	if err != nil {
+		return 0, fmt.Errorf("error processing prometheus baseline query: %s", err)
+	}
+	matrix, ok := m.(model.Matrix)
+	if !ok {
+		return 0, fmt.Errorf("the prometheus baseline query did not return a result in the form of expected type 'model.Matrix'")
+	}
+	// average of the fleet at each step, then median over the window
+	type sumCount struct {
+		sum   float64
+		count int
+	}
+	fleetByTime := map[model.Time]sumCount{}
+	for _, stream := range matrix {
+		for _, pair := range stream.Values {
+			fleet := fleetByTime[pair.Timestamp]
+			fleet.sum += float64(pair.Value)
+			fleet.count++
+			fleetByTime[pair.Timestamp] = fleet
+		}
+	}
+	if len(fleetByTime) == 0 {
+		return 0, fmt.Errorf("no baseline value over %s", p.config.Baseline.MedianWindow)
+	}
+	averages := make([]float64, 0, len(fleetByTime))
+	for _, fleet := range fleetByTime {
+		averages = append(averages, fleet.sum/float64(fleet.count))
+	}
+	sort.Float64s(averages)
+	return percentile(averages, 0.5), nil
+}
+
+type promLatencyPercentileAnalyser struct {
+	config   api.LatencyPercentile
+	queryAPI promApi.API
+	logger   *zap.Logger
+}
+
+// buildQueries returns the per pod and the fleet histogram_quantile promQL
+func (p *promLatencyPercentileAnalyser) buildQueries() (podQuery, fleetQuery string) {
+	rate := fmt.Sprintf("rate(%s_bucket{%s}[%s])", p.config.HistogramMetric, p.config.LabelFilter, p.config.Window)
+	podQuery = fmt.Sprintf("histogram_quantile(%g, sum(%s) by (le,%s))", *p.config.Quantile, rate, p.config.PodNameKey)
+	fleetQuery = fmt.Sprintf("histogram_quantile(%g, sum(%s) by (le))", *p.config.Quantile, rate)
+	return podQuery, fleetQuery
+}
+
+func (p *promLatencyPercentileAnalyser) doAnalysis() (latencyByPodName, float64, error) {
+	ctx := context.Background()
+	tsNow := time.Now()
+	podQuery, fleetQuery := p.buildQueries()
+
+	m, err := p.queryAPI.Query(ctx, podQuery, tsNow)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error processing prometheus query: %s", err)
+	}
+	vector, ok := m.(model.Vector)
+	if !ok {
+		return nil, 0, fmt.Errorf("the prometheus query did not return a result in the form of expected type 'model.Vector': %s", err)
+	}
+	result := latencyByPodName{}
+	for _, sample := range vector {
+		podName := string(sample.Metric[model.LabelName(p.config.PodNameKey)])
+		result[podName] = float64(sample.Value)
+	}
+
+	if p.config.MaxLatency != nil {
+		// fixed SLO, no need to query the fleet
+		return result, math.NaN(), nil
+	}
+
+	m, err = p.queryAPI.Query(ctx, fleetQuery, tsNow)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error processing prometheus query: %s", err)
+	}
+	vector, ok = m.(model.Vector)
+	if !ok {
+		return nil, 0, fmt.Errorf("the prometheus query did not return a result in the form of expected type 'model.Vector': %s", err)
+	}
+	switch len(vector) {
+	case 0:
+		// no traffic on the fleet during the window: no reference to compare the pods to
+		return result, math.NaN(), nil
+	case 1:
+		return result, float64(vector[0].Value), nil
+	default:
+		return nil, 0, fmt.Errorf("the fleet percentile query returned %d samples instead of 1", len(vector))
+	}
+}
+
+type promResourceUsageSource struct {
+	config    api.ResourceUsage
+	namespace string
+	queryAPI  promApi.API
+}
+
+// buildQuery returns the promQL of the usage by pod from the cAdvisor metrics
+func (p *promResourceUsageSource) buildQuery() string {
+	filter := fmt.Sprintf(`namespace="%s",container!="",container!="POD"`, p.namespace)
+	if p.config.LabelFilter != "" {
+		filter += "," + p.config.LabelFilter
+	}
+	if p.config.Resource == kapiv1.ResourceCPU {
+		return fmt.Sprintf("sum(rate(container_cpu_usage_seconds_total{%s}[1m])) by (%s)", filter, p.config.PodNameKey)
+	}
+	return fmt.Sprintf("sum(container_memory_working_set_bytes{%s}) by (%s)", filter, p.config.PodNameKey)
+}
+
+func (p *promResourceUsageSource) usage() (usageByPodName, error) {
+	m, err := p.queryAPI.Query(context.Background(), p.buildQuery(), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error processing prometheus query: %s", err)
+	}
+	vector, ok := m.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("the prometheus query did not return a result in the form of expected type 'model.Vector': %s", err)
+	}
+	result := usageByPodName{}
+	for _, sample := range vector {
+		result[string(sample.Metric[model.LabelName(p.config.PodNameKey)])] = float64(sample.Value)
+	}
 	return result, nil
 }
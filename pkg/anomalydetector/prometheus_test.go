@@ -3,6 +3,7 @@ package anomalydetector
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -327,3 +328,220 @@ func Test_promContinuousValueDeviationAnalyser_doAnalysis(t *testing.T) {
 		})
 	}
 }
+
+// testBaselinePrometheusAPI returns the current value for the instant queries evaluated now, the past value otherwise
+type testBaselinePrometheusAPI struct {
+	testPrometheusAPI
+	current model.Value
+	past    model.Value
+	ranges  []promApi.Range
+}
+
+// Query performs a query for the given time.
+func (tAPI *testBaselinePrometheusAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, error) {
+	if time.Since(ts) < time.Minute {
+		return tAPI.current, nil
+	}
+	return tAPI.past, nil
+}
+
+// QueryRange performs a query for the given range.
+func (tAPI *testBaselinePrometheusAPI) QueryRange(ctx context.Context, query string, r promApi.Range) (model.Value, error) {
+	tAPI.ranges = append(tAPI.ranges, r)
+	return tAPI.past, nil
+}
+
+func Test_promContinuousValueDeviationAnalyser_baseline(t *testing.T) {
+	sample := func(pod string, value float64) *model.Sample {
+		return &model.Sample{
+			Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{"pod": model.LabelValue(pod)})),
+			Value:  model.SampleValue(value),
+		}
+	}
+	stream := func(pod string, values ...float64) *model.SampleStream {
+		s := &model.SampleStream{Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{"pod": model.LabelValue(pod)}))}
+		for i, v := range values {
+			s.Values = append(s.Values, model.SamplePair{Timestamp: model.Time(i * 3600 * 1000), Value: model.SampleValue(v)})
+		}
+		return s
+	}
+	current := model.Vector{sample("podA", 120), sample("podB", 200)}
+
+	tests := []struct {
+		name      string
+		baseline  api.DeviationBaseline
+		past      model.Value
+		want      deviationByPodName
+		wantRange time.Duration
+		wantErr   bool
+	}{
+		{
+			name:     "offset",
+			baseline: api.DeviationBaseline{Offset: "1d"},
+			past:     model.Vector{sample("podX", 90), sample("podY", 110)},
+			want:     deviationByPodName{"podA": 1.2, "podB": 2},
+		},
+		{
+			name:     "offset no history",
+			baseline: api.DeviationBaseline{Offset: "1d"},
+			past:     model.Vector{},
+			wantErr:  true,
+		},
+		{
+			name:     "offset zero baseline",
+			baseline: api.DeviationBaseline{Offset: "1w"},
+			past:     model.Vector{sample("podX", 0)},
+			wantErr:  true,
+		},
+		{
+			name:     "median",
+			baseline: api.DeviationBaseline{MedianWindow: "1w", MedianStep: "1h"},
+			// fleet averages by step: 100, 40, 250
+			past:      model.Matrix{stream("podX", 100, 30, 300), stream("podY", 100, 50, 200)},
+			want:      deviationByPodName{"podA": 1.2, "podB": 2},
+			wantRange: 7 * 24 * time.Hour,
+		},
+		{
+			name:     "median bad type",
+			baseline: api.DeviationBaseline{MedianWindow: "1w", MedianStep: "1h"},
+			past:     model.Vector{sample("podX", 100)},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseline := tt.baseline
+			qAPI := &testBaselinePrometheusAPI{current: current, past: tt.past}
+			p := &promContinuousValueDeviationAnalyser{
+				config:   api.ContinuousValueDeviation{PodNameKey: "pod", PromQL: "foo", Baseline: &baseline},
+				queryAPI: qAPI,
+			}
+			got, err := p.doAnalysis()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("promContinuousValueDeviationAnalyser.doAnalysis() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("promContinuousValueDeviationAnalyser.doAnalysis() = %v, want %v", got, tt.want)
+			}
+			if tt.wantRange != 0 {
+				if len(qAPI.ranges) != 1 || qAPI.ranges[0].End.Sub(qAPI.ranges[0].Start) != tt.wantRange || qAPI.ranges[0].Step != time.Hour {
+					t.Errorf("unexpected range queries %v", qAPI.ranges)
+				}
+			}
+		})
+	}
+}
+
+// testQueryPrometheusAPI returns a value per query
+type testQueryPrometheusAPI struct {
+	testPrometheusAPI
+	values map[string]model.Value
+}
+
+// Query performs a query for the given time.
+func (tAPI *testQueryPrometheusAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, error) {
+	if tAPI.err != nil {
+		return nil, tAPI.err
+	}
+	return tAPI.values[query], nil
+}
+
+func Test_promLatencyPercentileAnalyser_doAnalysis(t *testing.T) {
+	podQuery := `histogram_quantile(0.99, sum(rate(rpc_duration_seconds_bucket{app="foo"}[1m])) by (le,pod))`
+	fleetQuery := `histogram_quantile(0.99, sum(rate(rpc_duration_seconds_bucket{app="foo"}[1m])) by (le))`
+	podVector := model.Vector([]*model.Sample{
+		{
+			Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{"pod": "podA"})),
+			Value:  model.SampleValue(0.2),
+		},
+	})
+	fleetVector := model.Vector([]*model.Sample{
+		{
+			Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{})),
+			Value:  model.SampleValue(0.1),
+		},
+	})
+	config := api.LatencyPercentile{
+		HistogramMetric:     "rpc_duration_seconds",
+		LabelFilter:         `app="foo"`,
+		Quantile:            api.NewFloat64(0.99),
+		Window:              "1m",
+		PodNameKey:          "pod",
+		MaxDeviationPercent: api.NewFloat64(50),
+	}
+	sloConfig := *config.DeepCopy()
+	sloConfig.MaxDeviationPercent = nil
+	sloConfig.MaxLatency = api.NewFloat64(0.15)
+
+	tests := []struct {
+		name      string
+		config    api.LatencyPercentile
+		qAPI      promApi.API
+		want      latencyByPodName
+		wantFleet float64
+		wantErr   bool
+	}{
+		{
+			name:    "caseErrorQuery",
+			config:  config,
+			qAPI:    &testQueryPrometheusAPI{testPrometheusAPI: testPrometheusAPI{err: fmt.Errorf("A prom Error")}},
+			wantErr: true,
+		},
+		{
+			name:      "fleet",
+			config:    config,
+			qAPI:      &testQueryPrometheusAPI{values: map[string]model.Value{podQuery: podVector, fleetQuery: fleetVector}},
+			want:      latencyByPodName{"podA": 0.2},
+			wantFleet: 0.1,
+		},
+		{
+			name:      "fleet empty",
+			config:    config,
+			qAPI:      &testQueryPrometheusAPI{values: map[string]model.Value{podQuery: podVector, fleetQuery: model.Vector{}}},
+			want:      latencyByPodName{"podA": 0.2},
+			wantFleet: math.NaN(),
+		},
+		{
+			name:    "fleet several samples",
+			config:  config,
+			qAPI:    &testQueryPrometheusAPI{values: map[string]model.Value{podQuery: podVector, fleetQuery: append(fleetVector, fleetVector...)}},
+			wantErr: true,
+		},
+		{
+			name:      "slo",
+			config:    sloConfig,
+			qAPI:      &testQueryPrometheusAPI{values: map[string]model.Value{podQuery: podVector}},
+			want:      latencyByPodName{"podA": 0.2},
+			wantFleet: math.NaN(),
+		},
+		{
+			name:    "badCast",
+			config:  config,
+			qAPI:    &testQueryPrometheusAPI{values: map[string]model.Value{}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &promLatencyPercentileAnalyser{
+				config:   tt.config,
+				queryAPI: tt.qAPI,
+			}
+			got, fleet, err := p.doAnalysis()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("promLatencyPercentileAnalyser.doAnalysis() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("promLatencyPercentileAnalyser.doAnalysis() = %v, want %v", got, tt.want)
+			}
+			if fleet != tt.wantFleet && !(math.IsNaN(fleet) && math.IsNaN(tt.wantFleet)) {
+				t.Errorf("promLatencyPercentileAnalyser.doAnalysis() fleet = %v, want %v", fleet, tt.wantFleet)
+			}
+		})
+	}
+}
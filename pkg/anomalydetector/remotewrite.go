@@ -0,0 +1,59 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
+)
+
+var _ counterSource = &remoteWriteCounterSource{}
+
+// remoteWriteCounterSource reads the counters increase in the store fed by the remote-write endpoint
+type remoteWriteCounterSource struct {
+	store     *remotewrite.Store
+	window    time.Duration
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	now       func() time.Time
+}
+
+func newRemoteWriteCounterSource(config api.RemoteWriteSource, cfg Config) (*remoteWriteCounterSource, error) {
+	if cfg.RemoteWriteStore == nil {
+		return nil, fmt.Errorf("the remote write endpoint is not enabled, see the --remote-write flag of the controller")
+	}
+	config = *api.DefaultRemoteWriteSource(&config)
+	window, err := model.ParseDuration(config.Window)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write window: %v", err)
+	}
+	return &remoteWriteCounterSource{
+		store:     cfg.RemoteWriteStore,
+		window:    time.Duration(window),
+		selector:  cfg.Selector,
+		podLister: cfg.PodLister,
+		now:       time.Now,
+	}, nil
+}
+
+func (r *remoteWriteCounterSource) deltas(names ...string) (map[string]model.Vector, error) {
+	listOfPods, err := r.podLister.List(r.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+	podNames := make([]string, 0, len(listOfPods))
+	for _, p := range listOfPods {
+		podNames = append(podNames, p.Name)
+	}
+	return r.store.Increases(podNames, names, r.now().Add(-r.window)), nil
+}
@@ -0,0 +1,73 @@
+package anomalydetector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func remoteWriteSeries(pod, code string, start time.Time, values ...float64) *remotewrite.TimeSeries {
+	ts := &remotewrite.TimeSeries{Labels: []*remotewrite.Label{
+		{Name: "__name__", Value: "rpc_count"},
+		{Name: "code", Value: code},
+		{Name: "kubernetes_pod_name", Value: pod},
+	}}
+	for i, v := range values {
+		ts.Samples = append(ts.Samples, &remotewrite.Sample{Value: v, Timestamp: int64(model.TimeFromUnixNano(start.Add(time.Duration(i) * 10 * time.Second).UnixNano()))})
+	}
+	return ts
+}
+
+func Test_remoteWriteCounterSource(t *testing.T) {
+	store := remotewrite.NewStore(remotewrite.Config{PodLabels: []string{"kubernetes_pod_name"}, MaxSeries: 100, Retention: 10 * time.Minute})
+	t0 := time.Now().Add(-30 * time.Second)
+	store.Append(&remotewrite.WriteRequest{Timeseries: []*remotewrite.TimeSeries{
+		remoteWriteSeries("A", "200", t0, 10, 20, 30),
+		remoteWriteSeries("A", "500", t0, 0, 0, 1),
+		remoteWriteSeries("B", "200", t0, 10, 12, 14),
+		remoteWriteSeries("B", "500", t0, 0, 6, 12),
+		remoteWriteSeries("C", "500", t0, 0, 6, 12), // not ready
+		remoteWriteSeries("D", "500", t0, 0, 6, 12), // not part of the breaker
+	}})
+
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, false, labeling.LabelTrafficYes),
+	}
+
+	if _, err := newRemoteWriteCounterSource(api.RemoteWriteSource{Metric: "rpc_count"}, Config{}); err == nil {
+		t.Errorf("newRemoteWriteCounterSource() without store should fail")
+	}
+	source, err := newRemoteWriteCounterSource(api.RemoteWriteSource{Metric: "rpc_count"}, Config{
+		Selector:         labels.Everything(),
+		PodLister:        test.NewTestPodNamespaceLister(pods, "test-ns"),
+		RemoteWriteStore: store,
+	})
+	if err != nil {
+		t.Fatalf("newRemoteWriteCounterSource() unexpected error: %v", err)
+	}
+
+	a := &counterDiscreteValueOutOfListAnalyser{
+		config:           api.DiscreteValueOutOfList{Key: "code"},
+		metric:           "rpc_count",
+		source:           source,
+		valueCheckerFunc: func(value string) bool { return ContainsString([]string{"200"}, value) },
+	}
+	got, err := a.doAnalysis()
+	if err != nil {
+		t.Fatalf("counterDiscreteValueOutOfListAnalyser.doAnalysis() unexpected error: %v", err)
+	}
+	want := okkoByPodName{"A": {20, 1}, "B": {4, 12}}
+	if len(got) != len(want) || got["A"] != want["A"] || got["B"] != want["B"] {
+		t.Errorf("counterDiscreteValueOutOfListAnalyser.doAnalysis() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package anomalydetector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+var _ AnomalyDetector = &ResourceUsageAnalyser{}
+
+// usageByPodName usage of the resource by pod name: cores for cpu, bytes for memory
+type usageByPodName map[string]float64
+
+type resourceUsageSource interface {
+	usage() (usageByPodName, error)
+}
+
+//ResourceUsageAnalyser anomalyDetector that compares the cpu or memory usage of each pod to the fleet median and to the pod limits
+type ResourceUsageAnalyser struct {
+	api.ResourceUsage
+	selector  labels.Selector
+	podLister kv1.PodNamespaceLister
+	source    resourceUsageSource
+	logger    *zap.Logger
+
+	// overSince is only accessed by the breaker goroutine that calls GetPodsOutOfBounds
+	overSince map[string]time.Time
+	now       func() time.Time
+}
+
+//GetPodsOutOfBounds implements interface AnomalyDetector
+func (d *ResourceUsageAnalyser) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	listOfPods, err := d.podLister.List(d.selector)
+	if err != nil {
+		return nil, fmt.Errorf("can't list pods, error:%v", err)
+	}
+	listOfPods, err = pod.PurgeNotReadyPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't purge not ready pods, error:%v", err)
+	}
+	listOfPods, err = pod.KeepWithTrafficYesPods(listOfPods)
+	if err != nil {
+		return nil, fmt.Errorf("can't keep pods with traffic, error:%v", err)
+	}
+
+	usageByPod, err := d.source.usage()
+	if err != nil {
+		return nil, err
+	}
+
+	fleet := []float64{}
+	for _, p := range listOfPods {
+		if value, ok := usageByPod[p.Name]; ok {
+			fleet = append(fleet, value)
+		}
+	}
+	result := []*kapiv1.Pod{}
+	if len(fleet) == 0 {
+		d.overSince = map[string]time.Time{}
+		return result, nil
+	}
+	sort.Float64s(fleet)
+	median := percentile(fleet, 0.5)
+
+	now := d.now()
+	sustained := time.Duration(*d.SustainedPeriod*1000) * time.Millisecond
+	overSince := map[string]time.Time{}
+	for _, p := range listOfPods {
+		value, ok := usageByPod[p.Name]
+		if !ok {
+			continue
+		}
+		reason := d.overThreshold(p, value, median)
+		if reason == "" {
+			continue
+		}
+		since, ok := d.overSince[p.Name]
+		if !ok {
+			since = now
+		}
+		overSince[p.Name] = since
+		if now.Sub(since) >= sustained {
+			d.logger.Sugar().Infof("the pod %s is out of bounds: %s since %s", p.Name, reason, now.Sub(since))
+			result = append(result, p)
+		}
+	}
+	d.overSince = overSince
+	return result, nil
+}
+
+// overThreshold returns why the usage of the pod is above a threshold, empty if it is not
+func (d *ResourceUsageAnalyser) overThreshold(p *kapiv1.Pod, value, median float64) string {
+	if d.MaxFleetRatio != nil && median > 0 && value > *d.MaxFleetRatio*median {
+		return fmt.Sprintf("%s usage %g is more than %g times the fleet median %g", d.Resource, value, *d.MaxFleetRatio, median)
+	}
+	if d.MaxLimitPercent != nil {
+		if limit := podLimit(p, d.Resource); limit > 0 && value*100 > float64(*d.MaxLimitPercent)*limit {
+			return fmt.Sprintf("%s usage %g is more than %d%% of the limit %g", d.Resource, value, *d.MaxLimitPercent, limit)
+		}
+	}
+	return ""
+}
+
+// podLimit returns the sum of the containers limits for the resource, 0 if a container has no limit
+func podLimit(p *kapiv1.Pod, resourceName kapiv1.ResourceName) float64 {
+	sum := 0.0
+	for _, c := range p.Spec.Containers {
+		limit, ok := c.Resources.Limits[resourceName]
+		if !ok {
+			return 0
+		}
+		sum += quantityValue(limit, resourceName)
+	}
+	return sum
+}
+
+// quantityValue converts a quantity to cores for cpu, to bytes for memory
+func quantityValue(q resource.Quantity, resourceName kapiv1.ResourceName) float64 {
+	if resourceName == kapiv1.ResourceCPU {
+		return float64(q.MilliValue()) / 1000
+	}
+	return float64(q.Value())
+}
+
+// metricsAPIPodMetricsList is the part of the metrics.k8s.io/v1beta1 PodMetricsList used by the analyser
+type metricsAPIPodMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Name  string              `json:"name"`
+			Usage kapiv1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+var _ resourceUsageSource = &metricsAPIUsageSource{}
+
+// metricsAPIUsageSource reads the usage of the pods from the metrics API
+type metricsAPIUsageSource struct {
+	resourceName kapiv1.ResourceName
+	namespace    string
+	selector     labels.Selector
+	// get returns the body of the GET request on the metrics API path
+	get func(path string, labelSelector string) ([]byte, error)
+}
+
+func (s *metricsAPIUsageSource) usage() (usageByPodName, error) {
+	body, err := s.get(fmt.Sprintf("/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods", s.namespace), s.selector.String())
+	if err != nil {
+		return nil, fmt.Errorf("can't get the pod metrics, error:%v", err)
+	}
+	list := metricsAPIPodMetricsList{}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("can't decode the pod metrics, error:%v", err)
+	}
+	result := usageByPodName{}
+	for _, item := range list.Items {
+		sum := 0.0
+		for _, c := range item.Containers {
+			if q, ok := c.Usage[s.resourceName]; ok {
+				sum += quantityValue(q, s.resourceName)
+			}
+		}
+		result[item.Metadata.Name] = sum
+	}
+	return result, nil
+}
+
+// httpMetricsAPIGet returns a get function for the metrics API served by the given host:port
+func httpMetricsAPIGet(service string) func(path string, labelSelector string) ([]byte, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return func(path string, labelSelector string) ([]byte, error) {
+		resp, err := client.Get(fmt.Sprintf("http://%s%s?labelSelector=%s", service, path, url.QueryEscape(labelSelector)))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+		}
+		return body, nil
+	}
+}
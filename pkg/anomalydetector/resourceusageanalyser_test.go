@@ -0,0 +1,203 @@
+package anomalydetector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func cpuUsage(value string) kapiv1.ResourceList {
+	return kapiv1.ResourceList{kapiv1.ResourceCPU: resource.MustParse(value)}
+}
+
+func TestResourceUsageAnalyser_GetPodsOutOfBounds(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+
+	server := test.NewTestMetricsServer()
+	defer server.Close()
+	for _, name := range []string{"A", "B", "C", "N"} {
+		server.SetPodUsage("test-ns", name, cpuUsage("100m"))
+	}
+	// D spins, N is out of traffic with a high usage that must not move the fleet median
+	server.SetPodUsage("test-ns", "D", cpuUsage("500m"))
+	server.SetPodUsage("test-ns", "N", cpuUsage("2"))
+	server.SetPodUsage("other-ns", "A", cpuUsage("4"))
+
+	pods := []*kapiv1.Pod{}
+	for _, name := range []string{"A", "B", "C", "D"} {
+		pods = append(pods, test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes))
+	}
+	pods = append(pods, test.PodGen("N", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficNo))
+
+	now := time.Now()
+	d := &ResourceUsageAnalyser{
+		ResourceUsage: *api.DefaultResourceUsage(&api.ResourceUsage{Resource: kapiv1.ResourceCPU, MetricsAPIService: server.Host(), SustainedPeriod: api.NewFloat64(60)}),
+		selector:      labels.Everything(),
+		podLister:     test.NewTestPodNamespaceLister(pods, "test-ns"),
+		source:        &metricsAPIUsageSource{resourceName: kapiv1.ResourceCPU, namespace: "test-ns", selector: labels.Everything(), get: httpMetricsAPIGet(server.Host())},
+		logger:        devlogger,
+		overSince:     map[string]time.Time{},
+		now:           func() time.Time { return now },
+	}
+
+	evaluate := func(step string, want []string) {
+		got, err := d.GetPodsOutOfBounds()
+		if err != nil {
+			t.Fatalf("%s: GetPodsOutOfBounds() error = %v", step, err)
+		}
+		if !reflect.DeepEqual(podNames(got), want) {
+			t.Errorf("%s: GetPodsOutOfBounds() = %v, want %v", step, podNames(got), want)
+		}
+	}
+
+	evaluate("first detection", []string{})
+	now = now.Add(30 * time.Second)
+	evaluate("not sustained", []string{})
+	now = now.Add(30 * time.Second)
+	evaluate("sustained", []string{"D"})
+
+	// the usage goes back to normal: the sustained period starts again
+	server.SetPodUsage("test-ns", "D", cpuUsage("150m"))
+	now = now.Add(10 * time.Second)
+	evaluate("back to normal", []string{})
+	server.SetPodUsage("test-ns", "D", cpuUsage("500m"))
+	now = now.Add(10 * time.Second)
+	evaluate("new detection", []string{})
+}
+
+func TestResourceUsageAnalyser_overThreshold(t *testing.T) {
+	withLimits := func(limits ...string) *kapiv1.Pod {
+		p := test.PodGen("A", "test-ns", nil, nil, true, true, labeling.LabelTrafficYes)
+		for i, l := range limits {
+			c := kapiv1.Container{Name: fmt.Sprintf("c%d", i)}
+			if l != "" {
+				c.Resources.Limits = kapiv1.ResourceList{kapiv1.ResourceMemory: resource.MustParse(l)}
+			}
+			p.Spec.Containers = append(p.Spec.Containers, c)
+		}
+		return p
+	}
+	mi := float64(1024 * 1024)
+
+	tests := []struct {
+		name   string
+		config api.ResourceUsage
+		pod    *kapiv1.Pod
+		value  float64
+		median float64
+		want   bool
+	}{
+		{
+			name:   "fleet ratio exceeded",
+			config: api.ResourceUsage{MaxFleetRatio: api.NewFloat64(2)},
+			pod:    withLimits(),
+			value:  250 * mi,
+			median: 100 * mi,
+			want:   true,
+		},
+		{
+			name:   "fleet ratio ok",
+			config: api.ResourceUsage{MaxFleetRatio: api.NewFloat64(2)},
+			pod:    withLimits(),
+			value:  150 * mi,
+			median: 100 * mi,
+			want:   false,
+		},
+		{
+			name:   "limit exceeded",
+			config: api.ResourceUsage{MaxLimitPercent: api.NewUInt(90)},
+			pod:    withLimits("100Mi", "100Mi"),
+			value:  190 * mi,
+			median: 190 * mi,
+			want:   true,
+		},
+		{
+			name:   "limit ok",
+			config: api.ResourceUsage{MaxLimitPercent: api.NewUInt(90)},
+			pod:    withLimits("100Mi", "100Mi"),
+			value:  170 * mi,
+			median: 170 * mi,
+			want:   false,
+		},
+		{
+			name:   "container without limit",
+			config: api.ResourceUsage{MaxLimitPercent: api.NewUInt(90)},
+			pod:    withLimits("100Mi", ""),
+			value:  190 * mi,
+			median: 190 * mi,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Resource = kapiv1.ResourceMemory
+			d := &ResourceUsageAnalyser{ResourceUsage: config}
+			if got := d.overThreshold(tt.pod, tt.value, tt.median); (got != "") != tt.want {
+				t.Errorf("ResourceUsageAnalyser.overThreshold() = %q, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_promResourceUsageSource_usage(t *testing.T) {
+	cpuQuery := `sum(rate(container_cpu_usage_seconds_total{namespace="test-ns",container!="",container!="POD",container="app"}[1m])) by (pod)`
+	memoryQuery := `sum(container_memory_working_set_bytes{namespace="test-ns",container!="",container!="POD"}) by (pod)`
+	vector := model.Vector([]*model.Sample{
+		{
+			Metric: model.Metric(model.LabelSet(map[model.LabelName]model.LabelValue{"pod": "podA"})),
+			Value:  model.SampleValue(0.5),
+		},
+	})
+
+	tests := []struct {
+		name    string
+		config  api.ResourceUsage
+		want    usageByPodName
+		wantErr bool
+	}{
+		{
+			name:   "cpu",
+			config: api.ResourceUsage{Resource: kapiv1.ResourceCPU, LabelFilter: `container="app"`, PodNameKey: "pod"},
+			want:   usageByPodName{"podA": 0.5},
+		},
+		{
+			name:   "memory",
+			config: api.ResourceUsage{Resource: kapiv1.ResourceMemory, PodNameKey: "pod"},
+			want:   usageByPodName{"podA": 0.5},
+		},
+		{
+			name:    "unexpected query",
+			config:  api.ResourceUsage{Resource: kapiv1.ResourceMemory, PodNameKey: "pod_name"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &promResourceUsageSource{
+				config:    tt.config,
+				namespace: "test-ns",
+				queryAPI:  &testQueryPrometheusAPI{values: map[string]model.Value{cpuQuery: vector, memoryQuery: vector}},
+			}
+			got, err := p.usage()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("promResourceUsageSource.usage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("promResourceUsageSource.usage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
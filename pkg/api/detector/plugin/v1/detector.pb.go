@@ -0,0 +1,304 @@
+// Code generated by protoc-gen-go.
+// source: detector.proto
+// DO NOT EDIT!
+
+/*
+Package v1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	detector.proto
+
+It has these top-level messages:
+
+	EvaluateRequest
+	Pod
+	PodVerdict
+	EvaluateResponse
+*/
+package v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Verdict int32
+
+const (
+	Verdict_VERDICT_UNKNOWN       Verdict = 0
+	Verdict_VERDICT_OK            Verdict = 1
+	Verdict_VERDICT_OUT_OF_BOUNDS Verdict = 2
+)
+
+var Verdict_name = map[int32]string{
+	0: "VERDICT_UNKNOWN",
+	1: "VERDICT_OK",
+	2: "VERDICT_OUT_OF_BOUNDS",
+}
+var Verdict_value = map[string]int32{
+	"VERDICT_UNKNOWN":       0,
+	"VERDICT_OK":            1,
+	"VERDICT_OUT_OF_BOUNDS": 2,
+}
+
+func (x Verdict) String() string {
+	return proto.EnumName(Verdict_name, int32(x))
+}
+func (Verdict) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type EvaluateRequest struct {
+	Namespace         string `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	KubervisorService string `protobuf:"bytes,2,opt,name=kubervisor_service,json=kubervisorService" json:"kubervisor_service,omitempty"`
+	Strategy          string `protobuf:"bytes,3,opt,name=strategy" json:"strategy,omitempty"`
+	// Pods managed by the KubervisorService that receive traffic
+	Pods []*Pod `protobuf:"bytes,4,rep,name=pods" json:"pods,omitempty"`
+}
+
+func (m *EvaluateRequest) Reset()                    { *m = EvaluateRequest{} }
+func (m *EvaluateRequest) String() string            { return proto.CompactTextString(m) }
+func (*EvaluateRequest) ProtoMessage()               {}
+func (*EvaluateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *EvaluateRequest) GetPods() []*Pod {
+	if m != nil {
+		return m.Pods
+	}
+	return nil
+}
+
+type Pod struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Ip   string `protobuf:"bytes,2,opt,name=ip" json:"ip,omitempty"`
+}
+
+func (m *Pod) Reset()                    { *m = Pod{} }
+func (m *Pod) String() string            { return proto.CompactTextString(m) }
+func (*Pod) ProtoMessage()               {}
+func (*Pod) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type PodVerdict struct {
+	Name    string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Verdict Verdict `protobuf:"varint,2,opt,name=verdict,enum=kubervisor.detector.plugin.v1.Verdict" json:"verdict,omitempty"`
+	// Anomaly score, for information
+	Score float64 `protobuf:"fixed64,3,opt,name=score" json:"score,omitempty"`
+	// Human readable explanation of the verdict
+	Reason string `protobuf:"bytes,4,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *PodVerdict) Reset()                    { *m = PodVerdict{} }
+func (m *PodVerdict) String() string            { return proto.CompactTextString(m) }
+func (*PodVerdict) ProtoMessage()               {}
+func (*PodVerdict) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type EvaluateResponse struct {
+	// Pods without verdict are considered ok
+	Pods []*PodVerdict `protobuf:"bytes,1,rep,name=pods" json:"pods,omitempty"`
+}
+
+func (m *EvaluateResponse) Reset()                    { *m = EvaluateResponse{} }
+func (m *EvaluateResponse) String() string            { return proto.CompactTextString(m) }
+func (*EvaluateResponse) ProtoMessage()               {}
+func (*EvaluateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *EvaluateResponse) GetPods() []*PodVerdict {
+	if m != nil {
+		return m.Pods
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*EvaluateRequest)(nil), "kubervisor.detector.plugin.v1.EvaluateRequest")
+	proto.RegisterType((*Pod)(nil), "kubervisor.detector.plugin.v1.Pod")
+	proto.RegisterType((*PodVerdict)(nil), "kubervisor.detector.plugin.v1.PodVerdict")
+	proto.RegisterType((*EvaluateResponse)(nil), "kubervisor.detector.plugin.v1.EvaluateResponse")
+	proto.RegisterEnum("kubervisor.detector.plugin.v1.Verdict", Verdict_name, Verdict_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for AnomalyDetector service
+
+type AnomalyDetectorClient interface {
+	// Evaluate returns the verdicts of the pods. It is called at each evaluation of the breaker
+	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
+	// Watch sends the verdicts of all the pods each time they change, the first message is sent immediately.
+	// The call is restarted by kubervisor when the managed pods change
+	Watch(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (AnomalyDetector_WatchClient, error)
+}
+
+type anomalyDetectorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAnomalyDetectorClient(cc *grpc.ClientConn) AnomalyDetectorClient {
+	return &anomalyDetectorClient{cc}
+}
+
+func (c *anomalyDetectorClient) Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error) {
+	out := new(EvaluateResponse)
+	err := grpc.Invoke(ctx, "/kubervisor.detector.plugin.v1.AnomalyDetector/Evaluate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *anomalyDetectorClient) Watch(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (AnomalyDetector_WatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AnomalyDetector_serviceDesc.Streams[0], c.cc, "/kubervisor.detector.plugin.v1.AnomalyDetector/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &anomalyDetectorWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AnomalyDetector_WatchClient interface {
+	Recv() (*EvaluateResponse, error)
+	grpc.ClientStream
+}
+
+type anomalyDetectorWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *anomalyDetectorWatchClient) Recv() (*EvaluateResponse, error) {
+	m := new(EvaluateResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for AnomalyDetector service
+
+type AnomalyDetectorServer interface {
+	// Evaluate returns the verdicts of the pods. It is called at each evaluation of the breaker
+	Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error)
+	// Watch sends the verdicts of all the pods each time they change, the first message is sent immediately.
+	// The call is restarted by kubervisor when the managed pods change
+	Watch(*EvaluateRequest, AnomalyDetector_WatchServer) error
+}
+
+func RegisterAnomalyDetectorServer(s *grpc.Server, srv AnomalyDetectorServer) {
+	s.RegisterService(&_AnomalyDetector_serviceDesc, srv)
+}
+
+func _AnomalyDetector_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnomalyDetectorServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kubervisor.detector.plugin.v1.AnomalyDetector/Evaluate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnomalyDetectorServer).Evaluate(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnomalyDetector_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EvaluateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AnomalyDetectorServer).Watch(m, &anomalyDetectorWatchServer{stream})
+}
+
+type AnomalyDetector_WatchServer interface {
+	Send(*EvaluateResponse) error
+	grpc.ServerStream
+}
+
+type anomalyDetectorWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *anomalyDetectorWatchServer) Send(m *EvaluateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AnomalyDetector_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "kubervisor.detector.plugin.v1.AnomalyDetector",
+	HandlerType: (*AnomalyDetectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Evaluate",
+			Handler:    _AnomalyDetector_Evaluate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _AnomalyDetector_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "detector.proto",
+}
+
+func init() { proto.RegisterFile("detector.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 431 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x93, 0xdf, 0x6a, 0xd4, 0x40,
+	0x14, 0xc6, 0x9d, 0xdd, 0xed, 0xbf, 0x23, 0xec, 0xae, 0xc7, 0x3f, 0xc4, 0xa2, 0x50, 0x72, 0x21,
+	0xad, 0x60, 0xc6, 0xae, 0xe0, 0x9d, 0x68, 0xeb, 0x56, 0x28, 0x85, 0xdd, 0x35, 0xed, 0xb6, 0xe0,
+	0x4d, 0x98, 0x4d, 0x86, 0x74, 0xdc, 0x4d, 0x66, 0x9c, 0x99, 0x04, 0xfa, 0x16, 0x3e, 0x8b, 0x8f,
+	0xe5, 0x53, 0x48, 0x27, 0x49, 0x03, 0x22, 0xae, 0x37, 0xde, 0xcd, 0x99, 0x73, 0xbe, 0xef, 0xfc,
+	0xe6, 0x23, 0x81, 0x7e, 0xc2, 0x2d, 0x8f, 0xad, 0xd4, 0x81, 0xd2, 0xd2, 0x4a, 0x7c, 0xbe, 0x2c,
+	0x16, 0x5c, 0x97, 0xc2, 0x48, 0x1d, 0xb4, 0xad, 0x55, 0x91, 0x8a, 0x3c, 0x28, 0x0f, 0xfd, 0x1f,
+	0x04, 0x06, 0x27, 0x25, 0x5b, 0x15, 0xcc, 0xf2, 0x90, 0x7f, 0x2b, 0xb8, 0xb1, 0xf8, 0x0c, 0x76,
+	0x72, 0x96, 0x71, 0xa3, 0x58, 0xcc, 0x3d, 0xb2, 0x47, 0xf6, 0x77, 0xc2, 0xf6, 0x02, 0x5f, 0x01,
+	0xb6, 0x96, 0x91, 0xb9, 0x3d, 0xc4, 0xdc, 0xeb, 0xb8, 0xb1, 0x07, 0x6d, 0xe7, 0xbc, 0x6a, 0xe0,
+	0x2e, 0x6c, 0x1b, 0xab, 0x99, 0xe5, 0xe9, 0x8d, 0xd7, 0x75, 0x43, 0x77, 0x35, 0xbe, 0x85, 0x9e,
+	0x92, 0x89, 0xf1, 0x7a, 0x7b, 0xdd, 0xfd, 0xfb, 0x23, 0x3f, 0xf8, 0x2b, 0x6a, 0x30, 0x93, 0x49,
+	0xe8, 0xe6, 0xfd, 0x03, 0xe8, 0xce, 0x64, 0x82, 0x08, 0xbd, 0x5b, 0xac, 0x1a, 0xd1, 0x9d, 0xb1,
+	0x0f, 0x1d, 0xa1, 0x6a, 0x9a, 0x8e, 0x50, 0xfe, 0x77, 0x02, 0x30, 0x93, 0xc9, 0x25, 0xd7, 0x89,
+	0x88, 0xed, 0x1f, 0x25, 0x1f, 0x60, 0xab, 0xac, 0xda, 0x4e, 0xd7, 0x1f, 0xbd, 0x58, 0x03, 0x52,
+	0x9b, 0x85, 0x8d, 0x0c, 0x1f, 0xc1, 0x86, 0x89, 0xa5, 0xe6, 0xee, 0x81, 0x24, 0xac, 0x0a, 0x7c,
+	0x02, 0x9b, 0x9a, 0x33, 0x23, 0x73, 0xaf, 0xe7, 0xb6, 0xd5, 0x95, 0xff, 0x19, 0x86, 0x6d, 0xe2,
+	0x46, 0xc9, 0xdc, 0x70, 0x7c, 0x57, 0x27, 0x41, 0x5c, 0x12, 0x07, 0xeb, 0x93, 0x68, 0x18, 0x9c,
+	0xec, 0xe5, 0x29, 0x6c, 0x35, 0x2f, 0x7c, 0x08, 0x83, 0xcb, 0x93, 0x70, 0x7c, 0xfa, 0xf1, 0x22,
+	0x9a, 0x4f, 0xce, 0x26, 0xd3, 0xab, 0xc9, 0xf0, 0x1e, 0xf6, 0x01, 0x9a, 0xcb, 0xe9, 0xd9, 0x90,
+	0xe0, 0x53, 0x78, 0x7c, 0x57, 0xcf, 0x2f, 0xa2, 0xe9, 0xa7, 0xe8, 0x78, 0x3a, 0x9f, 0x8c, 0xcf,
+	0x87, 0x9d, 0xd1, 0x4f, 0x02, 0x83, 0xa3, 0x5c, 0x66, 0x6c, 0x75, 0x33, 0xae, 0x37, 0xe3, 0x12,
+	0xb6, 0x1b, 0x62, 0x0c, 0xd6, 0xb0, 0xfd, 0xf6, 0x31, 0xed, 0xd2, 0x7f, 0x9e, 0xaf, 0xa3, 0xf8,
+	0x0a, 0x1b, 0x57, 0xcc, 0xc6, 0xd7, 0xff, 0x7d, 0xd3, 0x6b, 0x72, 0x7c, 0xf4, 0xe5, 0x7d, 0x2a,
+	0xec, 0x75, 0xb1, 0x08, 0x62, 0x99, 0x51, 0x96, 0xb1, 0x84, 0x17, 0x46, 0xd8, 0x54, 0xcb, 0x42,
+	0xd1, 0xd6, 0x8d, 0xaa, 0x65, 0x4a, 0x99, 0x12, 0xb4, 0x71, 0xa5, 0x95, 0x2b, 0x2d, 0x0f, 0x17,
+	0x9b, 0xee, 0x37, 0x7b, 0xf3, 0x6b, 0x00, 0xe5, 0x09, 0x2b, 0xb7, 0x78, 0x03, 0x00, 0x00,
+}
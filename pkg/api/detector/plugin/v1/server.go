@@ -0,0 +1,57 @@
+// Package v1 is the version 1 of the gRPC protocol between kubervisor and the out-of-process anomaly detector plugins, defined in detector.proto
+package v1
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Detector implemented by the plugins
+type Detector interface {
+	Evaluate(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error)
+}
+
+// Watcher optionally implemented by the Detectors that push their verdicts.
+// Watch sends the verdicts of all the pods each time they change, until ctx is done
+type Watcher interface {
+	Watch(ctx context.Context, req *EvaluateRequest, send func(*EvaluateResponse) error) error
+}
+
+// detectorServer adapts a Detector to the generated AnomalyDetectorServer
+type detectorServer struct {
+	detector Detector
+}
+
+var _ AnomalyDetectorServer = &detectorServer{}
+
+func (s *detectorServer) Evaluate(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	return s.detector.Evaluate(ctx, req)
+}
+
+func (s *detectorServer) Watch(req *EvaluateRequest, stream AnomalyDetector_WatchServer) error {
+	w, ok := s.detector.(Watcher)
+	if !ok {
+		return grpc.Errorf(codes.Unimplemented, "the detector doesn't implement Watch")
+	}
+	return w.Watch(stream.Context(), req, stream.Send)
+}
+
+// NewServer returns a gRPC server exposing the detector. Watch is unimplemented if the detector is not a Watcher
+func NewServer(d Detector, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	RegisterAnomalyDetectorServer(s, &detectorServer{detector: d})
+	return s
+}
+
+// ListenAndServe serves the detector with cleartext HTTP/2 on addr
+func ListenAndServe(addr string, d Detector) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	return NewServer(d).Serve(listener)
+}
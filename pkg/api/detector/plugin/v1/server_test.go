@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type testDetector struct {
+	verdicts []*PodVerdict
+}
+
+func (d *testDetector) Evaluate(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	resp := &EvaluateResponse{}
+	for _, p := range req.Pods {
+		for _, v := range d.verdicts {
+			if v.Name == p.Name {
+				resp.Pods = append(resp.Pods, v)
+			}
+		}
+	}
+	return resp, nil
+}
+
+type testWatcher struct {
+	testDetector
+}
+
+func (w *testWatcher) Watch(ctx context.Context, req *EvaluateRequest, send func(*EvaluateResponse) error) error {
+	for i := 0; i < 2; i++ {
+		resp, _ := w.Evaluate(ctx, req)
+		if err := send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serve(t *testing.T, d Detector) (AnomalyDetectorClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %v", err)
+	}
+	server := NewServer(d)
+	go server.Serve(listener)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	return NewAnomalyDetectorClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestServer(t *testing.T) {
+	verdicts := []*PodVerdict{
+		{Name: "A", Verdict: Verdict_VERDICT_OK},
+		{Name: "B", Verdict: Verdict_VERDICT_OUT_OF_BOUNDS, Score: 0.75, Reason: "error rate 12%"},
+	}
+	req := &EvaluateRequest{Namespace: "ns", KubervisorService: "foo", Strategy: "bar", Pods: []*Pod{{Name: "A", Ip: "10.0.0.1"}, {Name: "B", Ip: "10.0.0.2"}}}
+	client, stop := serve(t, &testWatcher{testDetector{verdicts: verdicts}})
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.Evaluate(ctx, req)
+	if err != nil {
+		t.Fatalf("Client.Evaluate() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Pods, verdicts) {
+		t.Errorf("Client.Evaluate() = %v, want %v", resp, verdicts)
+	}
+
+	stream, err := client.Watch(ctx, req)
+	if err != nil {
+		t.Fatalf("Client.Watch() unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := stream.Recv()
+		if err != nil || !reflect.DeepEqual(resp.Pods, verdicts) {
+			t.Fatalf("AnomalyDetector_WatchClient.Recv() = %v, %v, want %v", resp, err, verdicts)
+		}
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("AnomalyDetector_WatchClient.Recv() error = %v, want io.EOF", err)
+	}
+}
+
+func TestServer_WatchUnimplemented(t *testing.T) {
+	client, stop := serve(t, &testDetector{})
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &EvaluateRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if grpc.Code(err) != codes.Unimplemented {
+		t.Errorf("Client.Watch() error = %v, want Unimplemented", err)
+	}
+}
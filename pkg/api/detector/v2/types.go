@@ -0,0 +1,48 @@
+// Package v2 is the version 2 of the protocol between kubervisor and the custom anomaly detectors.
+// At each evaluation kubervisor POSTs a Request to the detector, that answers a Response with a verdict for each pod.
+package v2
+
+// APIVersion value of the apiVersion field of the requests and responses
+const APIVersion = "kubervisor.k8s.io/detector/v2"
+
+// ContentType of the requests and responses
+const ContentType = "application/json"
+
+// Request sent by kubervisor to the detector
+type Request struct {
+	APIVersion        string `json:"apiVersion"`
+	Namespace         string `json:"namespace"`
+	KubervisorService string `json:"kubervisorService"`
+	Strategy          string `json:"strategy"`
+	Pods              []Pod  `json:"pods"` // Pods managed by the KubervisorService that receive traffic
+}
+
+// Pod managed by the KubervisorService
+type Pod struct {
+	Name string `json:"name"`
+	IP   string `json:"ip,omitempty"`
+}
+
+// Response returned by the detector
+type Response struct {
+	APIVersion string       `json:"apiVersion"`
+	Pods       []PodVerdict `json:"pods"` // Pods without verdict are considered ok
+}
+
+// Verdict of the detector for a pod
+type Verdict string
+
+// Verdict values
+const (
+	VerdictOK          Verdict = "ok"
+	VerdictOutOfBounds Verdict = "outOfBounds"
+	VerdictUnknown     Verdict = "unknown"
+)
+
+// PodVerdict verdict of the detector for one pod
+type PodVerdict struct {
+	Name    string  `json:"name"`
+	Verdict Verdict `json:"verdict"`
+	Score   float64 `json:"score,omitempty"`  // Anomaly score, for information
+	Reason  string  `json:"reason,omitempty"` // Human readable explanation of the verdict
+}
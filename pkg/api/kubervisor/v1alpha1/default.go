@@ -7,6 +7,9 @@ func DefaultKubervisorService(item *KubervisorService) *KubervisorService {
 	for i := range copy.Spec.Breakers {
 		copy.Spec.Breakers[i] = *DefaultBreakerStrategy(&copy.Spec.Breakers[i])
 	}
+	if copy.Spec.BreakRateLimit != nil && copy.Spec.BreakRateLimit.Period == nil {
+		copy.Spec.BreakRateLimit.Period = NewFloat64(300)
+	}
 	return copy
 }
 
@@ -25,6 +28,12 @@ func DefaultDiscreteValueOutOfList(item *DiscreteValueOutOfList) *DiscreteValueO
 	if copy.TolerancePercent == nil {
 		copy.TolerancePercent = NewUInt(50)
 	}
+	if copy.PodMetricsScrape != nil {
+		copy.PodMetricsScrape = DefaultPodMetricsScrape(copy.PodMetricsScrape)
+	}
+	if copy.RemoteWrite != nil {
+		copy.RemoteWrite = DefaultRemoteWriteSource(copy.RemoteWrite)
+	}
 	return copy
 }
 
@@ -34,9 +43,223 @@ func DefaultContinuousValueDeviation(item *ContinuousValueDeviation) *Continuous
 	if copy.MaxDeviationPercent == nil {
 		copy.MaxDeviationPercent = NewFloat64(75)
 	}
+	if copy.PodMetricsScrape != nil {
+		copy.PodMetricsScrape = DefaultPodMetricsScrape(copy.PodMetricsScrape)
+	}
+	if copy.RemoteWrite != nil {
+		copy.RemoteWrite = DefaultRemoteWriteSource(copy.RemoteWrite)
+	}
+	if copy.Baseline != nil && copy.Baseline.MedianWindow != "" && copy.Baseline.MedianStep == "" {
+		copy.Baseline.MedianStep = "1h"
+	}
+	return copy
+}
+
+//DefaultPodMetricsScrape injecting default values for the struct
+func DefaultPodMetricsScrape(item *PodMetricsScrape) *PodMetricsScrape {
+	copy := item.DeepCopy()
+	if copy.Path == "" {
+		copy.Path = "/metrics"
+	}
+	if copy.Timeout == nil {
+		copy.Timeout = NewFloat64(1)
+	}
+	return copy
+}
+
+//DefaultRemoteWriteSource injecting default values for the struct
+func DefaultRemoteWriteSource(item *RemoteWriteSource) *RemoteWriteSource {
+	copy := item.DeepCopy()
+	if copy.Window == "" {
+		copy.Window = "1m"
+	}
+	return copy
+}
+
+//DefaultLatencyPercentile injecting default values for the struct
+func DefaultLatencyPercentile(item *LatencyPercentile) *LatencyPercentile {
+	copy := item.DeepCopy()
+	if copy.Quantile == nil {
+		copy.Quantile = NewFloat64(0.99)
+	}
+	if copy.Window == "" {
+		copy.Window = "1m"
+	}
+	if copy.MaxDeviationPercent == nil && copy.MaxLatency == nil {
+		copy.MaxDeviationPercent = NewFloat64(100)
+	}
+	return copy
+}
+
+//DefaultPodStatusAnomaly injecting default values for the struct
+func DefaultPodStatusAnomaly(item *PodStatusAnomaly) *PodStatusAnomaly {
+	copy := item.DeepCopy()
+	if copy.Window == nil {
+		copy.Window = NewFloat64(300)
+	}
+	if copy.MaxRestartCount == nil {
+		copy.MaxRestartCount = NewUInt(0)
+	}
+	if copy.TerminationReasons == nil {
+		copy.TerminationReasons = []string{"OOMKilled", "Error"}
+	}
+	if copy.MaxReadyFlipCount == nil {
+		copy.MaxReadyFlipCount = NewUInt(3)
+	}
+	return copy
+}
+
+//DefaultPodLogPattern injecting default values for the struct
+func DefaultPodLogPattern(item *PodLogPattern) *PodLogPattern {
+	copy := item.DeepCopy()
+	if copy.MinimumActivityCount == nil {
+		copy.MinimumActivityCount = NewUInt(2)
+	}
+	if copy.TolerancePercent == nil {
+		copy.TolerancePercent = NewUInt(50)
+	}
+	if copy.MaxBytesPerPod == nil {
+		copy.MaxBytesPerPod = NewInt64(1 << 20)
+	}
+	return copy
+}
+
+//DefaultActiveProbe injecting default values for the struct
+func DefaultActiveProbe(item *ActiveProbe) *ActiveProbe {
+	copy := item.DeepCopy()
+	if copy.Timeout == nil {
+		copy.Timeout = NewFloat64(1)
+	}
+	if copy.Window == nil {
+		copy.Window = NewUInt(5)
+	}
+	if copy.TolerancePercent == nil {
+		copy.TolerancePercent = NewUInt(50)
+	}
+	if copy.MaxConcurrentProbes == nil {
+		copy.MaxConcurrentProbes = NewUInt(10)
+	}
+	if copy.HTTP != nil {
+		defaultHTTPProbe(copy.HTTP)
+	}
+	return copy
+}
+
+//DefaultConsensus injecting default values for the struct
+func DefaultConsensus(item *Consensus) *Consensus {
+	copy := item.DeepCopy()
+	if copy.QuorumPercent == nil {
+		copy.QuorumPercent = NewUInt(51)
+	}
+	if copy.Timeout == nil {
+		copy.Timeout = NewFloat64(1)
+	}
+	if copy.MaxConcurrentRequests == nil {
+		copy.MaxConcurrentRequests = NewUInt(10)
+	}
+	defaultHTTPProbe(&copy.HTTP)
+	return copy
+}
+
+//DefaultCustomDetector injecting default values for the struct
+func DefaultCustomDetector(item *CustomDetector) *CustomDetector {
+	copy := item.DeepCopy()
+	if copy.Timeout == nil {
+		copy.Timeout = NewFloat64(1)
+	}
+	return copy
+}
+
+//DefaultGRPCService injecting default values for the struct
+func DefaultGRPCService(item *GRPCService) *GRPCService {
+	copy := item.DeepCopy()
+	if copy.Mode == "" {
+		copy.Mode = GRPCServiceModeEvaluate
+	}
+	if copy.Timeout == nil {
+		copy.Timeout = NewFloat64(1)
+	}
+	return copy
+}
+
+//DefaultAnomalyReport injecting default values for the struct
+func DefaultAnomalyReport(item *AnomalyReport) *AnomalyReport {
+	copy := item.DeepCopy()
+	if copy.MinSeverity == "" {
+		copy.MinSeverity = PodAnomalyReportSeverityWarning
+	}
 	return copy
 }
 
+//DefaultAlertmanager injecting default values for the struct
+func DefaultAlertmanager(item *Alertmanager) *Alertmanager {
+	copy := item.DeepCopy()
+	if copy.PodLabel == "" {
+		copy.PodLabel = "pod"
+	}
+	if copy.NamespaceLabel == "" {
+		copy.NamespaceLabel = "namespace"
+	}
+	return copy
+}
+
+//DefaultDetectorPreset injecting default values for the struct
+func DefaultDetectorPreset(item *DetectorPreset) *DetectorPreset {
+	copy := item.DeepCopy()
+	if copy.Window == "" {
+		copy.Window = "1m"
+	}
+	if copy.MinimumActivityCount == nil {
+		copy.MinimumActivityCount = NewUInt(2)
+	}
+	if copy.TolerancePercent == nil {
+		copy.TolerancePercent = NewUInt(50)
+	}
+	return copy
+}
+
+//DefaultResourceUsage injecting default values for the struct
+func DefaultResourceUsage(item *ResourceUsage) *ResourceUsage {
+	copy := item.DeepCopy()
+	if copy.MaxFleetRatio == nil && copy.MaxLimitPercent == nil {
+		copy.MaxFleetRatio = NewFloat64(2)
+	}
+	if copy.SustainedPeriod == nil {
+		copy.SustainedPeriod = NewFloat64(300)
+	}
+	if copy.PrometheusService != "" && copy.PodNameKey == "" {
+		copy.PodNameKey = "pod"
+	}
+	return copy
+}
+
+//DefaultBurnRate injecting default values for the struct
+func DefaultBurnRate(item *BurnRate) *BurnRate {
+	copy := item.DeepCopy()
+	if copy.ShortWindow == "" {
+		copy.ShortWindow = "5m"
+	}
+	if copy.LongWindow == "" {
+		copy.LongWindow = "1h"
+	}
+	if copy.MaxBurnRate == nil {
+		copy.MaxBurnRate = NewFloat64(14.4)
+	}
+	if copy.MinimumActivityCount == nil {
+		copy.MinimumActivityCount = NewUInt(10)
+	}
+	return copy
+}
+
+func defaultHTTPProbe(item *HTTPProbe) {
+	if item.Method == "" {
+		item.Method = "GET"
+	}
+	if item.Path == "" {
+		item.Path = "/"
+	}
+}
+
 // DefaultBreakerStrategy injecting default values for the struct
 func DefaultBreakerStrategy(item *BreakerStrategy) *BreakerStrategy {
 	copy := item.DeepCopy()
@@ -52,6 +275,42 @@ func DefaultBreakerStrategy(item *BreakerStrategy) *BreakerStrategy {
 	if copy.ContinuousValueDeviation != nil {
 		copy.ContinuousValueDeviation = DefaultContinuousValueDeviation(copy.ContinuousValueDeviation)
 	}
+	if copy.LatencyPercentile != nil {
+		copy.LatencyPercentile = DefaultLatencyPercentile(copy.LatencyPercentile)
+	}
+	if copy.PodStatusAnomaly != nil {
+		copy.PodStatusAnomaly = DefaultPodStatusAnomaly(copy.PodStatusAnomaly)
+	}
+	if copy.PodLogPattern != nil {
+		copy.PodLogPattern = DefaultPodLogPattern(copy.PodLogPattern)
+	}
+	if copy.ActiveProbe != nil {
+		copy.ActiveProbe = DefaultActiveProbe(copy.ActiveProbe)
+	}
+	if copy.Consensus != nil {
+		copy.Consensus = DefaultConsensus(copy.Consensus)
+	}
+	if copy.CustomDetector != nil {
+		copy.CustomDetector = DefaultCustomDetector(copy.CustomDetector)
+	}
+	if copy.GRPCService != nil {
+		copy.GRPCService = DefaultGRPCService(copy.GRPCService)
+	}
+	if copy.AnomalyReport != nil {
+		copy.AnomalyReport = DefaultAnomalyReport(copy.AnomalyReport)
+	}
+	if copy.Alertmanager != nil {
+		copy.Alertmanager = DefaultAlertmanager(copy.Alertmanager)
+	}
+	if copy.Preset != nil {
+		copy.Preset = DefaultDetectorPreset(copy.Preset)
+	}
+	if copy.ResourceUsage != nil {
+		copy.ResourceUsage = DefaultResourceUsage(copy.ResourceUsage)
+	}
+	if copy.BurnRate != nil {
+		copy.BurnRate = DefaultBurnRate(copy.BurnRate)
+	}
 	return copy
 }
 
@@ -87,6 +346,13 @@ func NewFloat64(val float64) *float64 {
 	return output
 }
 
+// NewInt64 return a pointer to a int64
+func NewInt64(val int64) *int64 {
+	output := new(int64)
+	*output = val
+	return output
+}
+
 // IsKubervisorServiceDefaulted used to check if a KubervisorService is already defaulted
 func IsKubervisorServiceDefaulted(bc *KubervisorService) bool {
 	if !isActivatorStrategyDefaulted(&bc.Spec.DefaultActivator) {
@@ -97,6 +363,9 @@ func IsKubervisorServiceDefaulted(bc *KubervisorService) bool {
 			return false
 		}
 	}
+	if bc.Spec.BreakRateLimit != nil && bc.Spec.BreakRateLimit.Period == nil {
+		return false
+	}
 	return true
 }
 
@@ -130,6 +399,66 @@ func isBreakerStrategyDefaulted(item *BreakerStrategy) bool {
 			return false
 		}
 	}
+	if item.LatencyPercentile != nil {
+		if !isLatencyPercentileDefaulted(item.LatencyPercentile) {
+			return false
+		}
+	}
+	if item.PodStatusAnomaly != nil {
+		if !isPodStatusAnomalyDefaulted(item.PodStatusAnomaly) {
+			return false
+		}
+	}
+	if item.PodLogPattern != nil {
+		if !isPodLogPatternDefaulted(item.PodLogPattern) {
+			return false
+		}
+	}
+	if item.ActiveProbe != nil {
+		if !isActiveProbeDefaulted(item.ActiveProbe) {
+			return false
+		}
+	}
+	if item.Consensus != nil {
+		if !isConsensusDefaulted(item.Consensus) {
+			return false
+		}
+	}
+	if item.CustomDetector != nil {
+		if !isCustomDetectorDefaulted(item.CustomDetector) {
+			return false
+		}
+	}
+	if item.GRPCService != nil {
+		if !isGRPCServiceDefaulted(item.GRPCService) {
+			return false
+		}
+	}
+	if item.AnomalyReport != nil {
+		if !isAnomalyReportDefaulted(item.AnomalyReport) {
+			return false
+		}
+	}
+	if item.Alertmanager != nil {
+		if !isAlertmanagerDefaulted(item.Alertmanager) {
+			return false
+		}
+	}
+	if item.Preset != nil {
+		if !isDetectorPresetDefaulted(item.Preset) {
+			return false
+		}
+	}
+	if item.ResourceUsage != nil {
+		if !isResourceUsageDefaulted(item.ResourceUsage) {
+			return false
+		}
+	}
+	if item.BurnRate != nil {
+		if !isBurnRateDefaulted(item.BurnRate) {
+			return false
+		}
+	}
 	if item.ContinuousValueDeviation != nil {
 		return isContinuousValueDeviationDefaulted(item.ContinuousValueDeviation)
 	}
@@ -147,10 +476,126 @@ func isDiscreteValueOutOfListDefaulted(item *DiscreteValueOutOfList) bool {
 	if item.TolerancePercent == nil {
 		return false
 	}
+	if item.RemoteWrite != nil && item.RemoteWrite.Window == "" {
+		return false
+	}
+	if item.PodMetricsScrape != nil && !isPodMetricsScrapeDefaulted(item.PodMetricsScrape) {
+		return false
+	}
 	return true
 }
 
 // isDiscreteValueOutOfListDefaulted used to check if a DiscreteValueOutOfList is already defaulted
 func isContinuousValueDeviationDefaulted(item *ContinuousValueDeviation) bool {
+	if item.Baseline != nil && item.Baseline.MedianWindow != "" && item.Baseline.MedianStep == "" {
+		return false
+	}
+	if item.RemoteWrite != nil && item.RemoteWrite.Window == "" {
+		return false
+	}
+	if item.PodMetricsScrape != nil && !isPodMetricsScrapeDefaulted(item.PodMetricsScrape) {
+		return false
+	}
 	return item.MaxDeviationPercent != nil
 }
+
+// isPodMetricsScrapeDefaulted used to check if a PodMetricsScrape is already defaulted
+func isPodMetricsScrapeDefaulted(item *PodMetricsScrape) bool {
+	return item.Path != "" && item.Timeout != nil
+}
+
+// isLatencyPercentileDefaulted used to check if a LatencyPercentile is already defaulted
+func isLatencyPercentileDefaulted(item *LatencyPercentile) bool {
+	if item.Quantile == nil || item.Window == "" {
+		return false
+	}
+	return item.MaxDeviationPercent != nil || item.MaxLatency != nil
+}
+
+// isPodStatusAnomalyDefaulted used to check if a PodStatusAnomaly is already defaulted
+func isPodStatusAnomalyDefaulted(item *PodStatusAnomaly) bool {
+	return item.Window != nil && item.MaxRestartCount != nil && item.TerminationReasons != nil && item.MaxReadyFlipCount != nil
+}
+
+// isPodLogPatternDefaulted used to check if a PodLogPattern is already defaulted
+func isPodLogPatternDefaulted(item *PodLogPattern) bool {
+	if item.MinimumActivityCount == nil {
+		return false
+	}
+	if item.TolerancePercent == nil {
+		return false
+	}
+	if item.MaxBytesPerPod == nil {
+		return false
+	}
+	return true
+}
+
+// isActiveProbeDefaulted used to check if a ActiveProbe is already defaulted
+func isActiveProbeDefaulted(item *ActiveProbe) bool {
+	if item.Timeout == nil {
+		return false
+	}
+	if item.Window == nil {
+		return false
+	}
+	if item.TolerancePercent == nil {
+		return false
+	}
+	if item.MaxConcurrentProbes == nil {
+		return false
+	}
+	return true
+}
+
+// isConsensusDefaulted used to check if a Consensus is already defaulted
+func isConsensusDefaulted(item *Consensus) bool {
+	if item.QuorumPercent == nil {
+		return false
+	}
+	if item.Timeout == nil {
+		return false
+	}
+	if item.MaxConcurrentRequests == nil {
+		return false
+	}
+	return true
+}
+
+// isCustomDetectorDefaulted used to check if a CustomDetector is already defaulted
+func isCustomDetectorDefaulted(item *CustomDetector) bool {
+	return item.Timeout != nil
+}
+
+// isGRPCServiceDefaulted used to check if a GRPCService is already defaulted
+func isGRPCServiceDefaulted(item *GRPCService) bool {
+	return item.Mode != "" && item.Timeout != nil
+}
+
+// isAnomalyReportDefaulted used to check if an AnomalyReport is already defaulted
+func isAnomalyReportDefaulted(item *AnomalyReport) bool {
+	return item.MinSeverity != ""
+}
+
+// isAlertmanagerDefaulted used to check if an Alertmanager is already defaulted
+func isAlertmanagerDefaulted(item *Alertmanager) bool {
+	return item.PodLabel != "" && item.NamespaceLabel != ""
+}
+
+// isDetectorPresetDefaulted used to check if a DetectorPreset is already defaulted
+func isDetectorPresetDefaulted(item *DetectorPreset) bool {
+	return item.Window != "" && item.MinimumActivityCount != nil && item.TolerancePercent != nil
+}
+
+// isResourceUsageDefaulted used to check if a ResourceUsage is already defaulted
+func isResourceUsageDefaulted(item *ResourceUsage) bool {
+	if item.PrometheusService != "" && item.PodNameKey == "" {
+		return false
+	}
+	return item.SustainedPeriod != nil && (item.MaxFleetRatio != nil || item.MaxLimitPercent != nil)
+}
+
+// isBurnRateDefaulted used to check if a BurnRate is already defaulted
+func isBurnRateDefaulted(item *BurnRate) bool {
+	return item.ShortWindow != "" && item.LongWindow != "" && item.MaxBurnRate != nil && item.MinimumActivityCount != nil
+}
@@ -157,6 +157,210 @@ func Test_isBreakerStrategyDefaulted(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "missing LatencyPercentile values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					LatencyPercentile:     &LatencyPercentile{},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "LatencyPercentile defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{LatencyPercentile: &LatencyPercentile{}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing PodLogPattern values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					PodLogPattern:         &PodLogPattern{},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "PodLogPattern defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{PodLogPattern: &PodLogPattern{}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing ActiveProbe values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					ActiveProbe:           &ActiveProbe{Port: 8080},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "ActiveProbe defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{ActiveProbe: &ActiveProbe{Port: 8080, HTTP: &HTTPProbe{}}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing Consensus values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					Consensus:             &Consensus{Port: 8080},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "Consensus defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{Consensus: &Consensus{Port: 8080}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing CustomDetector values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					CustomDetector:        &CustomDetector{URL: "http://detector"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "CustomDetector defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{CustomDetector: &CustomDetector{URL: "http://detector"}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing GRPCService values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					GRPCService:           &GRPCService{Address: "detector:9000", Timeout: NewFloat64(1)},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "GRPCService defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{GRPCService: &GRPCService{Address: "detector:9000"}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing AnomalyReport values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					AnomalyReport:         &AnomalyReport{},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "AnomalyReport defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{AnomalyReport: &AnomalyReport{}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing Alertmanager values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					Alertmanager:          &Alertmanager{PodLabel: "pod"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "Alertmanager defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{Alertmanager: &Alertmanager{}}),
+			},
+			want: true,
+		},
+		{
+			name: "missing Preset values",
+			args: args{
+				item: &BreakerStrategy{
+					EvaluationPeriod:      NewFloat64(1.0),
+					MinPodsAvailableCount: NewUInt(1),
+					Preset:                &DetectorPreset{Window: "1m"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "Preset defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{Preset: &DetectorPreset{}}),
+			},
+			want: true,
+		},
+		{
+			name: "BurnRate not defaulted",
+			args: args{
+				item: &BreakerStrategy{BurnRate: &BurnRate{Objective: NewFloat64(0.999)}},
+			},
+			want: false,
+		},
+		{
+			name: "BurnRate defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{BurnRate: &BurnRate{Objective: NewFloat64(0.999)}}),
+			},
+			want: true,
+		},
+		{
+			name: "ResourceUsage not defaulted",
+			args: args{
+				item: &BreakerStrategy{ResourceUsage: &ResourceUsage{Resource: "cpu"}},
+			},
+			want: false,
+		},
+		{
+			name: "ResourceUsage defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{ResourceUsage: &ResourceUsage{Resource: "cpu", PrometheusService: "prometheus:9090"}}),
+			},
+			want: true,
+		},
+		{
+			name: "ContinuousValueDeviation baseline not defaulted",
+			args: args{
+				item: &BreakerStrategy{ContinuousValueDeviation: &ContinuousValueDeviation{MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{MedianWindow: "1w"}}},
+			},
+			want: false,
+		},
+		{
+			name: "ContinuousValueDeviation baseline defaulted",
+			args: args{
+				item: DefaultBreakerStrategy(&BreakerStrategy{ContinuousValueDeviation: &ContinuousValueDeviation{Baseline: &DeviationBaseline{MedianWindow: "1w"}}}),
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -285,6 +489,19 @@ func TestIsKubervisorServiceDefaulted(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "BreakRateLimit not defaulted",
+			args: args{
+				bc: &KubervisorService{
+					Spec: KubervisorServiceSpec{
+						DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+						Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{})},
+						BreakRateLimit:   &BreakRateLimit{MaxBreaks: NewUInt(3)},
+					},
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -295,6 +512,74 @@ func TestIsKubervisorServiceDefaulted(t *testing.T) {
 	}
 }
 
+func TestIsKubervisorServiceDefaulted_metricsSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		breaker BreakerStrategy
+	}{
+		{
+			name: "discrete value out of list remote write without window",
+			breaker: BreakerStrategy{DiscreteValueOutOfList: &DiscreteValueOutOfList{
+				Key:                  "code",
+				GoodValues:           []string{"200"},
+				MinimumActivityCount: NewUInt(1),
+				TolerancePercent:     NewUInt(10),
+				RemoteWrite:          &RemoteWriteSource{Metric: "rpc_count"},
+			}},
+		},
+		{
+			name: "continuous value deviation remote write without window",
+			breaker: BreakerStrategy{ContinuousValueDeviation: &ContinuousValueDeviation{
+				MaxDeviationPercent: NewFloat64(20),
+				RemoteWrite:         &RemoteWriteSource{Metric: "rpc_duration"},
+			}},
+		},
+		{
+			name: "discrete value out of list pod metrics scrape without path and timeout",
+			breaker: BreakerStrategy{DiscreteValueOutOfList: &DiscreteValueOutOfList{
+				Key:                  "code",
+				GoodValues:           []string{"200"},
+				MinimumActivityCount: NewUInt(1),
+				TolerancePercent:     NewUInt(10),
+				PodMetricsScrape:     &PodMetricsScrape{Port: 8080, Metric: "rpc_count"},
+			}},
+		},
+		{
+			name: "continuous value deviation pod metrics scrape without path and timeout",
+			breaker: BreakerStrategy{ContinuousValueDeviation: &ContinuousValueDeviation{
+				MaxDeviationPercent: NewFloat64(20),
+				PodMetricsScrape:    &PodMetricsScrape{Port: 8080, Metric: "price"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := tt.breaker
+			breaker.Name = "strategy"
+			breaker.EvaluationPeriod = NewFloat64(1)
+			breaker.MinPodsAvailableCount = NewUInt(1)
+			bc := &KubervisorService{
+				Spec: KubervisorServiceSpec{
+					Service:          "servicefine",
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Breakers:         []BreakerStrategy{breaker},
+				},
+			}
+			// same sequence as the controller sync
+			if IsKubervisorServiceDefaulted(bc) {
+				t.Fatalf("IsKubervisorServiceDefaulted() = true, want false")
+			}
+			bc = DefaultKubervisorService(bc)
+			if !IsKubervisorServiceDefaulted(bc) {
+				t.Errorf("IsKubervisorServiceDefaulted() = false after DefaultKubervisorService()")
+			}
+			if err := ValidateKubervisorServiceSpec(bc.Spec); err != nil {
+				t.Errorf("ValidateKubervisorServiceSpec() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestDefaultKubervisorService(t *testing.T) {
 	bc := &KubervisorService{}
 	bc = DefaultKubervisorService(bc)
@@ -302,4 +587,10 @@ func TestDefaultKubervisorService(t *testing.T) {
 	if !IsKubervisorServiceDefaulted(bc) {
 		t.Errorf("KubervisorService is not defaulted properly")
 	}
+
+	bc.Spec.BreakRateLimit = &BreakRateLimit{MaxBreaks: NewUInt(3)}
+	bc = DefaultKubervisorService(bc)
+	if !IsKubervisorServiceDefaulted(bc) || *bc.Spec.BreakRateLimit.Period != 300 {
+		t.Errorf("KubervisorService break rate limit is not defaulted properly")
+	}
 }
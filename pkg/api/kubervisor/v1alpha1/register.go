@@ -25,6 +25,13 @@ const (
 	ResourceKind = "KubervisorService"
 	// ResourceVersion represent the resource version
 	ResourceVersion = "v1alpha1"
+
+	// ReportResourcePlural is the id to indentify the plural of PodAnomalyReport
+	ReportResourcePlural = "podanomalyreports"
+	// ReportResourceSingular represents the id for identify singular PodAnomalyReport resource
+	ReportResourceSingular = "podanomalyreport"
+	// ReportResourceKind represent the PodAnomalyReport resource kind
+	ReportResourceKind = "PodAnomalyReport"
 )
 
 var (
@@ -47,6 +54,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&KubervisorService{},
 		&KubervisorServiceList{},
+		&PodAnomalyReport{},
+		&PodAnomalyReportList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
@@ -40,6 +41,17 @@ type KubervisorServiceSpec struct {
 	Breakers         []BreakerStrategy `json:"breakers"`
 	DefaultActivator ActivatorStrategy `json:"defaultActivator"`
 	Service          string            `json:"service,omitempty"`
+	// MaxUnavailable maximum number, or % of the managed pods, of pods broken or paused at once by all the breaker strategies
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// BreakRateLimit maximum number of pods broken by all the breaker strategies over a period
+	BreakRateLimit *BreakRateLimit `json:"breakRateLimit,omitempty"`
+}
+
+// BreakRateLimit limits the number of pods broken over a sliding time window, so that a flapping detector can't churn the whole fleet
+// The breaks denied by the limit are reported with an event on the KubervisorService and the kubervisor_rate_limited_breaks metric
+type BreakRateLimit struct {
+	MaxBreaks *uint    `json:"maxBreaks"`        // Maximum number of pods broken during the period, at least 1: the rate limit is not a way to stop breaking pods
+	Period    *float64 `json:"period,omitempty"` // Duration of the sliding window in seconds
 }
 
 // KubervisorServiceConditionType KubervisorService Condition Type
@@ -55,6 +67,8 @@ const (
 	KubeServiceNotAvailable KubervisorServiceConditionType = "ServiceNotAvailable"
 	// KubervisorServiceFailed means the KubervisorService has failed its execution.
 	KubervisorServiceFailed KubervisorServiceConditionType = "Failed"
+	// KubervisorServiceFleetIncident means a breaker detected a service-level incident and stopped cutting pods.
+	KubervisorServiceFleetIncident KubervisorServiceConditionType = "FleetIncident"
 )
 
 // KubervisorServiceCondition represent the condition of the KubervisorService
@@ -98,17 +112,81 @@ type PodCountStatus struct {
 	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
 }
 
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodAnomalyReport reports an anomaly of a pod, written by an external system that can't be polled by kubervisor
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PodAnomalyReport struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the reported anomaly
+	Spec PodAnomalyReportSpec `json:"spec,omitempty"`
+}
+
+// PodAnomalyReportList implements list of PodAnomalyReport.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PodAnomalyReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of PodAnomalyReport
+	Items []PodAnomalyReport `json:"items"`
+}
+
+// PodAnomalyReportSpec contains PodAnomalyReport specification
+type PodAnomalyReportSpec struct {
+	PodName  string                   `json:"podName"`          // Name of the pod, in the namespace of the report
+	Source   string                   `json:"source"`           // System that reported the anomaly. example: synthetic-monitoring
+	Severity PodAnomalyReportSeverity `json:"severity"`         // info, warning or critical
+	Reason   string                   `json:"reason,omitempty"` // Human readable description of the anomaly
+	Expiry   metav1.Time              `json:"expiry"`           // The report is ignored, then garbage-collected by the controller, after this time
+}
+
+// PodAnomalyReportSeverity represent the severity of a reported anomaly
+type PodAnomalyReportSeverity string
+
+// PodAnomalyReportSeverity defines the possible severities, by increasing order
+const (
+	PodAnomalyReportSeverityInfo     PodAnomalyReportSeverity = "info"
+	PodAnomalyReportSeverityWarning  PodAnomalyReportSeverity = "warning"
+	PodAnomalyReportSeverityCritical PodAnomalyReportSeverity = "critical"
+)
+
 // BreakerStrategy contains BreakerStrategy definition
 type BreakerStrategy struct {
-	Name                  string   `json:"name"`
-	EvaluationPeriod      *float64 `json:"evaluationPeriod,omitempty"`
-	MinPodsAvailableCount *uint    `json:"minPodsAvailableCount,omitempty"`
-	MinPodsAvailableRatio *uint    `json:"minPodsAvailableRatio,omitempty"`
+	Name                        string                `json:"name"`
+	EvaluationPeriod            *float64              `json:"evaluationPeriod,omitempty"`
+	MinPodsAvailableCount       *uint                 `json:"minPodsAvailableCount,omitempty"`
+	MinPodsAvailableRatio       *uint                 `json:"minPodsAvailableRatio,omitempty"`
+	MinPodsAvailablePerTopology *TopologyMinAvailable `json:"minPodsAvailablePerTopology,omitempty"`
 
 	DiscreteValueOutOfList   *DiscreteValueOutOfList   `json:"discreteValueOutOfList,omitempty"`
 	ContinuousValueDeviation *ContinuousValueDeviation `json:"continuousValueDeviation,omitempty"`
+	LatencyPercentile        *LatencyPercentile        `json:"latencyPercentile,omitempty"`
+	PodStatusAnomaly         *PodStatusAnomaly         `json:"podStatusAnomaly,omitempty"`
+	PodLogPattern            *PodLogPattern            `json:"podLogPattern,omitempty"`
+	ActiveProbe              *ActiveProbe              `json:"activeProbe,omitempty"`
+	Consensus                *Consensus                `json:"consensus,omitempty"`
+
+	CustomService  string          `json:"customService,omitempty"`
+	CustomDetector *CustomDetector `json:"customDetector,omitempty"`
+	GRPCService    *GRPCService    `json:"grpcService,omitempty"`
+	AnomalyReport  *AnomalyReport  `json:"anomalyReport,omitempty"`
+	Alertmanager   *Alertmanager   `json:"alertmanager,omitempty"`
+
+	MetricsExpression *MetricsExpression `json:"metricsExpression,omitempty"`
+	Preset            *DetectorPreset    `json:"preset,omitempty"`
+	ResourceUsage     *ResourceUsage     `json:"resourceUsage,omitempty"`
+	BurnRate          *BurnRate          `json:"burnRate,omitempty"`
 
-	CustomService string `json:"customService,omitempty"`
+	FleetGuard *FleetGuard `json:"fleetGuard,omitempty"`
 
 	Activator *ActivatorStrategy `json:"activator"`
 }
@@ -120,8 +198,22 @@ type ContinuousValueDeviation struct {
 	PrometheusService string `json:"prometheusService"`
 	PromQL            string `json:"promQL"` // example deviation compare to global average: (rate(solution_price_sum[1m])/rate(solution_price_count[1m]) and delta(solution_price_count[1m])>70) / scalar(sum(rate(solution_price_sum[1m]))/sum(rate(solution_price_count[1m])))
 	// note the AND close that prevent to return record when there is less that 70 records over the floating time window of 1m
-	PodNameKey          string   `json:"podNamekey"`          // Key to access the podName
-	MaxDeviationPercent *float64 `json:"maxDeviationPercent"` // MaxDeviationPercent maxDeviation computation based on % of the mean
+	PodNameKey          string    `json:"podNamekey"`          // Key to access the podName
+	PodMatch            *PodMatch `json:"podMatch,omitempty"`  // How the value of the PodNameKey label identifies the pod. default: by pod name
+	MaxDeviationPercent *float64  `json:"maxDeviationPercent"` // MaxDeviationPercent maxDeviation computation based on % of the mean
+
+	PodMetricsScrape *PodMetricsScrape  `json:"podMetricsScrape,omitempty"` // Alternative to PrometheusService/PromQL: Metric must be a summary or histogram, the value of a pod is delta(<metric>_sum)/delta(<metric>_count) between two evaluations
+	RemoteWrite      *RemoteWriteSource `json:"remoteWrite,omitempty"`      // Alternative to PrometheusService/PromQL: same computation as PodMetricsScrape, on the series pushed to the kubervisor remote-write endpoint
+
+	Baseline *DeviationBaseline `json:"baseline,omitempty"` // Compare to the history of the fleet instead of the current fleet. Only with PrometheusService/PromQL
+}
+
+// DeviationBaseline compares the value of each pod to the past values of the fleet, to detect the regressions of the whole fleet as well as the outlier pods
+// In baseline mode the PromQL returns the value of each pod (ex: rate(solution_price_sum[1m])/rate(solution_price_count[1m])), not its deviation, and the reference is the average over the pods of the same PromQL evaluated in the past
+type DeviationBaseline struct {
+	Offset       string `json:"offset,omitempty"`       // Reference is the fleet average this duration ago, ex: 1d or 1w for the metrics that vary by time of day
+	MedianWindow string `json:"medianWindow,omitempty"` // Reference is the median of the fleet average over this duration, ending Offset ago, ex: 1w
+	MedianStep   string `json:"medianStep,omitempty"`   // Resolution of the median computation. default: 1h
 }
 
 // DiscreteValueOutOfList detect anomaly when the a value is not in the list with a ratio that exceed the tolerance
@@ -129,15 +221,300 @@ type ContinuousValueDeviation struct {
 // 1-the key of the value to monitor
 // 2-the podname
 type DiscreteValueOutOfList struct {
-	PrometheusService    string   `json:"prometheusService"`
-	PromQL               string   `json:"promQL"`               // example: sum(delta(ms_rpc_count{job=\"kubernetes-pods\",run=\"foo\"}[10s])) by (code,kubernetes_pod_name)
-	Key                  string   `json:"key"`                  // Key for the metrics. For the previous example it will be "code"
-	PodNameKey           string   `json:"podNamekey"`           // Key to access the podName
-	GoodValues           []string `json:"goodValues,omitempty"` // Good Values ["200","201"]. If empty means that BadValues should be used to do exclusion instead of inclusion.
-	BadValues            []string `json:"badValues,omitempty"`  // Bad Values ["500","404"].
-	TolerancePercent     *uint    `json:"tolerance"`            // % of Bad values tolerated until the pod is considered out of SLA
-	MinimumActivityCount *uint    `json:"minActivity"`          // Minimum number of event required to perform analysis on the pod
+	PrometheusService    string    `json:"prometheusService"`
+	PromQL               string    `json:"promQL"`               // example: sum(delta(ms_rpc_count{job=\"kubernetes-pods\",run=\"foo\"}[10s])) by (code,kubernetes_pod_name)
+	Key                  string    `json:"key"`                  // Key for the metrics. For the previous example it will be "code"
+	PodNameKey           string    `json:"podNamekey"`           // Key to access the podName
+	PodMatch             *PodMatch `json:"podMatch,omitempty"`   // How the value of the PodNameKey label identifies the pod. default: by pod name
+	GoodValues           []string  `json:"goodValues,omitempty"` // Good Values ["200","201"]. If empty means that BadValues should be used to do exclusion instead of inclusion.
+	BadValues            []string  `json:"badValues,omitempty"`  // Bad Values ["500","404"].
+	TolerancePercent     *uint     `json:"tolerance"`            // % of Bad values tolerated until the pod is considered out of SLA
+	MinimumActivityCount *uint     `json:"minActivity"`          // Minimum number of event required to perform analysis on the pod
+	Confidence           *float64  `json:"confidence,omitempty"` // Optional confidence level in ]0,1[, example: 0.95. The pod is out of SLA only if the lower bound of the Wilson score interval of its bad values ratio exceeds the tolerance, or if its ratio is significantly greater than the ratio of the other pods
+
+	Rules        []DiscreteValueRule `json:"rules,omitempty"`        // Alternative to Key/GoodValues/BadValues: the first rule matching the labels of a sample gives its class
+	DefaultClass DiscreteValueClass  `json:"defaultClass,omitempty"` // Class of the samples matching no rule. default: good
+
+	PodMetricsScrape *PodMetricsScrape  `json:"podMetricsScrape,omitempty"` // Alternative to PrometheusService/PromQL: Metric must be a counter, the delta between two evaluations is used
+	RemoteWrite      *RemoteWriteSource `json:"remoteWrite,omitempty"`      // Alternative to PrometheusService/PromQL: Metric must be a counter, its increase over the window is used
+}
+
+// DiscreteValueRule classifies the samples whose labels match all the matchers
+// A matcher is either a numeric range [min,max], bounds included, or a regular expression that must match the whole label value.
+// example: {"class": "bad", "labels": {"code": "5..", "grpc_status": "[1,16]"}}
+type DiscreteValueRule struct {
+	Class  DiscreteValueClass `json:"class"`  // good, bad or ignored
+	Labels map[string]string  `json:"labels"` // Matcher by label name
+}
+
+// DiscreteValueClass represent the class of a sample: good samples count as ok, bad samples count as ko, ignored samples are not counted
+type DiscreteValueClass string
+
+// DiscreteValueClass defines the possible classes
+const (
+	DiscreteValueClassGood    DiscreteValueClass = "good"
+	DiscreteValueClassBad     DiscreteValueClass = "bad"
+	DiscreteValueClassIgnored DiscreteValueClass = "ignored"
+)
+
+// PodMatch defines how the pods are identified in the metrics of the prometheus source
+// Ingress controllers, service meshes and sidecars usually identify the upstreams by ip:port or by a label rather than by the pod name.
+type PodMatch struct {
+	By        PodMatchBy `json:"by"`                  // name, ip or label
+	StripPort bool       `json:"stripPort,omitempty"` // For the ip match: remove the port of the ip:port values
+	Label     string     `json:"label,omitempty"`     // For the label match: the pod label whose value is in the metric. example: statefulset.kubernetes.io/pod-name
+}
+
+// PodMatchBy represent the pod attribute compared to the metric label value
+type PodMatchBy string
+
+// PodMatchBy defines the possible pod attributes
+const (
+	PodMatchByName  PodMatchBy = "name"
+	PodMatchByIP    PodMatchBy = "ip"
+	PodMatchByLabel PodMatchBy = "label"
+)
+
+// PodMetricsScrape source of metrics read directly on the metrics endpoint of each pod, without prometheus
+// The endpoint must expose the prometheus text or protobuf format
+type PodMetricsScrape struct {
+	Port    int32    `json:"port"`              // Port of the metrics endpoint on the pod IP
+	Path    string   `json:"path,omitempty"`    // Path of the metrics endpoint. default: /metrics
+	Metric  string   `json:"metric"`            // Name of the metric to read. example: ms_rpc_count
+	Timeout *float64 `json:"timeout,omitempty"` // Timeout in seconds of the scrape of one pod
+}
+
+// RemoteWriteSource source of metrics pushed by prometheus agents to the kubervisor remote-write endpoint (/api/v1/write)
+// The series are associated to the pods thanks to the pod labels configured on the controller
+// The endpoint is enabled with the --remote-write flag of the controller and requires a bearer token. The series are kept in memory
+// by the replica that receives them, so the controller must run with a single replica when the endpoint is used.
+type RemoteWriteSource struct {
+	Metric string `json:"metric"`           // Name of the pushed metric. example: ms_rpc_count
+	Window string `json:"window,omitempty"` // Range on which the increase of the counters is computed, prometheus duration format. default: 1m
+}
+
+// LatencyPercentile detect anomaly when the latency percentile of a pod, computed from a prometheus histogram, is above the fleet percentile or above a fixed SLO
+// The queries are built from the histogram definition:
+// 1- per pod: histogram_quantile(<quantile>, sum(rate(<histogramMetric>_bucket{<labelFilter>}[<window>])) by (le,<podNamekey>))
+// 2- fleet: histogram_quantile(<quantile>, sum(rate(<histogramMetric>_bucket{<labelFilter>}[<window>])) by (le))
+type LatencyPercentile struct {
+	PrometheusService string   `json:"prometheusService"`
+	HistogramMetric   string   `json:"histogramMetric"`       // Name of the histogram without the "_bucket" suffix. example: http_request_duration_seconds
+	LabelFilter       string   `json:"labelFilter,omitempty"` // Optional label matchers applied to the histogram. example: job="kubernetes-pods",app="foo"
+	Quantile          *float64 `json:"quantile"`              // Quantile to compute, in ]0,1[. example: 0.99
+	Window            string   `json:"window"`                // Range used for the rate computation. example: 1m
+	PodNameKey        string   `json:"podNamekey"`            // Key to access the podName
+
+	MaxDeviationPercent *float64 `json:"maxDeviationPercent,omitempty"` // Pod is out of SLA when its percentile exceeds the fleet percentile by more than this % of the fleet percentile
+	MaxLatency          *float64 `json:"maxLatency,omitempty"`          // Pod is out of SLA when its percentile exceeds this fixed value (in the unit of the histogram)
+}
+
+// PodStatusAnomaly detect anomaly from the pod status only, no metrics backend is required
+// A pod is out of SLA if during the window:
+// 1- its containers restarted more than MaxRestartCount times
+// 2- or one of its containers terminated with a reason listed in TerminationReasons
+// 3- or its Ready condition changed more than MaxReadyFlipCount times
+type PodStatusAnomaly struct {
+	Window             *float64 `json:"window"`                       // Observation window in seconds
+	MaxRestartCount    *uint    `json:"maxRestartCount"`              // Number of container restarts tolerated during the window
+	TerminationReasons []string `json:"terminationReasons,omitempty"` // Container termination reasons considered as anomalies. example: ["OOMKilled","Error"]
+	MaxReadyFlipCount  *uint    `json:"maxReadyFlipCount"`            // Number of Ready condition transitions tolerated during the window
+}
+
+// PodLogPattern detect anomaly from the logs of the pods, read with the kubernetes pod log API since the previous evaluation
+// The lines matching one of the BadPatterns are counted as bad values, the lines matching one of the GoodPatterns as good values.
+// If GoodPatterns is empty all the lines that don't match a bad pattern are counted as good values.
+// The ratio of bad values is then checked like DiscreteValueOutOfList does.
+type PodLogPattern struct {
+	Container            string   `json:"container,omitempty"`      // Container to read the logs from. Required if the pods have several containers
+	GoodPatterns         []string `json:"goodPatterns,omitempty"`   // Regular expressions of the good lines. example: ["request served"]
+	BadPatterns          []string `json:"badPatterns"`              // Regular expressions of the bad lines. example: ["connection pool exhausted"]
+	TolerancePercent     *uint    `json:"tolerance"`                // % of bad lines tolerated until the pod is considered out of SLA
+	MinimumActivityCount *uint    `json:"minActivity"`              // Minimum number of counted lines required to perform analysis on the pod
+	MaxBytesPerPod       *int64   `json:"maxBytesPerPod,omitempty"` // Maximum number of bytes of logs read per pod at each evaluation
+}
+
+// ActiveProbe detect anomaly by sending at each evaluation a synthetic request directly to the IP of each pod
+// Exactly one of HTTP or GRPC must be defined. A probe fails on error, timeout, unexpected response or latency above MaxLatency.
+// A pod is out of SLA when the % of failed probes among its last Window probes exceeds the tolerance.
+type ActiveProbe struct {
+	Port                int32            `json:"port"`                          // Port of the pod to probe
+	HTTP                *HTTPProbe       `json:"http,omitempty"`                // HTTP request definition
+	GRPC                *GRPCHealthProbe `json:"grpc,omitempty"`                // gRPC health check definition
+	Timeout             *float64         `json:"timeout"`                       // Timeout in seconds of a probe
+	MaxLatency          *float64         `json:"maxLatency,omitempty"`          // Optional latency in seconds above which a successful probe is counted as failed
+	Window              *uint            `json:"window"`                        // Number of last probes of a pod used to compute its failure ratio. The pod is evaluated once Window probes were done
+	TolerancePercent    *uint            `json:"tolerance"`                     // % of failed probes tolerated until the pod is considered out of SLA
+	MaxConcurrentProbes *uint            `json:"maxConcurrentProbes,omitempty"` // Maximum number of pods probed in parallel
+}
+
+// HTTPProbe synthetic HTTP request sent to the pods
+type HTTPProbe struct {
+	Method         string            `json:"method,omitempty"`         // default: GET
+	Path           string            `json:"path,omitempty"`           // default: /
+	Headers        map[string]string `json:"headers,omitempty"`        // Headers of the request
+	Body           string            `json:"body,omitempty"`           // Body of the request
+	ExpectedStatus []int             `json:"expectedStatus,omitempty"` // Expected status codes. If empty any 2xx status is expected
+	BodyRegex      string            `json:"bodyRegex,omitempty"`      // Optional regular expression that the response body must match
+}
+
+// GRPCHealthProbe call of the standard gRPC health checking protocol (grpc.health.v1.Health/Check). The pod must answer SERVING
+type GRPCHealthProbe struct {
+	Service string `json:"service,omitempty"` // Name of the service checked. Empty means the overall server health
+}
+
+// Consensus detect anomaly by sending at each evaluation the same request to each pod and comparing their normalized answers
+// The pods whose answer differs from the majority answer are out of SLA. A pod without answer (failed or timed out request) is not
+// flagged by default: a slow pod or a network issue is not a divergent answer. BreakUnanswered flags these pods too.
+// When the majority answer is given by less than Quorum % of the requested pods there is no clear majority, and no pod is flagged.
+type Consensus struct {
+	Port                  int32     `json:"port"`                            // Port of the pod to request
+	HTTP                  HTTPProbe `json:"http"`                            // HTTP request sent to each pod
+	JSONPaths             []string  `json:"jsonPaths,omitempty"`             // Fields of the JSON answer that are compared, dot separated names and array indexes. example: ["solutions.0.price"]. If empty the whole answer is compared
+	RoundingDigits        *uint     `json:"roundingDigits,omitempty"`        // Optional number of decimals the numbers of the JSON answer are rounded to before comparison
+	QuorumPercent         *uint     `json:"quorum"`                          // Minimum % of requested pods giving the majority answer. Must be above 50
+	Timeout               *float64  `json:"timeout"`                         // Timeout in seconds of a request
+	MaxConcurrentRequests *uint     `json:"maxConcurrentRequests,omitempty"` // Maximum number of pods requested in parallel
+	BreakUnanswered       bool      `json:"breakUnanswered,omitempty"`       // Flag the pods without answer as out of SLA. Off by default
+}
+
+// CustomDetector delegates the anomaly detection to an external service with the v2 protocol
+// At each evaluation the service receives a POST with the managed pods and answers a verdict, a score and a reason for each pod.
+// CustomService is the v1 protocol: a GET that returns the list of faulty pods.
+type CustomDetector struct {
+	URL                string   `json:"url"`                          // URL of the detector, http or https. example: https://detector.default.svc:8443/analyse
+	HeadersSecretName  string   `json:"headersSecretName,omitempty"`  // Optional Secret of the KubervisorService namespace. Each entry is sent as a request header, example: Authorization
+	InsecureSkipVerify bool     `json:"insecureSkipVerify,omitempty"` // Skip the verification of the detector certificate
+	Timeout            *float64 `json:"timeout"`                      // Timeout in seconds of the call
+}
+
+// GRPCService delegates the anomaly detection to an out-of-process plugin implementing the gRPC service of pkg/api/detector/plugin/v1/detector.proto
+// In evaluate mode the plugin is called at each evaluation. In watch mode the plugin pushes its verdicts on a stream, and the breaker is evaluated with the last verdicts each time they are pushed, in addition to every evaluation period.
+type GRPCService struct {
+	Address string          `json:"address"`        // host:port of the plugin. example: detector.default.svc:9000
+	Mode    GRPCServiceMode `json:"mode,omitempty"` // evaluate or watch. default: evaluate
+	TLS     *GRPCServiceTLS `json:"tls,omitempty"`  // TLS configuration. Cleartext HTTP/2 is used if not defined
+	Timeout *float64        `json:"timeout"`        // Timeout in seconds of an Evaluate call
+}
+
+// GRPCServiceMode represent the way the verdicts of a gRPC plugin are retrieved
+type GRPCServiceMode string
+
+// GRPCServiceMode defines the possible modes
+const (
+	GRPCServiceModeEvaluate GRPCServiceMode = "evaluate"
+	GRPCServiceModeWatch    GRPCServiceMode = "watch"
+)
+
+// GRPCServiceTLS TLS configuration of the connection to a gRPC plugin
+type GRPCServiceTLS struct {
+	ServerName         string `json:"serverName,omitempty"`         // Name used to verify the plugin certificate. Default: the host of the address
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // Skip the verification of the plugin certificate
+	SecretName         string `json:"secretName,omitempty"`         // Optional Secret of the KubervisorService namespace with the CA (ca.crt) and the client certificate (tls.crt, tls.key)
+}
+
+// AnomalyReport detect anomaly from the PodAnomalyReport objects of the KubervisorService namespace: a pod is out of bounds while it has an unexpired report matching the filter
+type AnomalyReport struct {
+	Sources     []string                 `json:"sources,omitempty"` // Sources of the reports that are taken into account. If empty all the sources are accepted
+	MinSeverity PodAnomalyReportSeverity `json:"minSeverity"`       // Minimum severity of the reports that are taken into account. default: warning
+}
+
+// Alertmanager detect anomaly from the alerts received on the Alertmanager webhook receiver of the controller (/api/v1/alerts): a pod is out of bounds while a matching alert is firing for it
+// The breaker is evaluated when a notification is received, and every evaluation period to retry the pods not broken yet and to drop the expired alerts.
+// The receiver is enabled with the --alertmanager-webhook flag of the controller and requires a bearer token. The alerts are kept in memory
+// by the replica that receives them, so the controller must run with a single replica when the receiver is used.
+type Alertmanager struct {
+	MatchLabels    map[string]string `json:"matchLabels,omitempty"` // Labels the alert must have. example: {"alertname": "HighErrorRate"}
+	PodLabel       string            `json:"podLabel"`              // Label of the alert that carries the pod name. default: pod
+	NamespaceLabel string            `json:"namespaceLabel"`        // Label of the alert that carries the pod namespace, the alerts of other namespaces are ignored. default: namespace
+}
+
+// MetricsExpression detect anomaly by evaluating a boolean expression for each pod, over the results of several PromQL queries joined by the pod name
+// Each query defines a variable of the expression with the value of the pod. The fleet aggregates of a query <q> are variables too:
+// fleet_avg_<q>, fleet_median_<q>, fleet_min_<q>, fleet_max_<q> and fleet_p<N>_<q> (percentile N, from 1 to 99), computed over the pods with traffic.
+// A pod is out of SLA when the expression is true. Pods missing a value used by the expression are not evaluated.
+// example: errors / requests > 0.05 && requests > 100 || latency > 2 * fleet_p99_latency
+type MetricsExpression struct {
+	PrometheusService string            `json:"prometheusService"`
+	PodNameKey        string            `json:"podNamekey"` // Key to access the podName
+	Queries           map[string]string `json:"queries"`    // PromQL queries by variable name. example: {"errors": "sum(rate(http_requests_total{code=~\"5..\"}[1m])) by (pod)"}
+	Expression        string            `json:"expression"` // Boolean expression with the operators || && ! < <= > >= == != + - * / and parentheses
+}
+
+// DetectorPreset generates a DiscreteValueOutOfList detector for the metrics of a well known proxy: the PromQL, the Key, the PodNameKey and the good or bad values are built from the kind and the workload
+// 1- istioHTTP5xx: istio_requests_total reported by the destination sidecar, the 5xx response codes are bad
+// 2- linkerdSuccessRate: response_total of the inbound linkerd proxy, the failure classification is bad
+// 3- nginxUpstream5xx: nginx_upstream_responses_total of the nginx ingress (vts module), the 5xx responses are bad and the upstream server ip:port is matched with the pod IP
+type DetectorPreset struct {
+	Kind                 DetectorPresetKind `json:"kind"` // istioHTTP5xx, linkerdSuccessRate or nginxUpstream5xx
+	PrometheusService    string             `json:"prometheusService"`
+	Namespace            string             `json:"namespace,omitempty"` // Namespace of the workload. default: namespace of the KubervisorService
+	Workload             string             `json:"workload"`            // Name of the deployment for istio and linkerd, name of the service for nginx
+	Window               string             `json:"window"`              // Range of the increase computation. default: 1m
+	TolerancePercent     *uint              `json:"tolerance"`           // % of Bad values tolerated until the pod is considered out of SLA
+	MinimumActivityCount *uint              `json:"minActivity"`         // Minimum number of event required to perform analysis on the pod
+}
+
+// DetectorPresetKind represent the metrics a DetectorPreset is built for
+type DetectorPresetKind string
+
+// DetectorPresetKind defines the possible kinds
+const (
+	DetectorPresetIstioHTTP5xx       DetectorPresetKind = "istioHTTP5xx"
+	DetectorPresetLinkerdSuccessRate DetectorPresetKind = "linkerdSuccessRate"
+	DetectorPresetNginxUpstream5xx   DetectorPresetKind = "nginxUpstream5xx"
+)
+
+// ResourceUsage detect the pods that consume abnormally CPU or memory, for example stuck in a spin loop or leaking memory, before they return errors
+// The usage of each pod is read from the metrics API (metrics.k8s.io) or, if PrometheusService is defined, from the cAdvisor metrics in prometheus
+// A pod is out of SLA when its usage stays above the thresholds during the SustainedPeriod
+type ResourceUsage struct {
+	Resource api.ResourceName `json:"resource"` // cpu or memory
+
+	MetricsAPIService string `json:"metricsAPIService,omitempty"` // host:port of a server implementing the metrics.k8s.io API. default: the kubernetes API server
+	PrometheusService string `json:"prometheusService,omitempty"` // Read the cAdvisor metrics container_cpu_usage_seconds_total and container_memory_working_set_bytes from prometheus
+	LabelFilter       string `json:"labelFilter,omitempty"`       // Optional label matchers applied to the cAdvisor metrics. example: container="app"
+	PodNameKey        string `json:"podNamekey,omitempty"`        // Key to access the podName in the cAdvisor metrics. default: pod
+
+	MaxFleetRatio   *float64 `json:"maxFleetRatio,omitempty"`   // Pod is out of SLA when its usage exceeds this ratio of the median usage of the fleet. example: 2
+	MaxLimitPercent *uint    `json:"maxLimitPercent,omitempty"` // Pod is out of SLA when its usage exceeds this % of the sum of its containers limits. Ignored for the pods without limits
+	SustainedPeriod *float64 `json:"sustainedPeriod"`           // Period in seconds during which the usage must stay above a threshold
+}
+
+// BurnRateWindowPlaceholder is replaced by the short and the long windows in the BurnRate queries
+const BurnRateWindowPlaceholder = "$window"
+
+// BurnRate detect the pods that burn the error budget of the SLO much faster than allowed, with the multiwindow pattern of the Google SRE workbook
+// The burn rate of a pod over a window is its error ratio (1 - good/total) divided by the error budget (1 - Objective)
+// A pod is out of SLA when its burn rate exceeds MaxBurnRate over both the short and the long windows
+type BurnRate struct {
+	PrometheusService string `json:"prometheusService"`
+	GoodQuery         string `json:"goodQuery"`  // Number of good events by pod over $window. example: sum(increase(http_requests_total{app="foo",code!~"5.."}[$window])) by (pod)
+	TotalQuery        string `json:"totalQuery"` // Number of events by pod over $window. example: sum(increase(http_requests_total{app="foo"}[$window])) by (pod)
+	PodNameKey        string `json:"podNamekey"` // Key to access the podName
+
+	Objective            *float64 `json:"objective"`               // SLO target in ]0,1[. example: 0.999
+	ShortWindow          string   `json:"shortWindow"`             // default: 5m
+	LongWindow           string   `json:"longWindow"`              // default: 1h
+	MaxBurnRate          *float64 `json:"maxBurnRate"`             // default: 14.4, 2% of a 30 days budget consumed in one hour
+	MaxFleetRatio        *float64 `json:"maxFleetRatio,omitempty"` // Pod must also burn the budget this ratio faster than the fleet, in both windows, to be out of SLA
+	MinimumActivityCount *uint    `json:"minActivity"`             // Minimum number of events of the pod over the short window
+}
+
+// FleetGuard detects the service-level incidents, for example the failure of a dependency: when the anomaly is fleet-wide the pods are not to blame and the breaker cuts no pod, as it would amplify the outage
+// The start and the end of an incident are reported with an event on the KubervisorService and the kubervisor_fleet_incident metric
+type FleetGuard struct {
+	MaxFlaggedPodsPercent *uint `json:"maxFlaggedPodsPercent,omitempty"` // Incident when more than this % of the managed pods are out of bounds at once
+	MaxFleetErrorPercent  *uint `json:"maxFleetErrorPercent,omitempty"`  // Incident when the bad values ratio of the whole fleet exceeds this %. Only for the DiscreteValueOutOfList and Preset detectors
+	SetCondition          bool  `json:"setCondition,omitempty"`          // Set the FleetIncident condition of the KubervisorService during the incident
+}
 
+// TopologyMinAvailable keeps a minimum of available pods in each topology domain, the pods sharing the same value for the topology key, on top of the minimum for the whole fleet
+// The topology key is read from the pod labels, then from the labels of the node of the pod: for example failure-domain.beta.kubernetes.io/zone or kubernetes.io/hostname
+type TopologyMinAvailable struct {
+	TopologyKey           string `json:"topologyKey"`
+	MinPodsAvailableCount *uint  `json:"minPodsAvailableCount,omitempty"` // Minimum number of pods with traffic in each domain
+	MinPodsAvailableRatio *uint  `json:"minPodsAvailableRatio,omitempty"` // Minimum % of the pods with traffic in each domain
 }
 
 // ActivatorStrategy contains ActivatorStrategy definition
@@ -2,8 +2,19 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/prometheus/common/model"
+	api "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/expression"
 )
 
 //ValidateKubervisorServiceSpec validate the KubervisorService specification
@@ -31,6 +42,49 @@ func ValidateKubervisorServiceSpec(s KubervisorServiceSpec) error {
 	if err := ValidateActivatorStrategy(s.DefaultActivator); err != nil {
 		return fmt.Errorf("Validation of kubervisor service specification failed for default activator strategy: %v", err)
 	}
+
+	if s.MaxUnavailable != nil {
+		if err := ValidateMaxUnavailable(*s.MaxUnavailable); err != nil {
+			return fmt.Errorf("Validation of kubervisor service specification failed for max unavailable: %v", err)
+		}
+	}
+
+	if s.BreakRateLimit != nil {
+		if err := ValidateBreakRateLimit(*s.BreakRateLimit); err != nil {
+			return fmt.Errorf("Validation of kubervisor service specification failed for break rate limit: %v", err)
+		}
+	}
+	return nil
+}
+
+//ValidateBreakRateLimit validation of input
+func ValidateBreakRateLimit(l BreakRateLimit) error {
+	if l.MaxBreaks == nil || *l.MaxBreaks < 1 {
+		return fmt.Errorf("max breaks undefined or lower than 1")
+	}
+	if l.Period == nil || *l.Period <= 0 {
+		return fmt.Errorf("period undefined or not positive")
+	}
+	return nil
+}
+
+//ValidateMaxUnavailable validation of input
+func ValidateMaxUnavailable(m intstr.IntOrString) error {
+	if m.Type == intstr.String {
+		if !strings.HasSuffix(m.StrVal, "%") {
+			return fmt.Errorf("'%s' is not a percentage", m.StrVal)
+		}
+	}
+	value, err := intstr.GetValueFromIntOrPercent(&m, 100, false)
+	if err != nil {
+		return err
+	}
+	if value < 0 {
+		return fmt.Errorf("must be positive")
+	}
+	if m.Type == intstr.String && value > 100 {
+		return fmt.Errorf("percentage must be in [0%%,100%%]")
+	}
 	return nil
 }
 
@@ -59,9 +113,87 @@ func ValidateBreakerStrategy(s BreakerStrategy) error {
 			return fmt.Errorf("Validation of strategy ContinuousValueDeviation failed: %v", err)
 		}
 	}
+	if s.LatencyPercentile != nil {
+		strategies = append(strategies, "LatencyPercentile")
+		if err := ValidateLatencyPercentile(*s.LatencyPercentile); err != nil {
+			return fmt.Errorf("Validation of strategy LatencyPercentile failed: %v", err)
+		}
+	}
+	if s.PodStatusAnomaly != nil {
+		strategies = append(strategies, "PodStatusAnomaly")
+		if err := ValidatePodStatusAnomaly(*s.PodStatusAnomaly); err != nil {
+			return fmt.Errorf("Validation of strategy PodStatusAnomaly failed: %v", err)
+		}
+	}
+	if s.PodLogPattern != nil {
+		strategies = append(strategies, "PodLogPattern")
+		if err := ValidatePodLogPattern(*s.PodLogPattern); err != nil {
+			return fmt.Errorf("Validation of strategy PodLogPattern failed: %v", err)
+		}
+	}
+	if s.ActiveProbe != nil {
+		strategies = append(strategies, "ActiveProbe")
+		if err := ValidateActiveProbe(*s.ActiveProbe); err != nil {
+			return fmt.Errorf("Validation of strategy ActiveProbe failed: %v", err)
+		}
+	}
+	if s.Consensus != nil {
+		strategies = append(strategies, "Consensus")
+		if err := ValidateConsensus(*s.Consensus); err != nil {
+			return fmt.Errorf("Validation of strategy Consensus failed: %v", err)
+		}
+	}
 	if s.CustomService != "" {
 		strategies = append(strategies, "CustomService")
 	}
+	if s.CustomDetector != nil {
+		strategies = append(strategies, "CustomDetector")
+		if err := ValidateCustomDetector(*s.CustomDetector); err != nil {
+			return fmt.Errorf("Validation of strategy CustomDetector failed: %v", err)
+		}
+	}
+	if s.GRPCService != nil {
+		strategies = append(strategies, "GRPCService")
+		if err := ValidateGRPCService(*s.GRPCService); err != nil {
+			return fmt.Errorf("Validation of strategy GRPCService failed: %v", err)
+		}
+	}
+	if s.AnomalyReport != nil {
+		strategies = append(strategies, "AnomalyReport")
+		if err := ValidateAnomalyReport(*s.AnomalyReport); err != nil {
+			return fmt.Errorf("Validation of strategy AnomalyReport failed: %v", err)
+		}
+	}
+	if s.Alertmanager != nil {
+		strategies = append(strategies, "Alertmanager")
+		if err := ValidateAlertmanager(*s.Alertmanager); err != nil {
+			return fmt.Errorf("Validation of strategy Alertmanager failed: %v", err)
+		}
+	}
+	if s.MetricsExpression != nil {
+		strategies = append(strategies, "MetricsExpression")
+		if err := ValidateMetricsExpression(*s.MetricsExpression); err != nil {
+			return fmt.Errorf("Validation of strategy MetricsExpression failed: %v", err)
+		}
+	}
+	if s.Preset != nil {
+		strategies = append(strategies, "Preset")
+		if err := ValidateDetectorPreset(*s.Preset); err != nil {
+			return fmt.Errorf("Validation of strategy Preset failed: %v", err)
+		}
+	}
+	if s.ResourceUsage != nil {
+		strategies = append(strategies, "ResourceUsage")
+		if err := ValidateResourceUsage(*s.ResourceUsage); err != nil {
+			return fmt.Errorf("Validation of strategy ResourceUsage failed: %v", err)
+		}
+	}
+	if s.BurnRate != nil {
+		strategies = append(strategies, "BurnRate")
+		if err := ValidateBurnRate(*s.BurnRate); err != nil {
+			return fmt.Errorf("Validation of strategy BurnRate failed: %v", err)
+		}
+	}
 
 	if len(strategies) == 0 {
 		return fmt.Errorf("BreakerStrategy is missing anomaly detection specification (DiscreteValueOutOfList or CustomService or ...)")
@@ -79,6 +211,21 @@ func ValidateBreakerStrategy(s BreakerStrategy) error {
 		return fmt.Errorf("BreakerStrategy evaluation period undefined or too big (more than 1 day)")
 	}
 
+	if s.MinPodsAvailablePerTopology != nil {
+		if err := ValidateTopologyMinAvailable(*s.MinPodsAvailablePerTopology); err != nil {
+			return fmt.Errorf("BreakerStrategy min pods available per topology is invalid: %v", err)
+		}
+	}
+
+	if s.FleetGuard != nil {
+		if err := ValidateFleetGuard(*s.FleetGuard); err != nil {
+			return fmt.Errorf("BreakerStrategy fleet guard is invalid: %v", err)
+		}
+		if s.FleetGuard.MaxFleetErrorPercent != nil && s.DiscreteValueOutOfList == nil && s.Preset == nil {
+			return fmt.Errorf("BreakerStrategy fleet guard max fleet error is only supported by the DiscreteValueOutOfList and Preset strategies")
+		}
+	}
+
 	if s.Activator != nil {
 		if err := ValidateActivatorStrategy(*s.Activator); err != nil {
 			return fmt.Errorf("BreakerStrategy activator is invalid: %v", err)
@@ -88,32 +235,95 @@ func ValidateBreakerStrategy(s BreakerStrategy) error {
 	return nil
 }
 
+//ValidateTopologyMinAvailable validation of input
+func ValidateTopologyMinAvailable(t TopologyMinAvailable) error {
+	if errs := utilvalidation.IsQualifiedName(t.TopologyKey); len(errs) != 0 {
+		return fmt.Errorf("bad topology key '%s': %v", t.TopologyKey, errs[0])
+	}
+	if t.MinPodsAvailableCount == nil && t.MinPodsAvailableRatio == nil {
+		return fmt.Errorf("missing min pods available count or ratio")
+	}
+	if t.MinPodsAvailableRatio != nil && *t.MinPodsAvailableRatio > 100 {
+		return fmt.Errorf("min pods available ratio must be in [0,100]")
+	}
+	return nil
+}
+
+//ValidateFleetGuard validation of input
+func ValidateFleetGuard(g FleetGuard) error {
+	if g.MaxFlaggedPodsPercent == nil && g.MaxFleetErrorPercent == nil {
+		return fmt.Errorf("missing max flagged pods percent or max fleet error percent")
+	}
+	if g.MaxFlaggedPodsPercent != nil && *g.MaxFlaggedPodsPercent > 100 {
+		return fmt.Errorf("max flagged pods percent must be in [0,100]")
+	}
+	if g.MaxFleetErrorPercent != nil && *g.MaxFleetErrorPercent > 100 {
+		return fmt.Errorf("max fleet error percent must be in [0,100]")
+	}
+	return nil
+}
+
 //ValidateDiscreteValueOutOfList validation of input
 func ValidateDiscreteValueOutOfList(d DiscreteValueOutOfList) error {
 	good, bad := d.GoodValues, d.BadValues
-	if len(good) == 0 && len(bad) == 0 {
-		return fmt.Errorf("no good nor bad value defined")
-	}
-	if len(good) != 0 && len(bad) != 0 {
-		return fmt.Errorf("good and bad value defined, only good values will be used to do inclusion")
+	if len(d.Rules) != 0 {
+		if len(good) != 0 || len(bad) != 0 {
+			return fmt.Errorf("rules and good or bad values are exclusive")
+		}
+		if err := validateDiscreteValueRules(d.Rules, d.DefaultClass); err != nil {
+			return err
+		}
+	} else {
+		if len(good) == 0 && len(bad) == 0 {
+			return fmt.Errorf("no good nor bad value defined")
+		}
+		if len(good) != 0 && len(bad) != 0 {
+			return fmt.Errorf("good and bad value defined, only good values will be used to do inclusion")
+		}
+
+		if len(d.Key) == 0 {
+			return fmt.Errorf("missing metric Key definition")
+		}
 	}
 
-	if len(d.Key) == 0 {
-		return fmt.Errorf("missing metric Key definition")
+	if d.PodMatch != nil {
+		if d.PromQL == "" && d.PrometheusService == "" {
+			return fmt.Errorf("pod match is only supported with the prometheus source")
+		}
+		if err := ValidatePodMatch(*d.PodMatch); err != nil {
+			return err
+		}
 	}
 
-	if len(d.PodNameKey) == 0 {
-		return fmt.Errorf("missing PodName Key definition")
+	if d.Confidence != nil && (*d.Confidence <= 0 || *d.Confidence >= 1) {
+		return fmt.Errorf("confidence must be defined in ]0,1[")
 	}
 
 	switch {
 	case d.PromQL != "" || d.PrometheusService != "":
+		if d.PodMetricsScrape != nil || d.RemoteWrite != nil {
+			return fmt.Errorf("prometheus, pod metrics scrape and remote write sources are exclusive")
+		}
+		if len(d.PodNameKey) == 0 {
+			return fmt.Errorf("missing PodName Key definition")
+		}
 		if d.PrometheusService == "" {
 			return fmt.Errorf("missing Prometheus service")
 		}
 		if d.PromQL == "" {
 			return fmt.Errorf("missing PromQL")
 		}
+	case d.PodMetricsScrape != nil:
+		if d.RemoteWrite != nil {
+			return fmt.Errorf("pod metrics scrape and remote write sources are exclusive")
+		}
+		if err := ValidatePodMetricsScrape(*d.PodMetricsScrape); err != nil {
+			return err
+		}
+	case d.RemoteWrite != nil:
+		if err := ValidateRemoteWriteSource(*d.RemoteWrite); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("missing parameter to create DiscreteValueOutOfListAnalyser")
 	}
@@ -122,24 +332,609 @@ func ValidateDiscreteValueOutOfList(d DiscreteValueOutOfList) error {
 
 //ValidateContinuousValueDeviation validation of input
 func ValidateContinuousValueDeviation(d ContinuousValueDeviation) error {
-	if len(d.PodNameKey) == 0 {
-		return fmt.Errorf("missing PodName Key definition")
-	}
-
 	if d.MaxDeviationPercent == nil {
 		return fmt.Errorf("missing Max Deviation percent")
 	}
 
+	if d.PodMatch != nil {
+		if d.PromQL == "" && d.PrometheusService == "" {
+			return fmt.Errorf("pod match is only supported with the prometheus source")
+		}
+		if err := ValidatePodMatch(*d.PodMatch); err != nil {
+			return err
+		}
+	}
+
 	switch {
 	case d.PromQL != "" || d.PrometheusService != "":
+		if d.PodMetricsScrape != nil || d.RemoteWrite != nil {
+			return fmt.Errorf("prometheus, pod metrics scrape and remote write sources are exclusive")
+		}
+		if len(d.PodNameKey) == 0 {
+			return fmt.Errorf("missing PodName Key definition")
+		}
 		if d.PrometheusService == "" {
 			return fmt.Errorf("missing Prometheus service")
 		}
 		if d.PromQL == "" {
 			return fmt.Errorf("missing PromQL")
 		}
+	case d.PodMetricsScrape != nil:
+		if d.RemoteWrite != nil {
+			return fmt.Errorf("pod metrics scrape and remote write sources are exclusive")
+		}
+		if err := ValidatePodMetricsScrape(*d.PodMetricsScrape); err != nil {
+			return err
+		}
+	case d.RemoteWrite != nil:
+		if err := ValidateRemoteWriteSource(*d.RemoteWrite); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("missing parameter to create DiscreteValueOutOfListAnalyser")
 	}
+
+	if d.Baseline != nil {
+		if d.PromQL == "" {
+			return fmt.Errorf("baseline is only supported with the prometheus source")
+		}
+		if err := ValidateDeviationBaseline(*d.Baseline); err != nil {
+			return fmt.Errorf("invalid baseline: %v", err)
+		}
+	}
+	return nil
+}
+
+//ValidateDeviationBaseline validation of input
+func ValidateDeviationBaseline(b DeviationBaseline) error {
+	if b.Offset == "" && b.MedianWindow == "" {
+		return fmt.Errorf("missing offset or median window")
+	}
+	if b.Offset != "" {
+		if _, err := model.ParseDuration(b.Offset); err != nil {
+			return fmt.Errorf("invalid offset '%s': %v", b.Offset, err)
+		}
+	}
+	if b.MedianWindow == "" {
+		return nil
+	}
+	window, err := model.ParseDuration(b.MedianWindow)
+	if err != nil {
+		return fmt.Errorf("invalid median window '%s': %v", b.MedianWindow, err)
+	}
+	step, err := model.ParseDuration(b.MedianStep)
+	if err != nil {
+		return fmt.Errorf("invalid median step '%s': %v", b.MedianStep, err)
+	}
+	if step == 0 || step > window {
+		return fmt.Errorf("median step must be in ]0,%s]", b.MedianWindow)
+	}
+	// prometheus refuses the range queries of more than 11000 points
+	if window/step > 11000 {
+		return fmt.Errorf("median step too small for the median window")
+	}
+	return nil
+}
+
+func validateDiscreteValueRules(rules []DiscreteValueRule, defaultClass DiscreteValueClass) error {
+	if defaultClass != "" && !isValidDiscreteValueClass(defaultClass) {
+		return fmt.Errorf("unknown default class %q", defaultClass)
+	}
+	for i, rule := range rules {
+		if !isValidDiscreteValueClass(rule.Class) {
+			return fmt.Errorf("rule %d: unknown class %q", i, rule.Class)
+		}
+		if len(rule.Labels) == 0 {
+			return fmt.Errorf("rule %d: missing labels", i)
+		}
+		for name, matcher := range rule.Labels {
+			if !model.LabelName(name).IsValid() {
+				return fmt.Errorf("rule %d: invalid label name %q", i, name)
+			}
+			if _, err := CompileValueMatcher(matcher); err != nil {
+				return fmt.Errorf("rule %d: label %s: %v", i, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func isValidDiscreteValueClass(c DiscreteValueClass) bool {
+	switch c {
+	case DiscreteValueClassGood, DiscreteValueClassBad, DiscreteValueClassIgnored:
+		return true
+	}
+	return false
+}
+
+// CompileValueMatcher compiles a matcher of a DiscreteValueRule: a numeric range [min,max] or a regular expression matching the whole value
+func CompileValueMatcher(matcher string) (func(value string) bool, error) {
+	if strings.HasPrefix(matcher, "[") && strings.HasSuffix(matcher, "]") {
+		bounds := strings.Split(strings.TrimSuffix(strings.TrimPrefix(matcher, "["), "]"), ",")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid range %q", matcher)
+		}
+		min, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %v", matcher, err)
+		}
+		max, err := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %v", matcher, err)
+		}
+		if min > max {
+			return nil, fmt.Errorf("invalid range %q: min is greater than max", matcher)
+		}
+		return func(value string) bool {
+			v, err := strconv.ParseFloat(value, 64)
+			return err == nil && v >= min && v <= max
+		}, nil
+	}
+	re, err := regexp.Compile("^(?:" + matcher + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %v", matcher, err)
+	}
+	return re.MatchString, nil
+}
+
+//ValidatePodMatch validation of input
+func ValidatePodMatch(m PodMatch) error {
+	switch m.By {
+	case PodMatchByName, PodMatchByIP:
+		if m.Label != "" {
+			return fmt.Errorf("pod match label is only used to match by label")
+		}
+	case PodMatchByLabel:
+		if errs := utilvalidation.IsQualifiedName(m.Label); len(errs) != 0 {
+			return fmt.Errorf("invalid pod match label %q: %s", m.Label, errs[0])
+		}
+	default:
+		return fmt.Errorf("unknown pod match %q", m.By)
+	}
+	if m.StripPort && m.By != PodMatchByIP {
+		return fmt.Errorf("pod match port stripping is only used to match by ip")
+	}
+	return nil
+}
+
+//ValidateLatencyPercentile validation of input
+func ValidateLatencyPercentile(d LatencyPercentile) error {
+	if d.PrometheusService == "" {
+		return fmt.Errorf("missing Prometheus service")
+	}
+	if d.HistogramMetric == "" {
+		return fmt.Errorf("missing histogram metric name")
+	}
+	if len(d.PodNameKey) == 0 {
+		return fmt.Errorf("missing PodName Key definition")
+	}
+	if d.Quantile == nil || *d.Quantile <= 0 || *d.Quantile >= 1 {
+		return fmt.Errorf("quantile must be defined in ]0,1[")
+	}
+	if _, err := model.ParseDuration(d.Window); err != nil {
+		return fmt.Errorf("invalid window '%s': %v", d.Window, err)
+	}
+	if d.MaxDeviationPercent == nil && d.MaxLatency == nil {
+		return fmt.Errorf("missing Max Deviation percent or Max Latency")
+	}
+	if d.MaxDeviationPercent != nil && d.MaxLatency != nil {
+		return fmt.Errorf("Max Deviation percent and Max Latency are exclusive")
+	}
+	if d.MaxDeviationPercent != nil && *d.MaxDeviationPercent <= 0 {
+		return fmt.Errorf("Max Deviation percent must be positive")
+	}
+	if d.MaxLatency != nil && *d.MaxLatency <= 0 {
+		return fmt.Errorf("Max Latency must be positive")
+	}
+	return nil
+}
+
+//ValidatePodStatusAnomaly validation of input
+func ValidatePodStatusAnomaly(d PodStatusAnomaly) error {
+	if d.Window == nil || *d.Window <= 0 {
+		return fmt.Errorf("missing or invalid window")
+	}
+	if d.MaxRestartCount == nil {
+		return fmt.Errorf("missing Max Restart count")
+	}
+	if d.MaxReadyFlipCount == nil {
+		return fmt.Errorf("missing Max Ready flip count")
+	}
+	return nil
+}
+
+//ValidatePodLogPattern validation of input
+func ValidatePodLogPattern(d PodLogPattern) error {
+	if len(d.BadPatterns) == 0 {
+		return fmt.Errorf("no bad pattern defined")
+	}
+	for _, pattern := range append(append([]string{}, d.GoodPatterns...), d.BadPatterns...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	if d.TolerancePercent == nil {
+		return fmt.Errorf("missing tolerance")
+	}
+	if d.MinimumActivityCount == nil {
+		return fmt.Errorf("missing minimum activity")
+	}
+	if d.MaxBytesPerPod == nil || *d.MaxBytesPerPod <= 0 {
+		return fmt.Errorf("missing or invalid max bytes per pod")
+	}
+	return nil
+}
+
+//ValidateActiveProbe validation of input
+func ValidateActiveProbe(d ActiveProbe) error {
+	if d.Port <= 0 || d.Port > 65535 {
+		return fmt.Errorf("invalid probe port %d", d.Port)
+	}
+	switch {
+	case d.HTTP != nil && d.GRPC != nil:
+		return fmt.Errorf("http and grpc probes are exclusive")
+	case d.HTTP != nil:
+		if err := validateHTTPProbe(*d.HTTP); err != nil {
+			return err
+		}
+	case d.GRPC == nil:
+		return fmt.Errorf("missing http or grpc probe definition")
+	}
+	if d.Timeout == nil || *d.Timeout <= 0 {
+		return fmt.Errorf("missing or invalid timeout")
+	}
+	if d.MaxLatency != nil && *d.MaxLatency <= 0 {
+		return fmt.Errorf("max latency must be positive")
+	}
+	if d.Window == nil || *d.Window == 0 {
+		return fmt.Errorf("missing or invalid window")
+	}
+	if d.TolerancePercent == nil {
+		return fmt.Errorf("missing tolerance")
+	}
+	if d.MaxConcurrentProbes == nil || *d.MaxConcurrentProbes == 0 {
+		return fmt.Errorf("missing or invalid max concurrent probes")
+	}
+	return nil
+}
+
+//ValidateConsensus validation of input
+func ValidateConsensus(d Consensus) error {
+	if d.Port <= 0 || d.Port > 65535 {
+		return fmt.Errorf("invalid consensus port %d", d.Port)
+	}
+	if err := validateHTTPProbe(d.HTTP); err != nil {
+		return err
+	}
+	for _, path := range d.JSONPaths {
+		for _, field := range strings.Split(path, ".") {
+			if field == "" {
+				return fmt.Errorf("invalid json path %q", path)
+			}
+		}
+	}
+	if d.QuorumPercent == nil || *d.QuorumPercent <= 50 || *d.QuorumPercent > 100 {
+		return fmt.Errorf("missing or invalid quorum, it must be between 51 and 100")
+	}
+	if d.Timeout == nil || *d.Timeout <= 0 {
+		return fmt.Errorf("missing or invalid timeout")
+	}
+	if d.MaxConcurrentRequests == nil || *d.MaxConcurrentRequests == 0 {
+		return fmt.Errorf("missing or invalid max concurrent requests")
+	}
+	return nil
+}
+
+//ValidateCustomDetector validation of input
+func ValidateCustomDetector(d CustomDetector) error {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("the url scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in url")
+	}
+	if d.Timeout == nil || *d.Timeout <= 0 {
+		return fmt.Errorf("missing or invalid timeout")
+	}
+	return nil
+}
+
+//ValidateGRPCService validation of input
+func ValidateGRPCService(d GRPCService) error {
+	host, port, err := net.SplitHostPort(d.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address: %v", err)
+	}
+	if host == "" {
+		return fmt.Errorf("missing host in address")
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return fmt.Errorf("invalid port in address")
+	}
+	switch d.Mode {
+	case GRPCServiceModeEvaluate, GRPCServiceModeWatch:
+	default:
+		return fmt.Errorf("unknown mode %q", d.Mode)
+	}
+	if d.Timeout == nil || *d.Timeout <= 0 {
+		return fmt.Errorf("missing or invalid timeout")
+	}
+	return nil
+}
+
+//ValidateAnomalyReport validation of input
+func ValidateAnomalyReport(d AnomalyReport) error {
+	for _, source := range d.Sources {
+		if source == "" {
+			return fmt.Errorf("empty source")
+		}
+	}
+	if !isValidPodAnomalyReportSeverity(d.MinSeverity) {
+		return fmt.Errorf("unknown minimum severity %q", d.MinSeverity)
+	}
+	return nil
+}
+
+//ValidateAlertmanager validation of input
+func ValidateAlertmanager(d Alertmanager) error {
+	if d.PodLabel == "" {
+		return fmt.Errorf("missing pod label")
+	}
+	if d.NamespaceLabel == "" {
+		return fmt.Errorf("missing namespace label")
+	}
+	for k := range d.MatchLabels {
+		if !model.LabelName(k).IsValid() {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+	}
+	return nil
+}
+
+//ValidateMetricsExpression validation of input, the expression is compiled and type-checked
+func ValidateMetricsExpression(d MetricsExpression) error {
+	if d.PrometheusService == "" {
+		return fmt.Errorf("missing Prometheus service")
+	}
+	if len(d.PodNameKey) == 0 {
+		return fmt.Errorf("missing PodName Key definition")
+	}
+	if len(d.Queries) == 0 {
+		return fmt.Errorf("missing queries")
+	}
+	for name, q := range d.Queries {
+		if !queryNameRegexp.MatchString(name) || strings.HasPrefix(name, fleetVariablePrefix) || name == "true" || name == "false" {
+			return fmt.Errorf("invalid query name %q", name)
+		}
+		if q == "" {
+			return fmt.Errorf("empty query %q", name)
+		}
+	}
+	if _, err := CompileMetricsExpression(d); err != nil {
+		return fmt.Errorf("invalid expression: %v", err)
+	}
+	return nil
+}
+
+var queryNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+const fleetVariablePrefix = "fleet_"
+
+// FleetVariable aggregate of a query over the fleet of pods, used as a variable of a MetricsExpression
+type FleetVariable struct {
+	Aggregate  string  // avg, median, min, max or percentile
+	Percentile float64 // in ]0,1[ for the percentile aggregate
+	Query      string  // name of the query
+}
+
+// ParseFleetVariable parses the name of a fleet variable: fleet_<aggregate>_<query>
+func ParseFleetVariable(name string, queries map[string]string) (FleetVariable, bool) {
+	if !strings.HasPrefix(name, fleetVariablePrefix) {
+		return FleetVariable{}, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(name, fleetVariablePrefix), "_", 2)
+	if len(parts) != 2 {
+		return FleetVariable{}, false
+	}
+	if _, ok := queries[parts[1]]; !ok {
+		return FleetVariable{}, false
+	}
+	v := FleetVariable{Aggregate: parts[0], Query: parts[1]}
+	switch v.Aggregate {
+	case "avg", "median", "min", "max":
+		return v, true
+	}
+	if !strings.HasPrefix(v.Aggregate, "p") {
+		return FleetVariable{}, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(v.Aggregate, "p"))
+	if err != nil || n < 1 || n > 99 {
+		return FleetVariable{}, false
+	}
+	v.Aggregate = "percentile"
+	v.Percentile = float64(n) / 100
+	return v, true
+}
+
+// CompileMetricsExpression compiles the expression of a MetricsExpression, the variables are the queries and their fleet aggregates
+func CompileMetricsExpression(d MetricsExpression) (*expression.Expression, error) {
+	return expression.Compile(d.Expression, func(name string) bool {
+		if _, ok := d.Queries[name]; ok {
+			return true
+		}
+		_, ok := ParseFleetVariable(name, d.Queries)
+		return ok
+	})
+}
+
+//ValidateDetectorPreset validation of input
+func ValidateDetectorPreset(d DetectorPreset) error {
+	switch d.Kind {
+	case DetectorPresetIstioHTTP5xx, DetectorPresetLinkerdSuccessRate, DetectorPresetNginxUpstream5xx:
+	default:
+		return fmt.Errorf("unknown preset kind %q", d.Kind)
+	}
+	if d.PrometheusService == "" {
+		return fmt.Errorf("missing Prometheus service")
+	}
+	if d.Workload == "" {
+		return fmt.Errorf("missing workload")
+	}
+	// the names are inserted in the PromQL, they must be DNS-1123 labels
+	if errs := utilvalidation.IsDNS1123Label(d.Workload); len(errs) != 0 {
+		return fmt.Errorf("invalid workload '%s': %s", d.Workload, errs[0])
+	}
+	if d.Namespace != "" {
+		if errs := utilvalidation.IsDNS1123Label(d.Namespace); len(errs) != 0 {
+			return fmt.Errorf("invalid namespace '%s': %s", d.Namespace, errs[0])
+		}
+	}
+	if _, err := model.ParseDuration(d.Window); err != nil {
+		return fmt.Errorf("invalid window '%s': %v", d.Window, err)
+	}
+	if d.TolerancePercent == nil {
+		return fmt.Errorf("missing tolerance")
+	}
+	if d.MinimumActivityCount == nil {
+		return fmt.Errorf("missing minimum activity")
+	}
+	return nil
+}
+
+//ValidateResourceUsage validation of input
+func ValidateResourceUsage(r ResourceUsage) error {
+	if r.Resource != api.ResourceCPU && r.Resource != api.ResourceMemory {
+		return fmt.Errorf("resource must be %s or %s", api.ResourceCPU, api.ResourceMemory)
+	}
+	if r.MetricsAPIService != "" && r.PrometheusService != "" {
+		return fmt.Errorf("metrics API and prometheus sources are exclusive")
+	}
+	if r.PrometheusService == "" && (r.LabelFilter != "" || r.PodNameKey != "") {
+		return fmt.Errorf("label filter and PodName Key are only supported with the prometheus source")
+	}
+	if r.MaxFleetRatio == nil && r.MaxLimitPercent == nil {
+		return fmt.Errorf("missing max fleet ratio or max limit percent")
+	}
+	if r.MaxFleetRatio != nil && *r.MaxFleetRatio <= 1 {
+		return fmt.Errorf("max fleet ratio must be greater than 1")
+	}
+	if r.MaxLimitPercent != nil && (*r.MaxLimitPercent == 0 || *r.MaxLimitPercent > 100) {
+		return fmt.Errorf("max limit percent must be in ]0,100]")
+	}
+	if r.SustainedPeriod == nil || *r.SustainedPeriod < 0 {
+		return fmt.Errorf("sustained period undefined or negative")
+	}
+	return nil
+}
+
+//ValidateBurnRate validation of input
+func ValidateBurnRate(b BurnRate) error {
+	if b.PrometheusService == "" {
+		return fmt.Errorf("missing Prometheus service")
+	}
+	if !strings.Contains(b.GoodQuery, BurnRateWindowPlaceholder) {
+		return fmt.Errorf("the good query must contain the %s placeholder", BurnRateWindowPlaceholder)
+	}
+	if !strings.Contains(b.TotalQuery, BurnRateWindowPlaceholder) {
+		return fmt.Errorf("the total query must contain the %s placeholder", BurnRateWindowPlaceholder)
+	}
+	if len(b.PodNameKey) == 0 {
+		return fmt.Errorf("missing PodName Key definition")
+	}
+	if b.Objective == nil || *b.Objective <= 0 || *b.Objective >= 1 {
+		return fmt.Errorf("objective must be defined in ]0,1[")
+	}
+	short, err := model.ParseDuration(b.ShortWindow)
+	if err != nil {
+		return fmt.Errorf("invalid short window '%s': %v", b.ShortWindow, err)
+	}
+	long, err := model.ParseDuration(b.LongWindow)
+	if err != nil {
+		return fmt.Errorf("invalid long window '%s': %v", b.LongWindow, err)
+	}
+	if short >= long {
+		return fmt.Errorf("the short window must be shorter than the long window")
+	}
+	if b.MaxBurnRate == nil || *b.MaxBurnRate <= 0 {
+		return fmt.Errorf("max burn rate undefined or not positive")
+	}
+	if b.MaxFleetRatio != nil && *b.MaxFleetRatio <= 1 {
+		return fmt.Errorf("max fleet ratio must be greater than 1")
+	}
+	if b.MinimumActivityCount == nil {
+		return fmt.Errorf("missing minimum activity")
+	}
+	return nil
+}
+
+//ValidatePodAnomalyReportSpec validation of input
+func ValidatePodAnomalyReportSpec(s PodAnomalyReportSpec) error {
+	if s.PodName == "" {
+		return fmt.Errorf("missing pod name")
+	}
+	if s.Source == "" {
+		return fmt.Errorf("missing source")
+	}
+	if !isValidPodAnomalyReportSeverity(s.Severity) {
+		return fmt.Errorf("unknown severity %q", s.Severity)
+	}
+	if s.Expiry.IsZero() {
+		return fmt.Errorf("missing expiry")
+	}
+	return nil
+}
+
+func isValidPodAnomalyReportSeverity(s PodAnomalyReportSeverity) bool {
+	switch s {
+	case PodAnomalyReportSeverityInfo, PodAnomalyReportSeverityWarning, PodAnomalyReportSeverityCritical:
+		return true
+	}
+	return false
+}
+
+func validateHTTPProbe(d HTTPProbe) error {
+	if d.Path != "" && !strings.HasPrefix(d.Path, "/") {
+		return fmt.Errorf("the http probe path must start with /")
+	}
+	for _, status := range d.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("invalid expected status %d", status)
+		}
+	}
+	if _, err := regexp.Compile(d.BodyRegex); err != nil {
+		return fmt.Errorf("invalid body regex: %v", err)
+	}
+	return nil
+}
+
+//ValidatePodMetricsScrape validation of input
+func ValidatePodMetricsScrape(d PodMetricsScrape) error {
+	if d.Port <= 0 || d.Port > 65535 {
+		return fmt.Errorf("invalid pod metrics scrape port %d", d.Port)
+	}
+	if d.Metric == "" {
+		return fmt.Errorf("missing pod metrics scrape metric name")
+	}
+	if d.Path == "" {
+		return fmt.Errorf("missing pod metrics scrape path")
+	}
+	if d.Timeout == nil || *d.Timeout <= 0 {
+		return fmt.Errorf("pod metrics scrape timeout undefined or not positive")
+	}
+	return nil
+}
+
+//ValidateRemoteWriteSource validation of input
+func ValidateRemoteWriteSource(d RemoteWriteSource) error {
+	if d.Metric == "" {
+		return fmt.Errorf("missing remote write metric name")
+	}
+	if _, err := model.ParseDuration(d.Window); err != nil {
+		return fmt.Errorf("invalid remote write window: %v", err)
+	}
 	return nil
 }
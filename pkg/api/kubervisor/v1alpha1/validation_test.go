@@ -2,6 +2,10 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func Test_validateBreakerStrategy(t *testing.T) {
@@ -216,172 +220,1216 @@ func Test_validateBreakerStrategy(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "CustomService",
-			s: BreakerStrategy{
-				Name:          "avalidname",
-				CustomService: "Custo",
-			},
-			wantErr: false,
-		},
-		{
-			name: "Activator",
+			name: "DiscreteValueOutOfList pod scrape",
 			s: BreakerStrategy{
-				Name:          "avalidname",
-				CustomService: "Custo",
-				Activator:     &ActivatorStrategy{},
+				Name: "avalidname",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					Key:              "code",
+					GoodValues:       []string{"200"},
+					PodMetricsScrape: DefaultPodMetricsScrape(&PodMetricsScrape{Port: 8080, Metric: "rpc_count"}),
+				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "Small EvaluationPeriod",
+			name: "DiscreteValueOutOfList pod scrape missing port",
 			s: BreakerStrategy{
-				Name:             "avalidname",
-				EvaluationPeriod: NewFloat64(0.005),
-				CustomService:    "Custo",
-				Activator:        &ActivatorStrategy{},
+				Name: "avalidname",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					Key:              "code",
+					GoodValues:       []string{"200"},
+					PodMetricsScrape: DefaultPodMetricsScrape(&PodMetricsScrape{Metric: "rpc_count"}),
+				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "Large EvaluationPeriod",
+			name: "DiscreteValueOutOfList pod scrape not defaulted",
 			s: BreakerStrategy{
-				Name:             "avalidname",
-				EvaluationPeriod: NewFloat64(5 * 24 * 3600),
-				CustomService:    "Custo",
-				Activator:        &ActivatorStrategy{},
+				Name: "avalidname",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					Key:              "code",
+					GoodValues:       []string{"200"},
+					PodMetricsScrape: &PodMetricsScrape{Port: 8080, Metric: "rpc_count"},
+				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "to much",
+			name: "DiscreteValueOutOfList pod scrape and prometheus",
 			s: BreakerStrategy{
-				Name:          "avalidname",
-				CustomService: "Custo",
+				Name: "avalidname",
 				DiscreteValueOutOfList: &DiscreteValueOutOfList{
-					PromQL:            "fake query",
-					PrometheusService: "svc",
-					BadValues:         []string{"1"},
 					Key:               "code",
+					GoodValues:        []string{"200"},
+					PromQL:            "query",
+					PrometheusService: "service",
 					PodNameKey:        "podname",
+					PodMetricsScrape:  DefaultPodMetricsScrape(&PodMetricsScrape{Port: 8080, Metric: "rpc_count"}),
 				},
 			},
 			wantErr: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := ValidateBreakerStrategy(tt.s); (err != nil) != tt.wantErr {
-				t.Errorf("validateBreakerStrategy() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestValidateKubervisorServiceSpec(t *testing.T) {
-	type args struct {
-		s KubervisorServiceSpec
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-	}{
 		{
-			name: "ok",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          "servicefine",
+			name: "DiscreteValueOutOfList remote write",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					Key:         "code",
+					GoodValues:  []string{"200"},
+					RemoteWrite: DefaultRemoteWriteSource(&RemoteWriteSource{Metric: "rpc_count"}),
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "noname",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "", CustomService: "Custo"})},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          "servicefine",
+			name: "DiscreteValueOutOfList remote write bad window",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					Key:         "code",
+					GoodValues:  []string{"200"},
+					RemoteWrite: &RemoteWriteSource{Metric: "rpc_count", Window: "1 minute"},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "dupe names",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers: []BreakerStrategy{
-						*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"}),
-						*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname-other", CustomService: "Custo"}),
-						*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"}),
-					},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          "servicefine",
+			name: "DiscreteValueOutOfList remote write and pod scrape",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					Key:              "code",
+					GoodValues:       []string{"200"},
+					PodMetricsScrape: DefaultPodMetricsScrape(&PodMetricsScrape{Port: 8080, Metric: "rpc_count"}),
+					RemoteWrite:      DefaultRemoteWriteSource(&RemoteWriteSource{Metric: "rpc_count"}),
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "noservice",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+			name: "ContinuousValueDeviation pod scrape",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				ContinuousValueDeviation: &ContinuousValueDeviation{
+					MaxDeviationPercent: NewFloat64(50.0),
+					PodMetricsScrape:    DefaultPodMetricsScrape(&PodMetricsScrape{Port: 8080, Metric: "price"}),
 				},
 			},
+			wantErr: false,
+		},
+		{
+			name: "LatencyPercentile empty",
+			s: BreakerStrategy{
+				Name:              "avalidname",
+				LatencyPercentile: &LatencyPercentile{},
+			},
 			wantErr: true,
 		},
 		{
-			name: "badservice",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          ";^%$#))(",
+			name: "LatencyPercentile complete",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				LatencyPercentile: &LatencyPercentile{
+					PrometheusService:   "service",
+					HistogramMetric:     "rpc_duration_seconds",
+					PodNameKey:          "pod",
+					Quantile:            NewFloat64(0.99),
+					Window:              "1m",
+					MaxDeviationPercent: NewFloat64(50.0),
 				},
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "nobreaker",
-			args: args{
-				s: KubervisorServiceSpec{
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          "servicefine",
+			name: "LatencyPercentile bad quantile",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				LatencyPercentile: &LatencyPercentile{
+					PrometheusService:   "service",
+					HistogramMetric:     "rpc_duration_seconds",
+					PodNameKey:          "pod",
+					Quantile:            NewFloat64(99),
+					Window:              "1m",
+					MaxDeviationPercent: NewFloat64(50.0),
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "emptybreakers",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers:         []BreakerStrategy{},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          "servicefine",
+			name: "LatencyPercentile bad window",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				LatencyPercentile: &LatencyPercentile{
+					PrometheusService: "service",
+					HistogramMetric:   "rpc_duration_seconds",
+					PodNameKey:        "pod",
+					Quantile:          NewFloat64(0.99),
+					Window:            "1 minute",
+					MaxLatency:        NewFloat64(0.5),
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "badbreakers",
-			args: args{
-				s: KubervisorServiceSpec{
-					Breakers:         []BreakerStrategy{BreakerStrategy{}},
-					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
-					Service:          "servicefine",
+			name: "LatencyPercentile deviation and SLO",
+			s: BreakerStrategy{
+				Name: "avalidname",
+				LatencyPercentile: &LatencyPercentile{
+					PrometheusService:   "service",
+					HistogramMetric:     "rpc_duration_seconds",
+					PodNameKey:          "pod",
+					Quantile:            NewFloat64(0.99),
+					Window:              "1m",
+					MaxDeviationPercent: NewFloat64(50.0),
+					MaxLatency:          NewFloat64(0.5),
 				},
 			},
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := ValidateKubervisorServiceSpec(tt.args.s); (err != nil) != tt.wantErr {
-				t.Errorf("ValidateKubervisorServiceSpec() error = %v, wantErr %v", err, tt.wantErr)
+		{
+			name: "PodStatusAnomaly empty",
+			s: BreakerStrategy{
+				Name:             "avalidname",
+				PodStatusAnomaly: &PodStatusAnomaly{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "PodStatusAnomaly defaulted",
+			s: BreakerStrategy{
+				Name:             "avalidname",
+				PodStatusAnomaly: DefaultPodStatusAnomaly(&PodStatusAnomaly{}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "PodLogPattern empty",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				PodLogPattern: &PodLogPattern{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "PodLogPattern defaulted",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				PodLogPattern: DefaultPodLogPattern(&PodLogPattern{BadPatterns: []string{"pool exhausted"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "PodLogPattern bad regexp",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				PodLogPattern: DefaultPodLogPattern(&PodLogPattern{BadPatterns: []string{"pool (exhausted"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ActiveProbe http",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				ActiveProbe: DefaultActiveProbe(&ActiveProbe{Port: 8080, HTTP: &HTTPProbe{Path: "/health", ExpectedStatus: []int{200}, BodyRegex: "UP"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ActiveProbe grpc",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				ActiveProbe: DefaultActiveProbe(&ActiveProbe{Port: 8080, GRPC: &GRPCHealthProbe{Service: "foo"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ActiveProbe http and grpc",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				ActiveProbe: DefaultActiveProbe(&ActiveProbe{Port: 8080, HTTP: &HTTPProbe{}, GRPC: &GRPCHealthProbe{}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ActiveProbe missing probe",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				ActiveProbe: DefaultActiveProbe(&ActiveProbe{Port: 8080}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ActiveProbe bad status",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				ActiveProbe: DefaultActiveProbe(&ActiveProbe{Port: 8080, HTTP: &HTTPProbe{ExpectedStatus: []int{2000}}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ActiveProbe missing port",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				ActiveProbe: DefaultActiveProbe(&ActiveProbe{HTTP: &HTTPProbe{}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Consensus",
+			s: BreakerStrategy{
+				Name:      "avalidname",
+				Consensus: DefaultConsensus(&Consensus{Port: 8080, JSONPaths: []string{"solutions.0.price"}, RoundingDigits: NewUInt(2)}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Consensus quorum too low",
+			s: BreakerStrategy{
+				Name:      "avalidname",
+				Consensus: DefaultConsensus(&Consensus{Port: 8080, QuorumPercent: NewUInt(50)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Consensus invalid json path",
+			s: BreakerStrategy{
+				Name:      "avalidname",
+				Consensus: DefaultConsensus(&Consensus{Port: 8080, JSONPaths: []string{"solutions..price"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Consensus missing port",
+			s: BreakerStrategy{
+				Name:      "avalidname",
+				Consensus: DefaultConsensus(&Consensus{}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "CustomDetector",
+			s: BreakerStrategy{
+				Name:           "avalidname",
+				CustomDetector: DefaultCustomDetector(&CustomDetector{URL: "https://detector:8443/analyse", HeadersSecretName: "auth"}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "CustomDetector bad scheme",
+			s: BreakerStrategy{
+				Name:           "avalidname",
+				CustomDetector: DefaultCustomDetector(&CustomDetector{URL: "ftp://detector"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "CustomDetector missing host",
+			s: BreakerStrategy{
+				Name:           "avalidname",
+				CustomDetector: DefaultCustomDetector(&CustomDetector{URL: "http:///analyse"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "CustomDetector and CustomService",
+			s: BreakerStrategy{
+				Name:           "avalidname",
+				CustomService:  "Custo",
+				CustomDetector: DefaultCustomDetector(&CustomDetector{URL: "http://detector"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "GRPCService",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				GRPCService: DefaultGRPCService(&GRPCService{Address: "detector:9000", Mode: GRPCServiceModeWatch}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "GRPCService missing port",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				GRPCService: DefaultGRPCService(&GRPCService{Address: "detector"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "GRPCService invalid port",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				GRPCService: DefaultGRPCService(&GRPCService{Address: "detector:http"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "GRPCService unknown mode",
+			s: BreakerStrategy{
+				Name:        "avalidname",
+				GRPCService: DefaultGRPCService(&GRPCService{Address: "detector:9000", Mode: "poll"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "AnomalyReport",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				AnomalyReport: DefaultAnomalyReport(&AnomalyReport{Sources: []string{"lb", "synthetic"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "AnomalyReport empty source",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				AnomalyReport: DefaultAnomalyReport(&AnomalyReport{Sources: []string{""}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "AnomalyReport unknown severity",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				AnomalyReport: &AnomalyReport{MinSeverity: "fatal"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Alertmanager",
+			s: BreakerStrategy{
+				Name:         "avalidname",
+				Alertmanager: DefaultAlertmanager(&Alertmanager{MatchLabels: map[string]string{"alertname": "HighErrorRate"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Alertmanager invalid label name",
+			s: BreakerStrategy{
+				Name:         "avalidname",
+				Alertmanager: DefaultAlertmanager(&Alertmanager{MatchLabels: map[string]string{"alert-name": "HighErrorRate"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Alertmanager missing pod label",
+			s: BreakerStrategy{
+				Name:         "avalidname",
+				Alertmanager: &Alertmanager{NamespaceLabel: "namespace"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MetricsExpression",
+			s: BreakerStrategy{
+				Name:              "avalidname",
+				MetricsExpression: &MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: map[string]string{"errors": "e", "requests": "r"}, Expression: "errors / requests > 0.05 && errors > fleet_p99_errors"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "MetricsExpression unknown variable",
+			s: BreakerStrategy{
+				Name:              "avalidname",
+				MetricsExpression: &MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: map[string]string{"errors": "e"}, Expression: "errors / requests > 0.05"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MetricsExpression not a bool",
+			s: BreakerStrategy{
+				Name:              "avalidname",
+				MetricsExpression: &MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: map[string]string{"errors": "e"}, Expression: "errors * 2"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MetricsExpression invalid query name",
+			s: BreakerStrategy{
+				Name:              "avalidname",
+				MetricsExpression: &MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Queries: map[string]string{"fleet_errors": "e"}, Expression: "fleet_errors > 1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MetricsExpression missing queries",
+			s: BreakerStrategy{
+				Name:              "avalidname",
+				MetricsExpression: &MetricsExpression{PrometheusService: "prometheus:9090", PodNameKey: "pod", Expression: "true"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList match by ip",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", Key: "code", PodNameKey: "upstream_addr", GoodValues: []string{"200"}, PodMatch: &PodMatch{By: PodMatchByIP, StripPort: true}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ContinuousValueDeviation match by label",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "instance", MaxDeviationPercent: NewFloat64(10), PodMatch: &PodMatch{By: PodMatchByLabel, Label: "statefulset.kubernetes.io/pod-name"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ContinuousValueDeviation baseline offset",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{Offset: "1d"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ContinuousValueDeviation baseline median",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{Offset: "1d", MedianWindow: "1w"}}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ContinuousValueDeviation baseline empty",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ContinuousValueDeviation baseline invalid offset",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{Offset: "1 day"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ContinuousValueDeviation baseline median step too big",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{MedianWindow: "1h", MedianStep: "1d"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ContinuousValueDeviation baseline median step too small",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", MaxDeviationPercent: NewFloat64(10), Baseline: &DeviationBaseline{MedianWindow: "1w", MedianStep: "10s"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ContinuousValueDeviation baseline pod scrape",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PodMetricsScrape: &PodMetricsScrape{Port: 8080, Metric: "solution_price"}, Baseline: &DeviationBaseline{Offset: "1d"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ContinuousValueDeviation match by label missing label",
+			s: BreakerStrategy{
+				Name:                     "avalidname",
+				ContinuousValueDeviation: DefaultContinuousValueDeviation(&ContinuousValueDeviation{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "instance", MaxDeviationPercent: NewFloat64(10), PodMatch: &PodMatch{By: PodMatchByLabel}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList match unknown",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", Key: "code", PodNameKey: "pod", GoodValues: []string{"200"}, PodMatch: &PodMatch{By: "uid"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList strip port by name",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", Key: "code", PodNameKey: "pod", GoodValues: []string{"200"}, PodMatch: &PodMatch{By: PodMatchByName, StripPort: true}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList match without prometheus",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{Key: "code", GoodValues: []string{"200"}, PodMetricsScrape: &PodMetricsScrape{Port: 8080, Metric: "ms_rpc_count"}, PodMatch: &PodMatch{By: PodMatchByIP}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Preset",
+			s: BreakerStrategy{
+				Name:   "avalidname",
+				Preset: DefaultDetectorPreset(&DetectorPreset{Kind: DetectorPresetLinkerdSuccessRate, PrometheusService: "prometheus:9090", Workload: "foo"}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Preset unknown kind",
+			s: BreakerStrategy{
+				Name:   "avalidname",
+				Preset: DefaultDetectorPreset(&DetectorPreset{Kind: "haproxy", PrometheusService: "prometheus:9090", Workload: "foo"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Preset invalid workload",
+			s: BreakerStrategy{
+				Name:   "avalidname",
+				Preset: DefaultDetectorPreset(&DetectorPreset{Kind: DetectorPresetIstioHTTP5xx, PrometheusService: "prometheus:9090", Workload: `foo"}`}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Preset invalid namespace",
+			s: BreakerStrategy{
+				Name:   "avalidname",
+				Preset: DefaultDetectorPreset(&DetectorPreset{Kind: DetectorPresetNginxUpstream5xx, PrometheusService: "prometheus:9090", Workload: "foo", Namespace: "ns.*"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Preset invalid window",
+			s: BreakerStrategy{
+				Name:   "avalidname",
+				Preset: DefaultDetectorPreset(&DetectorPreset{Kind: DetectorPresetIstioHTTP5xx, PrometheusService: "prometheus:9090", Workload: "foo", Window: "1 minute"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList rules",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", Rules: []DiscreteValueRule{{Class: DiscreteValueClassBad, Labels: map[string]string{"code": "5..", "grpc_status": "[1,16]"}}}, DefaultClass: DiscreteValueClassIgnored}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "DiscreteValueOutOfList rules and good values",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", GoodValues: []string{"200"}, Rules: []DiscreteValueRule{{Class: DiscreteValueClassBad, Labels: map[string]string{"code": "5.."}}}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList rules invalid range",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", Rules: []DiscreteValueRule{{Class: DiscreteValueClassBad, Labels: map[string]string{"code": "[599,500]"}}}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList rules invalid regex",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", Rules: []DiscreteValueRule{{Class: DiscreteValueClassBad, Labels: map[string]string{"code": "5(.."}}}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList rules unknown class",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", PodNameKey: "pod", Rules: []DiscreteValueRule{{Class: "ugly", Labels: map[string]string{"code": "5.."}}}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "DiscreteValueOutOfList confidence",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", Key: "code", PodNameKey: "pod", GoodValues: []string{"200"}, Confidence: NewFloat64(0.95)}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "DiscreteValueOutOfList invalid confidence",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", Key: "code", PodNameKey: "pod", GoodValues: []string{"200"}, Confidence: NewFloat64(95)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ResourceUsage metrics API",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "cpu"}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ResourceUsage prometheus",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "memory", PrometheusService: "prometheus:9090", LabelFilter: `container="app"`, MaxLimitPercent: NewUInt(90)}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ResourceUsage unknown resource",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "storage"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ResourceUsage exclusive sources",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "cpu", MetricsAPIService: "metrics-server:443", PrometheusService: "prometheus:9090"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ResourceUsage label filter without prometheus",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "cpu", LabelFilter: `container="app"`}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ResourceUsage invalid fleet ratio",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "cpu", MaxFleetRatio: NewFloat64(0.5)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ResourceUsage invalid limit percent",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: DefaultResourceUsage(&ResourceUsage{Resource: "cpu", MaxLimitPercent: NewUInt(120)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ResourceUsage missing sustained period",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				ResourceUsage: &ResourceUsage{Resource: "cpu", MaxFleetRatio: NewFloat64(2)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "BurnRate",
+			s: BreakerStrategy{
+				Name:     "avalidname",
+				BurnRate: DefaultBurnRate(&BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[$window]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: NewFloat64(0.999)}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "BurnRate missing objective",
+			s: BreakerStrategy{
+				Name:     "avalidname",
+				BurnRate: DefaultBurnRate(&BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[$window]", TotalQuery: "total[$window]", PodNameKey: "pod"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "BurnRate invalid objective",
+			s: BreakerStrategy{
+				Name:     "avalidname",
+				BurnRate: DefaultBurnRate(&BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[$window]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: NewFloat64(99.9)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "BurnRate windows inverted",
+			s: BreakerStrategy{
+				Name:     "avalidname",
+				BurnRate: DefaultBurnRate(&BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[$window]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: NewFloat64(0.999), ShortWindow: "1h", LongWindow: "5m"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "BurnRate invalid fleet ratio",
+			s: BreakerStrategy{
+				Name:     "avalidname",
+				BurnRate: DefaultBurnRate(&BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[$window]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: NewFloat64(0.999), MaxFleetRatio: NewFloat64(1)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "BurnRate missing window placeholder",
+			s: BreakerStrategy{
+				Name:     "avalidname",
+				BurnRate: DefaultBurnRate(&BurnRate{PrometheusService: "prometheus:9090", GoodQuery: "good[5m]", TotalQuery: "total[$window]", PodNameKey: "pod", Objective: NewFloat64(0.999)}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "FleetGuard",
+			s: BreakerStrategy{
+				Name:                   "avalidname",
+				DiscreteValueOutOfList: DefaultDiscreteValueOutOfList(&DiscreteValueOutOfList{PromQL: "foo", PrometheusService: "prometheus:9090", Key: "code", PodNameKey: "pod", GoodValues: []string{"200"}}),
+				FleetGuard:             &FleetGuard{MaxFlaggedPodsPercent: NewUInt(50), MaxFleetErrorPercent: NewUInt(20), SetCondition: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "FleetGuard empty",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				CustomService: "Custo",
+				FleetGuard:    &FleetGuard{SetCondition: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "FleetGuard invalid percent",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				CustomService: "Custo",
+				FleetGuard:    &FleetGuard{MaxFlaggedPodsPercent: NewUInt(150)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "FleetGuard fleet error without discrete values",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				CustomService: "Custo",
+				FleetGuard:    &FleetGuard{MaxFleetErrorPercent: NewUInt(20)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MinPodsAvailablePerTopology",
+			s: BreakerStrategy{
+				Name:                        "avalidname",
+				CustomService:               "Custo",
+				MinPodsAvailablePerTopology: &TopologyMinAvailable{TopologyKey: "failure-domain.beta.kubernetes.io/zone", MinPodsAvailableCount: NewUInt(1), MinPodsAvailableRatio: NewUInt(50)},
+			},
+			wantErr: false,
+		},
+		{
+			name: "MinPodsAvailablePerTopology bad key",
+			s: BreakerStrategy{
+				Name:                        "avalidname",
+				CustomService:               "Custo",
+				MinPodsAvailablePerTopology: &TopologyMinAvailable{TopologyKey: "bad key", MinPodsAvailableCount: NewUInt(1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MinPodsAvailablePerTopology missing minimum",
+			s: BreakerStrategy{
+				Name:                        "avalidname",
+				CustomService:               "Custo",
+				MinPodsAvailablePerTopology: &TopologyMinAvailable{TopologyKey: "zone"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MinPodsAvailablePerTopology invalid ratio",
+			s: BreakerStrategy{
+				Name:                        "avalidname",
+				CustomService:               "Custo",
+				MinPodsAvailablePerTopology: &TopologyMinAvailable{TopologyKey: "zone", MinPodsAvailableRatio: NewUInt(150)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CustomService",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				CustomService: "Custo",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Activator",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				CustomService: "Custo",
+				Activator:     &ActivatorStrategy{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Small EvaluationPeriod",
+			s: BreakerStrategy{
+				Name:             "avalidname",
+				EvaluationPeriod: NewFloat64(0.005),
+				CustomService:    "Custo",
+				Activator:        &ActivatorStrategy{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Large EvaluationPeriod",
+			s: BreakerStrategy{
+				Name:             "avalidname",
+				EvaluationPeriod: NewFloat64(5 * 24 * 3600),
+				CustomService:    "Custo",
+				Activator:        &ActivatorStrategy{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "to much",
+			s: BreakerStrategy{
+				Name:          "avalidname",
+				CustomService: "Custo",
+				DiscreteValueOutOfList: &DiscreteValueOutOfList{
+					PromQL:            "fake query",
+					PrometheusService: "svc",
+					BadValues:         []string{"1"},
+					Key:               "code",
+					PodNameKey:        "podname",
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBreakerStrategy(tt.s); (err != nil) != tt.wantErr {
+				t.Errorf("validateBreakerStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func intOrStringPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
+
+func TestValidateKubervisorServiceSpec(t *testing.T) {
+	type args struct {
+		s KubervisorServiceSpec
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "noname",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dupe names",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers: []BreakerStrategy{
+						*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"}),
+						*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname-other", CustomService: "Custo"}),
+						*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"}),
+					},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "noservice",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "badservice",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          ";^%$#))(",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max unavailable count",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					MaxUnavailable:   intOrStringPtr(intstr.FromInt(2)),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "max unavailable percent",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					MaxUnavailable:   intOrStringPtr(intstr.FromString("25%")),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "max unavailable not a percent",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					MaxUnavailable:   intOrStringPtr(intstr.FromString("25")),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max unavailable negative",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					MaxUnavailable:   intOrStringPtr(intstr.FromInt(-1)),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max unavailable over 100%",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					MaxUnavailable:   intOrStringPtr(intstr.FromString("150%")),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "break rate limit",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					BreakRateLimit:   &BreakRateLimit{MaxBreaks: NewUInt(3), Period: NewFloat64(300)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "break rate limit missing max breaks",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					BreakRateLimit:   &BreakRateLimit{Period: NewFloat64(300)},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "break rate limit zero max breaks",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					BreakRateLimit:   &BreakRateLimit{MaxBreaks: NewUInt(0), Period: NewFloat64(300)},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "break rate limit bad period",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{*DefaultBreakerStrategy(&BreakerStrategy{Name: "aname", CustomService: "Custo"})},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+					BreakRateLimit:   &BreakRateLimit{MaxBreaks: NewUInt(3), Period: NewFloat64(0)},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "nobreaker",
+			args: args{
+				s: KubervisorServiceSpec{
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "emptybreakers",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "badbreakers",
+			args: args{
+				s: KubervisorServiceSpec{
+					Breakers:         []BreakerStrategy{BreakerStrategy{}},
+					DefaultActivator: *DefaultActivatorStrategy(&ActivatorStrategy{}),
+					Service:          "servicefine",
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateKubervisorServiceSpec(tt.args.s); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKubervisorServiceSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePodAnomalyReportSpec(t *testing.T) {
+	expiry := metav1.NewTime(time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name    string
+		s       PodAnomalyReportSpec
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			s:       PodAnomalyReportSpec{PodName: "foo-1", Source: "lb", Severity: PodAnomalyReportSeverityCritical, Reason: "5xx", Expiry: expiry},
+			wantErr: false,
+		},
+		{
+			name:    "missing pod name",
+			s:       PodAnomalyReportSpec{Source: "lb", Severity: PodAnomalyReportSeverityCritical, Expiry: expiry},
+			wantErr: true,
+		},
+		{
+			name:    "missing source",
+			s:       PodAnomalyReportSpec{PodName: "foo-1", Severity: PodAnomalyReportSeverityCritical, Expiry: expiry},
+			wantErr: true,
+		},
+		{
+			name:    "unknown severity",
+			s:       PodAnomalyReportSpec{PodName: "foo-1", Source: "lb", Severity: "fatal", Expiry: expiry},
+			wantErr: true,
+		},
+		{
+			name:    "missing expiry",
+			s:       PodAnomalyReportSpec{PodName: "foo-1", Source: "lb", Severity: PodAnomalyReportSeverityInfo},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePodAnomalyReportSpec(tt.s); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePodAnomalyReportSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFleetVariable(t *testing.T) {
+	queries := map[string]string{"errors": "e", "p99_latency": "l"}
+	tests := []struct {
+		name   string
+		want   FleetVariable
+		wantOk bool
+	}{
+		{name: "fleet_avg_errors", want: FleetVariable{Aggregate: "avg", Query: "errors"}, wantOk: true},
+		{name: "fleet_median_errors", want: FleetVariable{Aggregate: "median", Query: "errors"}, wantOk: true},
+		{name: "fleet_p95_errors", want: FleetVariable{Aggregate: "percentile", Percentile: 0.95, Query: "errors"}, wantOk: true},
+		{name: "fleet_max_p99_latency", want: FleetVariable{Aggregate: "max", Query: "p99_latency"}, wantOk: true},
+		{name: "fleet_p100_errors", wantOk: false},
+		{name: "fleet_sum_errors", wantOk: false},
+		{name: "fleet_avg_unknown", wantOk: false},
+		{name: "errors", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseFleetVariable(tt.name, queries)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseFleetVariable() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFleetVariable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileValueMatcher(t *testing.T) {
+	tests := []struct {
+		matcher string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{matcher: "200", value: "200", want: true},
+		{matcher: "200", value: "2000", want: false},
+		{matcher: "5..", value: "503", want: true},
+		{matcher: "5..|429", value: "429", want: true},
+		{matcher: "[500,599]", value: "503", want: true},
+		{matcher: "[500, 599]", value: "599", want: true},
+		{matcher: "[500,599]", value: "600", want: false},
+		{matcher: "[500,599]", value: "OK", want: false},
+		{matcher: "[0.5,1]", value: "0.75", want: true},
+		{matcher: "[500]", wantErr: true},
+		{matcher: "[a,599]", wantErr: true},
+		{matcher: "(", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.matcher+" "+tt.value, func(t *testing.T) {
+			match, err := CompileValueMatcher(tt.matcher)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompileValueMatcher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := match(tt.value); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.value, got, tt.want)
 			}
 		})
 	}
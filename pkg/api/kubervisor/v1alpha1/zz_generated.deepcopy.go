@@ -30,6 +30,7 @@ package v1alpha1
 
 import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -75,6 +76,163 @@ func (in *ActivatorStrategy) DeepCopy() *ActivatorStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveProbe) DeepCopyInto(out *ActiveProbe) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HTTPProbe)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GRPCHealthProbe)
+			**out = **in
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MaxLatency != nil {
+		in, out := &in.MaxLatency, &out.MaxLatency
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.TolerancePercent != nil {
+		in, out := &in.TolerancePercent, &out.TolerancePercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.MaxConcurrentProbes != nil {
+		in, out := &in.MaxConcurrentProbes, &out.MaxConcurrentProbes
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveProbe.
+func (in *ActiveProbe) DeepCopy() *ActiveProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Alertmanager) DeepCopyInto(out *Alertmanager) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Alertmanager.
+func (in *Alertmanager) DeepCopy() *Alertmanager {
+	if in == nil {
+		return nil
+	}
+	out := new(Alertmanager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnomalyReport) DeepCopyInto(out *AnomalyReport) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnomalyReport.
+func (in *AnomalyReport) DeepCopy() *AnomalyReport {
+	if in == nil {
+		return nil
+	}
+	out := new(AnomalyReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BreakRateLimit) DeepCopyInto(out *BreakRateLimit) {
+	*out = *in
+	if in.MaxBreaks != nil {
+		in, out := &in.MaxBreaks, &out.MaxBreaks
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BreakRateLimit.
+func (in *BreakRateLimit) DeepCopy() *BreakRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(BreakRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BreakerStrategy) DeepCopyInto(out *BreakerStrategy) {
 	*out = *in
@@ -105,81 +263,1012 @@ func (in *BreakerStrategy) DeepCopyInto(out *BreakerStrategy) {
 			**out = **in
 		}
 	}
+	if in.MinPodsAvailablePerTopology != nil {
+		in, out := &in.MinPodsAvailablePerTopology, &out.MinPodsAvailablePerTopology
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(TopologyMinAvailable)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.DiscreteValueOutOfList != nil {
 		in, out := &in.DiscreteValueOutOfList, &out.DiscreteValueOutOfList
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(DiscreteValueOutOfList)
-			(*in).DeepCopyInto(*out)
+			*out = new(DiscreteValueOutOfList)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ContinuousValueDeviation != nil {
+		in, out := &in.ContinuousValueDeviation, &out.ContinuousValueDeviation
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ContinuousValueDeviation)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LatencyPercentile != nil {
+		in, out := &in.LatencyPercentile, &out.LatencyPercentile
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(LatencyPercentile)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PodStatusAnomaly != nil {
+		in, out := &in.PodStatusAnomaly, &out.PodStatusAnomaly
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodStatusAnomaly)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PodLogPattern != nil {
+		in, out := &in.PodLogPattern, &out.PodLogPattern
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodLogPattern)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ActiveProbe != nil {
+		in, out := &in.ActiveProbe, &out.ActiveProbe
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ActiveProbe)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Consensus != nil {
+		in, out := &in.Consensus, &out.Consensus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Consensus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.CustomDetector != nil {
+		in, out := &in.CustomDetector, &out.CustomDetector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CustomDetector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GRPCService != nil {
+		in, out := &in.GRPCService, &out.GRPCService
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GRPCService)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AnomalyReport != nil {
+		in, out := &in.AnomalyReport, &out.AnomalyReport
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AnomalyReport)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Alertmanager != nil {
+		in, out := &in.Alertmanager, &out.Alertmanager
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Alertmanager)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.MetricsExpression != nil {
+		in, out := &in.MetricsExpression, &out.MetricsExpression
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(MetricsExpression)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Preset != nil {
+		in, out := &in.Preset, &out.Preset
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DetectorPreset)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ResourceUsage != nil {
+		in, out := &in.ResourceUsage, &out.ResourceUsage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ResourceUsage)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.BurnRate != nil {
+		in, out := &in.BurnRate, &out.BurnRate
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BurnRate)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.FleetGuard != nil {
+		in, out := &in.FleetGuard, &out.FleetGuard
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(FleetGuard)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Activator != nil {
+		in, out := &in.Activator, &out.Activator
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ActivatorStrategy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BreakerStrategy.
+func (in *BreakerStrategy) DeepCopy() *BreakerStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(BreakerStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BurnRate) DeepCopyInto(out *BurnRate) {
+	*out = *in
+	if in.Objective != nil {
+		in, out := &in.Objective, &out.Objective
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MaxBurnRate != nil {
+		in, out := &in.MaxBurnRate, &out.MaxBurnRate
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MaxFleetRatio != nil {
+		in, out := &in.MaxFleetRatio, &out.MaxFleetRatio
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MinimumActivityCount != nil {
+		in, out := &in.MinimumActivityCount, &out.MinimumActivityCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BurnRate.
+func (in *BurnRate) DeepCopy() *BurnRate {
+	if in == nil {
+		return nil
+	}
+	out := new(BurnRate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Consensus) DeepCopyInto(out *Consensus) {
+	*out = *in
+	in.HTTP.DeepCopyInto(&out.HTTP)
+	if in.JSONPaths != nil {
+		in, out := &in.JSONPaths, &out.JSONPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RoundingDigits != nil {
+		in, out := &in.RoundingDigits, &out.RoundingDigits
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.QuorumPercent != nil {
+		in, out := &in.QuorumPercent, &out.QuorumPercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MaxConcurrentRequests != nil {
+		in, out := &in.MaxConcurrentRequests, &out.MaxConcurrentRequests
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Consensus.
+func (in *Consensus) DeepCopy() *Consensus {
+	if in == nil {
+		return nil
+	}
+	out := new(Consensus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContinuousValueDeviation) DeepCopyInto(out *ContinuousValueDeviation) {
+	*out = *in
+	if in.PodMatch != nil {
+		in, out := &in.PodMatch, &out.PodMatch
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodMatch)
+			**out = **in
+		}
+	}
+	if in.MaxDeviationPercent != nil {
+		in, out := &in.MaxDeviationPercent, &out.MaxDeviationPercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.PodMetricsScrape != nil {
+		in, out := &in.PodMetricsScrape, &out.PodMetricsScrape
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodMetricsScrape)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RemoteWriteSource)
+			**out = **in
+		}
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DeviationBaseline)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContinuousValueDeviation.
+func (in *ContinuousValueDeviation) DeepCopy() *ContinuousValueDeviation {
+	if in == nil {
+		return nil
+	}
+	out := new(ContinuousValueDeviation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDetector) DeepCopyInto(out *CustomDetector) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDetector.
+func (in *CustomDetector) DeepCopy() *CustomDetector {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDetector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DetectorPreset) DeepCopyInto(out *DetectorPreset) {
+	*out = *in
+	if in.TolerancePercent != nil {
+		in, out := &in.TolerancePercent, &out.TolerancePercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.MinimumActivityCount != nil {
+		in, out := &in.MinimumActivityCount, &out.MinimumActivityCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DetectorPreset.
+func (in *DetectorPreset) DeepCopy() *DetectorPreset {
+	if in == nil {
+		return nil
+	}
+	out := new(DetectorPreset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviationBaseline) DeepCopyInto(out *DeviationBaseline) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviationBaseline.
+func (in *DeviationBaseline) DeepCopy() *DeviationBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviationBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscreteValueOutOfList) DeepCopyInto(out *DiscreteValueOutOfList) {
+	*out = *in
+	if in.PodMatch != nil {
+		in, out := &in.PodMatch, &out.PodMatch
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodMatch)
+			**out = **in
+		}
+	}
+	if in.GoodValues != nil {
+		in, out := &in.GoodValues, &out.GoodValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BadValues != nil {
+		in, out := &in.BadValues, &out.BadValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TolerancePercent != nil {
+		in, out := &in.TolerancePercent, &out.TolerancePercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.MinimumActivityCount != nil {
+		in, out := &in.MinimumActivityCount, &out.MinimumActivityCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.Confidence != nil {
+		in, out := &in.Confidence, &out.Confidence
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]DiscreteValueRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodMetricsScrape != nil {
+		in, out := &in.PodMetricsScrape, &out.PodMetricsScrape
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodMetricsScrape)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RemoteWriteSource)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscreteValueOutOfList.
+func (in *DiscreteValueOutOfList) DeepCopy() *DiscreteValueOutOfList {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscreteValueOutOfList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscreteValueRule) DeepCopyInto(out *DiscreteValueRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscreteValueRule.
+func (in *DiscreteValueRule) DeepCopy() *DiscreteValueRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscreteValueRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetGuard) DeepCopyInto(out *FleetGuard) {
+	*out = *in
+	if in.MaxFlaggedPodsPercent != nil {
+		in, out := &in.MaxFlaggedPodsPercent, &out.MaxFlaggedPodsPercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.MaxFleetErrorPercent != nil {
+		in, out := &in.MaxFleetErrorPercent, &out.MaxFleetErrorPercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetGuard.
+func (in *FleetGuard) DeepCopy() *FleetGuard {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetGuard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetVariable) DeepCopyInto(out *FleetVariable) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetVariable.
+func (in *FleetVariable) DeepCopy() *FleetVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCHealthProbe) DeepCopyInto(out *GRPCHealthProbe) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCHealthProbe.
+func (in *GRPCHealthProbe) DeepCopy() *GRPCHealthProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCHealthProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCService) DeepCopyInto(out *GRPCService) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GRPCServiceTLS)
+			**out = **in
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCService.
+func (in *GRPCService) DeepCopy() *GRPCService {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCServiceTLS) DeepCopyInto(out *GRPCServiceTLS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCServiceTLS.
+func (in *GRPCServiceTLS) DeepCopy() *GRPCServiceTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCServiceTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProbe) DeepCopyInto(out *HTTPProbe) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExpectedStatus != nil {
+		in, out := &in.ExpectedStatus, &out.ExpectedStatus
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProbe.
+func (in *HTTPProbe) DeepCopy() *HTTPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubervisorService) DeepCopyInto(out *KubervisorService) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorService.
+func (in *KubervisorService) DeepCopy() *KubervisorService {
+	if in == nil {
+		return nil
+	}
+	out := new(KubervisorService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubervisorService) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubervisorServiceCondition) DeepCopyInto(out *KubervisorServiceCondition) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceCondition.
+func (in *KubervisorServiceCondition) DeepCopy() *KubervisorServiceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(KubervisorServiceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubervisorServiceList) DeepCopyInto(out *KubervisorServiceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubervisorService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceList.
+func (in *KubervisorServiceList) DeepCopy() *KubervisorServiceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubervisorServiceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubervisorServiceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubervisorServiceSpec) DeepCopyInto(out *KubervisorServiceSpec) {
+	*out = *in
+	if in.Breakers != nil {
+		in, out := &in.Breakers, &out.Breakers
+		*out = make([]BreakerStrategy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.DefaultActivator.DeepCopyInto(&out.DefaultActivator)
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(intstr.IntOrString)
+			**out = **in
+		}
+	}
+	if in.BreakRateLimit != nil {
+		in, out := &in.BreakRateLimit, &out.BreakRateLimit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BreakRateLimit)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceSpec.
+func (in *KubervisorServiceSpec) DeepCopy() *KubervisorServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubervisorServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubervisorServiceStatus) DeepCopyInto(out *KubervisorServiceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]KubervisorServiceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.PodCounts != nil {
+		in, out := &in.PodCounts, &out.PodCounts
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodCountStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceStatus.
+func (in *KubervisorServiceStatus) DeepCopy() *KubervisorServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubervisorServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyPercentile) DeepCopyInto(out *LatencyPercentile) {
+	*out = *in
+	if in.Quantile != nil {
+		in, out := &in.Quantile, &out.Quantile
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
 		}
 	}
-	if in.ContinuousValueDeviation != nil {
-		in, out := &in.ContinuousValueDeviation, &out.ContinuousValueDeviation
+	if in.MaxDeviationPercent != nil {
+		in, out := &in.MaxDeviationPercent, &out.MaxDeviationPercent
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(ContinuousValueDeviation)
-			(*in).DeepCopyInto(*out)
+			*out = new(float64)
+			**out = **in
 		}
 	}
-	if in.Activator != nil {
-		in, out := &in.Activator, &out.Activator
+	if in.MaxLatency != nil {
+		in, out := &in.MaxLatency, &out.MaxLatency
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(ActivatorStrategy)
-			(*in).DeepCopyInto(*out)
+			*out = new(float64)
+			**out = **in
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BreakerStrategy.
-func (in *BreakerStrategy) DeepCopy() *BreakerStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyPercentile.
+func (in *LatencyPercentile) DeepCopy() *LatencyPercentile {
 	if in == nil {
 		return nil
 	}
-	out := new(BreakerStrategy)
+	out := new(LatencyPercentile)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ContinuousValueDeviation) DeepCopyInto(out *ContinuousValueDeviation) {
+func (in *MetricsExpression) DeepCopyInto(out *MetricsExpression) {
 	*out = *in
-	if in.MaxDeviationPercent != nil {
-		in, out := &in.MaxDeviationPercent, &out.MaxDeviationPercent
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(float64)
-			**out = **in
+	if in.Queries != nil {
+		in, out := &in.Queries, &out.Queries
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContinuousValueDeviation.
-func (in *ContinuousValueDeviation) DeepCopy() *ContinuousValueDeviation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExpression.
+func (in *MetricsExpression) DeepCopy() *MetricsExpression {
 	if in == nil {
 		return nil
 	}
-	out := new(ContinuousValueDeviation)
+	out := new(MetricsExpression)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DiscreteValueOutOfList) DeepCopyInto(out *DiscreteValueOutOfList) {
+func (in *PodAnomalyReport) DeepCopyInto(out *PodAnomalyReport) {
 	*out = *in
-	if in.GoodValues != nil {
-		in, out := &in.GoodValues, &out.GoodValues
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAnomalyReport.
+func (in *PodAnomalyReport) DeepCopy() *PodAnomalyReport {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAnomalyReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodAnomalyReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAnomalyReportList) DeepCopyInto(out *PodAnomalyReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodAnomalyReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAnomalyReportList.
+func (in *PodAnomalyReportList) DeepCopy() *PodAnomalyReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAnomalyReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodAnomalyReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAnomalyReportSpec) DeepCopyInto(out *PodAnomalyReportSpec) {
+	*out = *in
+	in.Expiry.DeepCopyInto(&out.Expiry)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAnomalyReportSpec.
+func (in *PodAnomalyReportSpec) DeepCopy() *PodAnomalyReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAnomalyReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodCountStatus) DeepCopyInto(out *PodCountStatus) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodCountStatus.
+func (in *PodCountStatus) DeepCopy() *PodCountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodCountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodLogPattern) DeepCopyInto(out *PodLogPattern) {
+	*out = *in
+	if in.GoodPatterns != nil {
+		in, out := &in.GoodPatterns, &out.GoodPatterns
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.BadValues != nil {
-		in, out := &in.BadValues, &out.BadValues
+	if in.BadPatterns != nil {
+		in, out := &in.BadPatterns, &out.BadPatterns
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
@@ -201,175 +1290,206 @@ func (in *DiscreteValueOutOfList) DeepCopyInto(out *DiscreteValueOutOfList) {
 			**out = **in
 		}
 	}
+	if in.MaxBytesPerPod != nil {
+		in, out := &in.MaxBytesPerPod, &out.MaxBytesPerPod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscreteValueOutOfList.
-func (in *DiscreteValueOutOfList) DeepCopy() *DiscreteValueOutOfList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodLogPattern.
+func (in *PodLogPattern) DeepCopy() *PodLogPattern {
 	if in == nil {
 		return nil
 	}
-	out := new(DiscreteValueOutOfList)
+	out := new(PodLogPattern)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubervisorService) DeepCopyInto(out *KubervisorService) {
+func (in *PodMatch) DeepCopyInto(out *PodMatch) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorService.
-func (in *KubervisorService) DeepCopy() *KubervisorService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMatch.
+func (in *PodMatch) DeepCopy() *PodMatch {
 	if in == nil {
 		return nil
 	}
-	out := new(KubervisorService)
+	out := new(PodMatch)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubervisorService) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubervisorServiceCondition) DeepCopyInto(out *KubervisorServiceCondition) {
+func (in *PodMetricsScrape) DeepCopyInto(out *PodMetricsScrape) {
 	*out = *in
-	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceCondition.
-func (in *KubervisorServiceCondition) DeepCopy() *KubervisorServiceCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMetricsScrape.
+func (in *PodMetricsScrape) DeepCopy() *PodMetricsScrape {
 	if in == nil {
 		return nil
 	}
-	out := new(KubervisorServiceCondition)
+	out := new(PodMetricsScrape)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubervisorServiceList) DeepCopyInto(out *KubervisorServiceList) {
+func (in *PodStatusAnomaly) DeepCopyInto(out *PodStatusAnomaly) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KubervisorService, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MaxRestartCount != nil {
+		in, out := &in.MaxRestartCount, &out.MaxRestartCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.TerminationReasons != nil {
+		in, out := &in.TerminationReasons, &out.TerminationReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxReadyFlipCount != nil {
+		in, out := &in.MaxReadyFlipCount, &out.MaxReadyFlipCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceList.
-func (in *KubervisorServiceList) DeepCopy() *KubervisorServiceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodStatusAnomaly.
+func (in *PodStatusAnomaly) DeepCopy() *PodStatusAnomaly {
 	if in == nil {
 		return nil
 	}
-	out := new(KubervisorServiceList)
+	out := new(PodStatusAnomaly)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubervisorServiceList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubervisorServiceSpec) DeepCopyInto(out *KubervisorServiceSpec) {
+func (in *RemoteWriteSource) DeepCopyInto(out *RemoteWriteSource) {
 	*out = *in
-	if in.Breakers != nil {
-		in, out := &in.Breakers, &out.Breakers
-		*out = make([]BreakerStrategy, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	in.DefaultActivator.DeepCopyInto(&out.DefaultActivator)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceSpec.
-func (in *KubervisorServiceSpec) DeepCopy() *KubervisorServiceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteWriteSource.
+func (in *RemoteWriteSource) DeepCopy() *RemoteWriteSource {
 	if in == nil {
 		return nil
 	}
-	out := new(KubervisorServiceSpec)
+	out := new(RemoteWriteSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubervisorServiceStatus) DeepCopyInto(out *KubervisorServiceStatus) {
+func (in *ResourceUsage) DeepCopyInto(out *ResourceUsage) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]KubervisorServiceCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.MaxFleetRatio != nil {
+		in, out := &in.MaxFleetRatio, &out.MaxFleetRatio
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
 		}
 	}
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
+	if in.MaxLimitPercent != nil {
+		in, out := &in.MaxLimitPercent, &out.MaxLimitPercent
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = (*in).DeepCopy()
+			*out = new(uint)
+			**out = **in
 		}
 	}
-	if in.PodCounts != nil {
-		in, out := &in.PodCounts, &out.PodCounts
+	if in.SustainedPeriod != nil {
+		in, out := &in.SustainedPeriod, &out.SustainedPeriod
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(PodCountStatus)
-			(*in).DeepCopyInto(*out)
+			*out = new(float64)
+			**out = **in
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubervisorServiceStatus.
-func (in *KubervisorServiceStatus) DeepCopy() *KubervisorServiceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUsage.
+func (in *ResourceUsage) DeepCopy() *ResourceUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(KubervisorServiceStatus)
+	out := new(ResourceUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PodCountStatus) DeepCopyInto(out *PodCountStatus) {
+func (in *TopologyMinAvailable) DeepCopyInto(out *TopologyMinAvailable) {
 	*out = *in
-	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	if in.MinPodsAvailableCount != nil {
+		in, out := &in.MinPodsAvailableCount, &out.MinPodsAvailableCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
+	if in.MinPodsAvailableRatio != nil {
+		in, out := &in.MinPodsAvailableRatio, &out.MinPodsAvailableRatio
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(uint)
+			**out = **in
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodCountStatus.
-func (in *PodCountStatus) DeepCopy() *PodCountStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyMinAvailable.
+func (in *TopologyMinAvailable) DeepCopy() *TopologyMinAvailable {
 	if in == nil {
 		return nil
 	}
-	out := new(PodCountStatus)
+	out := new(TopologyMinAvailable)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1,18 +1,51 @@
 package breaker
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
 	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
 	"github.com/amadeusitgroup/kubervisor/pkg/anomalydetector"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
 	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
 	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
+)
+
+func init() {
+	prometheus.MustRegister(fleetIncidentGauges)
+	prometheus.MustRegister(rateLimitedBreakCounters)
+}
+
+var (
+	fleetIncidentGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_fleet_incident",
+			Help: "Set to 1 while the fleet guard of a breaker strategy detects a service-level incident",
+		},
+		[]string{"breaker", "namespace", "strategy"},
+	)
+
+	rateLimitedBreakCounters = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubervisor_rate_limited_breaks",
+			Help: "Number of pod breaks denied by the break rate limit of the KubervisorService",
+		},
+		[]string{"breaker", "namespace", "strategy"},
+	)
 )
 
 //Breaker engine that check anomaly and relabel pods
@@ -20,17 +53,59 @@ type Breaker interface {
 	Run(stop <-chan struct{})
 	CompareConfig(specConfig *api.BreakerStrategy, specSelector labels.Selector) bool
 	Name() string
+	// FleetIncident returns the reason of the ongoing fleet incident, empty if none or if the condition is not requested
+	FleetIncident() string
+}
+
+//NodeLimiter limits the number of pods broken on a node across all the KubervisorServices
+type NodeLimiter interface {
+	// RemainingBreaks returns how many pods can still be broken on the node
+	RemainingBreaks(nodeName string) int
+}
+
+//AnomalyRecorder receives the pods found out of bounds at each evaluation of a breaker, whether they are broken or not
+type AnomalyRecorder interface {
+	RecordAnomalies(namespace, kubervisorName, strategyName string, pods []*kapiv1.Pod)
+}
+
+//BreakBudget break slots shared by all the breakers of a KubervisorService
+type BreakBudget interface {
+	// Reserve atomically reserves a break slot for the pod, false if the budget or the minimum of available pods does not allow it
+	Reserve(p *kapiv1.Pod, minAvailable int) bool
+	// Release gives back the slot of a pod that was not broken
+	Release(p *kapiv1.Pod)
+}
+
+//BreakRateLimiter limits the number of pods broken over time by all the breakers of a KubervisorService
+type BreakRateLimiter interface {
+	// Take takes a break from the current window, false if the breaks of the window are exhausted
+	Take() bool
+	// Return gives back the break of a pod that was not broken
+	Return()
 }
 
 //Config configuration required to create a Breaker
 type Config struct {
 	KubervisorName        string
+	Namespace             string
 	StrategyName          string
 	Selector              labels.Selector
 	BreakerStrategyConfig api.BreakerStrategy
 
 	PodLister  kv1.PodNamespaceLister
+	PodIndexer cache.Indexer
 	PodControl pod.ControlInterface
+	NodeLister kv1.NodeLister
+
+	KubeClient       clientset.Interface
+	RemoteWriteStore *remotewrite.Store
+	ReportLister     blisters.PodAnomalyReportNamespaceLister
+	AlertStore       *alertmanager.Store
+	Recorder         record.EventRecorder
+	NodeLimiter      NodeLimiter
+	AnomalyRecorder  AnomalyRecorder
+	BreakBudget      BreakBudget
+	BreakRateLimiter BreakRateLimiter
 
 	Logger *zap.Logger
 }
@@ -40,16 +115,26 @@ var _ Breaker = &breakerImpl{}
 //breakerImpl implementation of the breaker interface
 type breakerImpl struct {
 	kubervisorName        string
+	namespace             string
 	breakerStrategyName   string
 	selector              labels.Selector
 	breakerStrategyConfig api.BreakerStrategy
 
 	podLister  kv1.PodNamespaceLister
 	podControl pod.ControlInterface
+	nodeLister kv1.NodeLister
 
-	logger *zap.Logger
+	logger          *zap.Logger
+	recorder        record.EventRecorder
+	nodeLimiter     NodeLimiter
+	anomalyRecorder AnomalyRecorder
+	budget          BreakBudget
+	rateLimiter     BreakRateLimiter
 
 	anomalyDetector anomalydetector.AnomalyDetector
+
+	incidentLock sync.Mutex
+	incident     string
 }
 
 //Name return the name of the breaker strategy
@@ -59,61 +144,227 @@ func (b *breakerImpl) Name() string {
 
 //Run implements Breaker run loop ( to launch as goroutine: go Run())
 func (b *breakerImpl) Run(stop <-chan struct{}) {
+	if runner, ok := b.anomalyDetector.(anomalydetector.Runner); ok {
+		go runner.Run(stop)
+	}
+	// a triggered anomaly detector is also evaluated every evaluation period: the pods that could not be broken are retried, and the
+	// state that changes without trigger (expired alerts, recreated pods) is taken into account
+	var trigger <-chan struct{}
 	ticker := time.NewTicker(time.Duration(*b.breakerStrategyConfig.EvaluationPeriod*1000) * time.Millisecond)
 	defer ticker.Stop()
+	tick := ticker.C
+
+	if triggered, ok := b.anomalyDetector.(anomalydetector.Triggered); ok {
+		trigger = triggered.Trigger()
+		// the triggers received before the start of the breaker are not lost
+		b.evaluate()
+	}
 	for {
 		select {
-		case <-ticker.C:
-			podsToCut, err := b.anomalyDetector.GetPodsOutOfBounds()
-			if err != nil {
-				b.logger.Sugar().Errorf("can't apply breaker. Anomaly detection failed: %s", err)
-				continue
-			}
+		case <-tick:
+			b.evaluate()
+		case <-trigger:
+			b.evaluate()
+		case <-stop:
+			return
+		}
+	}
+}
 
-			if len(podsToCut) == 0 {
-				b.logger.Sugar().Debug("no anomaly detected.")
-				continue
-			}
+// evaluate runs the anomaly detection and breaks the pods out of bounds, within the limit of the minimum available pods
+func (b *breakerImpl) evaluate() {
+	podsToCut, err := b.anomalyDetector.GetPodsOutOfBounds()
+	if err != nil {
+		b.logger.Sugar().Errorf("can't apply breaker. Anomaly detection failed: %s", err)
+		return
+	}
+	if b.anomalyRecorder != nil {
+		b.anomalyRecorder.RecordAnomalies(b.namespace, b.kubervisorName, b.breakerStrategyName, podsToCut)
+	}
 
-			allPods, err := b.podLister.List(b.selector)
-			if err != nil {
-				b.logger.Sugar().Errorf("can't list pods, error: %v ", err)
-				continue
+	if b.breakerStrategyConfig.FleetGuard != nil {
+		reason, err := b.fleetIncidentReason(podsToCut)
+		if err != nil {
+			b.logger.Sugar().Errorf("can't apply breaker. Fleet guard failed: %s", err)
+			return
+		}
+		b.setFleetIncident(reason)
+		if reason != "" {
+			b.logger.Sugar().Warnf("fleet incident, no pod is broken: %s", reason)
+			return
+		}
+	}
+
+	if len(podsToCut) == 0 {
+		b.logger.Sugar().Debug("no anomaly detected.")
+		return
+	}
+
+	allPods, err := b.podLister.List(b.selector)
+	if err != nil {
+		b.logger.Sugar().Errorf("can't list pods, error: %v ", err)
+		return
+	}
+	runningPods, err := pod.KeepRunningPods(allPods)
+	if err != nil {
+		b.logger.Sugar().Errorf("can't get running pods, error: %v ", err)
+		return
+	}
+	readyPods, err := pod.PurgeNotReadyPods(runningPods)
+	if err != nil {
+		b.logger.Sugar().Errorf("can't purge not ready pods, error: %v ", err)
+		return
+	}
+	withTraffic, err := pod.KeepWithTrafficYesPods(readyPods)
+	if err != nil {
+		b.logger.Sugar().Errorf("can't get pods with traffic, error: %v ", err)
+		return
+	}
+	minAvailable := b.computeMinAvailablePods(len(withTraffic))
+	removeCount := len(withTraffic) - minAvailable
+
+	if removeCount > len(podsToCut) {
+		removeCount = len(podsToCut)
+	}
+	if removeCount < 0 {
+		removeCount = 0
+	}
+
+	// removable pods by topology domain, nil if there is no minimum per topology
+	var removableByDomain map[string]int
+	if b.breakerStrategyConfig.MinPodsAvailablePerTopology != nil {
+		removableByDomain = b.computeRemovablePodsByDomain(withTraffic)
+	}
+	// remaining breaks by node name, read once per evaluation from the node limiter
+	remaining := map[string]int{}
+	rateLimited := []string{}
+	for _, p := range podsToCut {
+		if removeCount == 0 {
+			break
+		}
+		if b.nodeLimiter != nil && p.Spec.NodeName != "" {
+			if _, ok := remaining[p.Spec.NodeName]; !ok {
+				remaining[p.Spec.NodeName] = b.nodeLimiter.RemainingBreaks(p.Spec.NodeName)
 			}
-			runningPods, err := pod.KeepRunningPods(allPods)
-			if err != nil {
-				b.logger.Sugar().Errorf("can't get running pods, error: %v ", err)
+			if remaining[p.Spec.NodeName] <= 0 {
+				b.logger.Sugar().Infof("the pod %s is not broken: the limit of broken pods on the node %s is reached", p.Name, p.Spec.NodeName)
 				continue
 			}
-			readyPods, err := pod.PurgeNotReadyPods(runningPods)
-			if err != nil {
-				b.logger.Sugar().Errorf("can't purge not ready pods, error: %v ", err)
+		}
+		domain := ""
+		if removableByDomain != nil {
+			domain = b.topologyDomain(p)
+			if removableByDomain[domain] <= 0 {
+				b.logger.Sugar().Infof("the pod %s is not broken: the minimum of available pods in the topology domain %s=%s is reached", p.Name, b.breakerStrategyConfig.MinPodsAvailablePerTopology.TopologyKey, domain)
 				continue
 			}
-			withTraffic, err := pod.KeepWithTrafficYesPods(readyPods)
-			if err != nil {
-				b.logger.Sugar().Errorf("can't get pods with traffic, error: %v ", err)
-				continue
+		}
+		// the slot is reserved last: it is released if the pod can't be broken
+		if b.budget != nil && !b.budget.Reserve(p, minAvailable) {
+			b.logger.Sugar().Infof("the pod %s is not broken: the break budget of the KubervisorService is exhausted", p.Name)
+			continue
+		}
+		if b.rateLimiter != nil && !b.rateLimiter.Take() {
+			if b.budget != nil {
+				b.budget.Release(p)
 			}
-			removeCount := len(withTraffic) - b.computeMinAvailablePods(len(withTraffic))
-
-			if removeCount > len(podsToCut) {
-				removeCount = len(podsToCut)
+			rateLimited = append(rateLimited, p.Name)
+			continue
+		}
+		if removableByDomain != nil {
+			removableByDomain[domain]--
+		}
+		if b.nodeLimiter != nil && p.Spec.NodeName != "" {
+			remaining[p.Spec.NodeName]--
+		}
+		removeCount--
+		if _, err := b.podControl.UpdateBreakerAnnotationAndLabel(b.kubervisorName, b.breakerStrategyName, p); err != nil {
+			b.logger.Sugar().Errorf("can't update Breaker annotation and label: %s", err)
+			if b.budget != nil {
+				b.budget.Release(p)
 			}
-			if removeCount < 0 {
-				removeCount = 0
+			if b.rateLimiter != nil {
+				b.rateLimiter.Return()
 			}
+		}
+	}
 
-			for _, p := range podsToCut[:removeCount] {
-				if _, err := b.podControl.UpdateBreakerAnnotationAndLabel(b.kubervisorName, b.breakerStrategyName, p); err != nil {
-					b.logger.Sugar().Errorf("can't update Breaker annotation and label: %s", err)
-				}
-			}
+	if len(rateLimited) > 0 {
+		rateLimitedBreakCounters.WithLabelValues(b.kubervisorName, b.namespace, b.breakerStrategyName).Add(float64(len(rateLimited)))
+		b.logger.Sugar().Warnf("the pods %v are not broken: the break rate limit is reached", rateLimited)
+		b.recordEvent(kapiv1.EventTypeWarning, "BreakRateLimited", fmt.Sprintf("breaker strategy %s did not break the pods %v: the break rate limit is reached", b.breakerStrategyName, rateLimited))
+	}
+}
 
-		case <-stop:
-			return
+// fleetIncidentReason returns why the anomaly is considered as fleet-wide, empty if it is not
+func (b *breakerImpl) fleetIncidentReason(podsToCut []*kapiv1.Pod) (string, error) {
+	guard := b.breakerStrategyConfig.FleetGuard
+	if guard.MaxFleetErrorPercent != nil {
+		if reporter, ok := b.anomalyDetector.(anomalydetector.FleetReporter); ok {
+			if ratio, active := reporter.FleetErrorRatio(); active && ratio*100 > float64(*guard.MaxFleetErrorPercent) {
+				return fmt.Sprintf("fleet error ratio %.1f%% exceeds %d%%", ratio*100, *guard.MaxFleetErrorPercent), nil
+			}
 		}
 	}
+	if guard.MaxFlaggedPodsPercent == nil || len(podsToCut) == 0 {
+		return "", nil
+	}
+	allPods, err := b.podLister.List(b.selector)
+	if err != nil {
+		return "", fmt.Errorf("can't list pods, error: %v", err)
+	}
+	runningPods, err := pod.KeepRunningPods(allPods)
+	if err != nil {
+		return "", fmt.Errorf("can't get running pods, error: %v", err)
+	}
+	readyPods, err := pod.PurgeNotReadyPods(runningPods)
+	if err != nil {
+		return "", fmt.Errorf("can't purge not ready pods, error: %v", err)
+	}
+	if len(readyPods) > 0 && len(podsToCut)*100 > int(*guard.MaxFlaggedPodsPercent)*len(readyPods) {
+		return fmt.Sprintf("%d pods out of bounds out of %d managed pods exceeds %d%%", len(podsToCut), len(readyPods), *guard.MaxFlaggedPodsPercent), nil
+	}
+	return "", nil
+}
+
+// setFleetIncident records the start or the end of a fleet incident with an event and the fleet incident metric
+func (b *breakerImpl) setFleetIncident(reason string) {
+	b.incidentLock.Lock()
+	previous := b.incident
+	b.incident = reason
+	b.incidentLock.Unlock()
+
+	switch {
+	case previous == "" && reason != "":
+		fleetIncidentGauges.WithLabelValues(b.kubervisorName, b.namespace, b.breakerStrategyName).Set(1)
+		b.recordEvent(kapiv1.EventTypeWarning, "FleetIncident", fmt.Sprintf("breaker strategy %s stops breaking pods: %s", b.breakerStrategyName, reason))
+	case previous != "" && reason == "":
+		fleetIncidentGauges.WithLabelValues(b.kubervisorName, b.namespace, b.breakerStrategyName).Set(0)
+		b.recordEvent(kapiv1.EventTypeNormal, "FleetIncidentResolved", fmt.Sprintf("breaker strategy %s resumes breaking pods", b.breakerStrategyName))
+	}
+}
+
+func (b *breakerImpl) recordEvent(eventType, reason, message string) {
+	if b.recorder == nil {
+		return
+	}
+	ref := &kapiv1.ObjectReference{
+		Kind:       api.ResourceKind,
+		APIVersion: api.SchemeGroupVersion.String(),
+		Namespace:  b.namespace,
+		Name:       b.kubervisorName,
+	}
+	b.recorder.Event(ref, eventType, reason, message)
+}
+
+//FleetIncident implements interface Breaker
+func (b *breakerImpl) FleetIncident() string {
+	if b.breakerStrategyConfig.FleetGuard == nil || !b.breakerStrategyConfig.FleetGuard.SetCondition {
+		return ""
+	}
+	b.incidentLock.Lock()
+	defer b.incidentLock.Unlock()
+	return b.incident
 }
 
 // CompareConfig used to compare the current config with a possible new spec config
@@ -129,6 +380,49 @@ func (b *breakerImpl) CompareConfig(specConfig *api.BreakerStrategy, specSelecto
 
 }
 
+// computeRemovablePodsByDomain returns by topology domain how many pods can be removed while keeping the minimum of the domain
+func (b *breakerImpl) computeRemovablePodsByDomain(withTraffic []*kapiv1.Pod) map[string]int {
+	topology := b.breakerStrategyConfig.MinPodsAvailablePerTopology
+	count, ratio := 0, 0
+	if topology.MinPodsAvailableRatio != nil {
+		ratio = int(*topology.MinPodsAvailableRatio)
+	}
+	if topology.MinPodsAvailableCount != nil {
+		count = int(*topology.MinPodsAvailableCount)
+	}
+
+	podsByDomain := map[string]int{}
+	for _, p := range withTraffic {
+		podsByDomain[b.topologyDomain(p)]++
+	}
+	removable := map[string]int{}
+	for domain, pods := range podsByDomain {
+		min := pods * ratio / 100
+		if count > min {
+			min = count
+		}
+		removable[domain] = pods - min
+	}
+	return removable
+}
+
+// topologyDomain returns the value of the topology key in the pod labels, else in the labels of the node of the pod
+func (b *breakerImpl) topologyDomain(p *kapiv1.Pod) string {
+	key := b.breakerStrategyConfig.MinPodsAvailablePerTopology.TopologyKey
+	if value, ok := p.Labels[key]; ok {
+		return value
+	}
+	if b.nodeLister == nil || p.Spec.NodeName == "" {
+		return ""
+	}
+	node, err := b.nodeLister.Get(p.Spec.NodeName)
+	if err != nil {
+		b.logger.Sugar().Debugf("can't get the node %s of the pod %s, error: %v", p.Spec.NodeName, p.Name, err)
+		return ""
+	}
+	return node.Labels[key]
+}
+
 func (b *breakerImpl) computeMinAvailablePods(podUnderSelectorCount int) int {
 	count, ratio := 0, 0
 	if b.breakerStrategyConfig.MinPodsAvailableRatio != nil {
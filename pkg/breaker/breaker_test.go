@@ -2,14 +2,22 @@ package breaker
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	kfakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	kv1 "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/amadeusitgroup/kubervisor/pkg/anomalydetector"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
@@ -369,6 +377,572 @@ func (t *testAnomalyDetector) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
 	return t.pods, nil
 }
 
+type testTriggeredAnomalyDetector struct {
+	pods    []*kapiv1.Pod
+	trigger chan struct{}
+}
+
+func (t *testTriggeredAnomalyDetector) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	return t.pods, nil
+}
+
+func (t *testTriggeredAnomalyDetector) Trigger() <-chan struct{} {
+	return t.trigger
+}
+
+func TestBreakerImpl_RunFirstEvaluation(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	broken := make(chan string, 10)
+	b := &breakerImpl{
+		breakerStrategyConfig: api.BreakerStrategy{
+			EvaluationPeriod:      api.NewFloat64(0.3),
+			MinPodsAvailableCount: api.NewUInt(1),
+		},
+		selector:  labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister: test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B}, "test-ns"),
+		podControl: &test.TestPodControl{
+			UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+				broken <- p.Name
+				return p, nil
+			},
+		},
+		logger:          devlogger,
+		anomalyDetector: &testFleetAnomalyDetector{pods: []*kapiv1.Pod{A}},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go b.Run(stop)
+
+	// a detector without trigger is evaluated one evaluation period after the start, not at start
+	select {
+	case name := <-broken:
+		t.Fatalf("pod %s broken before the first evaluation period", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+	select {
+	case <-broken:
+	case <-time.After(time.Second):
+		t.Fatalf("no pod broken after the first evaluation period")
+	}
+}
+
+func TestBreakerImpl_RunTriggered(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	broken := make(chan string, 10)
+	detector := &testTriggeredAnomalyDetector{pods: []*kapiv1.Pod{A}, trigger: make(chan struct{})}
+	b := &breakerImpl{
+		breakerStrategyConfig: api.BreakerStrategy{
+			EvaluationPeriod:      api.NewFloat64(3600),
+			MinPodsAvailableCount: api.NewUInt(1),
+		},
+		selector:  labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister: test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B}, "test-ns"),
+		podControl: &test.TestPodControl{
+			UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+				broken <- p.Name
+				return p, nil
+			},
+		},
+		logger:          devlogger,
+		anomalyDetector: detector,
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go b.Run(stop)
+
+	waitBroken := func(when string) {
+		select {
+		case name := <-broken:
+			if name != "A" {
+				t.Errorf("broken pod %s = %s, want A", when, name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no pod broken %s", when)
+		}
+	}
+
+	// evaluated at start, then on the trigger before the end of the evaluation period
+	waitBroken("at start")
+	detector.trigger <- struct{}{}
+	waitBroken("after trigger")
+	select {
+	case name := <-broken:
+		t.Fatalf("pod %s broken without trigger before the evaluation period", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBreakerImpl_RunTriggeredPeriod(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	broken := make(chan string, 10)
+	b := &breakerImpl{
+		breakerStrategyConfig: api.BreakerStrategy{
+			EvaluationPeriod:      api.NewFloat64(0.01),
+			MinPodsAvailableCount: api.NewUInt(1),
+		},
+		selector:  labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister: test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B}, "test-ns"),
+		podControl: &test.TestPodControl{
+			UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+				broken <- p.Name
+				return p, nil
+			},
+		},
+		logger:          devlogger,
+		anomalyDetector: &testTriggeredAnomalyDetector{pods: []*kapiv1.Pod{A}, trigger: make(chan struct{})},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go b.Run(stop)
+
+	// the evaluation period is the fallback of the trigger: the pod is broken again at each period without trigger
+	for i := 0; i < 3; i++ {
+		select {
+		case <-broken:
+		case <-time.After(time.Second):
+			t.Fatalf("no pod broken without trigger, evaluation %d", i)
+		}
+	}
+}
+
+type testAnomalyRecorder struct {
+	recorded map[string][]string
+}
+
+func (r *testAnomalyRecorder) RecordAnomalies(namespace, kubervisorName, strategyName string, pods []*kapiv1.Pod) {
+	names := []string{}
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	r.recorded[namespace+"/"+kubervisorName+"/"+strategyName] = names
+}
+
+func TestBreakerImpl_AnomalyRecorder(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	recorder := &testAnomalyRecorder{recorded: map[string][]string{}}
+	b := &breakerImpl{
+		kubervisorName:      "foo",
+		namespace:           "test-ns",
+		breakerStrategyName: "strategy",
+		breakerStrategyConfig: api.BreakerStrategy{
+			EvaluationPeriod:      api.NewFloat64(1),
+			MinPodsAvailableCount: api.NewUInt(2),
+		},
+		selector:        labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister:       test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B}, "test-ns"),
+		podControl:      &test.TestPodControl{},
+		logger:          devlogger,
+		anomalyRecorder: recorder,
+		anomalyDetector: &testTriggeredAnomalyDetector{pods: []*kapiv1.Pod{A, B}},
+	}
+	b.evaluate()
+
+	// the out of bounds pods are recorded even if the minimum of available pods prevents the breaks
+	if got := recorder.recorded["test-ns/foo/strategy"]; !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("recorded anomalies = %v, want [A B]", got)
+	}
+}
+
+type testFleetAnomalyDetector struct {
+	pods       []*kapiv1.Pod
+	errorRatio float64
+}
+
+func (t *testFleetAnomalyDetector) GetPodsOutOfBounds() ([]*kapiv1.Pod, error) {
+	return t.pods, nil
+}
+
+func (t *testFleetAnomalyDetector) FleetErrorRatio() (float64, bool) {
+	return t.errorRatio, true
+}
+
+func TestBreakerImpl_FleetGuard(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	C := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	D := test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	tests := []struct {
+		name       string
+		guard      api.FleetGuard
+		pods       []*kapiv1.Pod
+		errorRatio float64
+		wantBroken []string
+		wantEvent  string
+	}{
+		{
+			name:       "no incident",
+			guard:      api.FleetGuard{MaxFlaggedPodsPercent: api.NewUInt(50), MaxFleetErrorPercent: api.NewUInt(20)},
+			pods:       []*kapiv1.Pod{A},
+			errorRatio: 0.1,
+			wantBroken: []string{"A"},
+		},
+		{
+			name:       "too many flagged pods",
+			guard:      api.FleetGuard{MaxFlaggedPodsPercent: api.NewUInt(50)},
+			pods:       []*kapiv1.Pod{A, B, C},
+			wantEvent:  "Warning FleetIncident breaker strategy strategy stops breaking pods: 3 pods out of bounds out of 4 managed pods exceeds 50%",
+			wantBroken: []string{},
+		},
+		{
+			name:       "fleet error",
+			guard:      api.FleetGuard{MaxFleetErrorPercent: api.NewUInt(20)},
+			pods:       []*kapiv1.Pod{A},
+			errorRatio: 0.3,
+			wantEvent:  "Warning FleetIncident breaker strategy strategy stops breaking pods: fleet error ratio 30.0% exceeds 20%",
+			wantBroken: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := []string{}
+			recorder := record.NewFakeRecorder(10)
+			detector := &testFleetAnomalyDetector{pods: tt.pods, errorRatio: tt.errorRatio}
+			guard := tt.guard
+			guard.SetCondition = true
+			b := &breakerImpl{
+				kubervisorName:      "kubervisor",
+				namespace:           "test-ns",
+				breakerStrategyName: "strategy",
+				breakerStrategyConfig: api.BreakerStrategy{
+					MinPodsAvailableCount: api.NewUInt(1),
+					FleetGuard:            &guard,
+				},
+				selector:  labels.SelectorFromSet(map[string]string{"app": "foo"}),
+				podLister: test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C, D}, "test-ns"),
+				podControl: &test.TestPodControl{
+					UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+						broken = append(broken, p.Name)
+						return p, nil
+					},
+				},
+				logger:          devlogger,
+				recorder:        recorder,
+				anomalyDetector: detector,
+			}
+
+			b.evaluate()
+			if !reflect.DeepEqual(broken, tt.wantBroken) {
+				t.Errorf("broken pods = %v, want %v", broken, tt.wantBroken)
+			}
+			if tt.wantEvent == "" {
+				if b.FleetIncident() != "" {
+					t.Errorf("unexpected fleet incident: %s", b.FleetIncident())
+				}
+				return
+			}
+			if b.FleetIncident() == "" {
+				t.Errorf("missing fleet incident")
+			}
+			if event := <-recorder.Events; event != tt.wantEvent {
+				t.Errorf("event = %q, want %q", event, tt.wantEvent)
+			}
+
+			// the incident is reported once and resolved when the anomaly is not fleet-wide anymore
+			b.evaluate()
+			detector.pods, detector.errorRatio = []*kapiv1.Pod{A}, 0
+			b.evaluate()
+			if event := <-recorder.Events; event != "Normal FleetIncidentResolved breaker strategy strategy resumes breaking pods" {
+				t.Errorf("unexpected event: %q", event)
+			}
+			if b.FleetIncident() != "" {
+				t.Errorf("fleet incident not resolved: %s", b.FleetIncident())
+			}
+			if !reflect.DeepEqual(broken, []string{"A"}) {
+				t.Errorf("broken pods after the incident = %v, want [A]", broken)
+			}
+		})
+	}
+}
+
+type testNodeLimiter map[string]int
+
+func (l testNodeLimiter) RemainingBreaks(nodeName string) int {
+	return l[nodeName]
+}
+
+func TestBreakerImpl_NodeLimiter(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	onNode := func(name, nodeName string) *kapiv1.Pod {
+		p := test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+		p.Spec.NodeName = nodeName
+		return p
+	}
+	A, B, C, D := onNode("A", "node1"), onNode("B", "node1"), onNode("C", "node2"), onNode("D", "node2")
+
+	broken := []string{}
+	b := &breakerImpl{
+		kubervisorName:        "kubervisor",
+		namespace:             "test-ns",
+		breakerStrategyName:   "strategy",
+		breakerStrategyConfig: api.BreakerStrategy{MinPodsAvailableCount: api.NewUInt(1)},
+		selector:              labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister:             test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C, D}, "test-ns"),
+		podControl: &test.TestPodControl{
+			UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+				broken = append(broken, p.Name)
+				return p, nil
+			},
+		},
+		logger:          devlogger,
+		nodeLimiter:     testNodeLimiter{"node1": 1, "node2": 5},
+		anomalyDetector: &testFleetAnomalyDetector{pods: []*kapiv1.Pod{A, B, C}},
+	}
+
+	// B is skipped because node1 reached its limit with A, C is broken in its place
+	b.evaluate()
+	if !reflect.DeepEqual(broken, []string{"A", "C"}) {
+		t.Errorf("broken pods = %v, want [A C]", broken)
+	}
+}
+
+// testBreakBudget grants a number of slots
+type testBreakBudget struct {
+	slots    int
+	released []string
+}
+
+func (b *testBreakBudget) Reserve(p *kapiv1.Pod, minAvailable int) bool {
+	if b.slots == 0 {
+		return false
+	}
+	b.slots--
+	return true
+}
+
+func (b *testBreakBudget) Release(p *kapiv1.Pod) {
+	b.slots++
+	b.released = append(b.released, p.Name)
+}
+
+func TestBreakerImpl_BreakBudget(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	C := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	D := test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	broken := []string{}
+	budget := &testBreakBudget{slots: 2}
+	b := &breakerImpl{
+		kubervisorName:        "kubervisor",
+		namespace:             "test-ns",
+		breakerStrategyName:   "strategy",
+		breakerStrategyConfig: api.BreakerStrategy{MinPodsAvailableCount: api.NewUInt(1)},
+		selector:              labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister:             test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C, D}, "test-ns"),
+		podControl: &test.TestPodControl{
+			UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+				if p.Name == "A" {
+					return nil, fmt.Errorf("update error")
+				}
+				broken = append(broken, p.Name)
+				return p, nil
+			},
+		},
+		logger:          devlogger,
+		budget:          budget,
+		anomalyDetector: &testFleetAnomalyDetector{pods: []*kapiv1.Pod{A, B, C}},
+	}
+
+	// the slot of A is released after the update error and taken by C
+	b.evaluate()
+	if !reflect.DeepEqual(broken, []string{"B", "C"}) {
+		t.Errorf("broken pods = %v, want [B C]", broken)
+	}
+	if !reflect.DeepEqual(budget.released, []string{"A"}) || budget.slots != 0 {
+		t.Errorf("released = %v, slots = %d, want [A] and 0", budget.released, budget.slots)
+	}
+
+	// no slot left
+	broken = []string{}
+	b.evaluate()
+	if len(broken) != 0 {
+		t.Errorf("broken pods = %v, want none", broken)
+	}
+}
+
+// testBreakRateLimiter grants a number of breaks
+type testBreakRateLimiter struct {
+	breaks int
+}
+
+func (l *testBreakRateLimiter) Take() bool {
+	if l.breaks == 0 {
+		return false
+	}
+	l.breaks--
+	return true
+}
+
+func (l *testBreakRateLimiter) Return() {
+	l.breaks++
+}
+
+func TestBreakerImpl_BreakRateLimiter(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	C := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	D := test.PodGen("D", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	broken := []string{}
+	recorder := record.NewFakeRecorder(10)
+	budget := &testBreakBudget{slots: 3}
+	b := &breakerImpl{
+		kubervisorName:        "kubervisor",
+		namespace:             "test-ns",
+		breakerStrategyName:   "strategy",
+		breakerStrategyConfig: api.BreakerStrategy{MinPodsAvailableCount: api.NewUInt(1)},
+		selector:              labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister:             test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C, D}, "test-ns"),
+		podControl: &test.TestPodControl{
+			UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+				broken = append(broken, p.Name)
+				return p, nil
+			},
+		},
+		logger:          devlogger,
+		recorder:        recorder,
+		budget:          budget,
+		rateLimiter:     &testBreakRateLimiter{breaks: 1},
+		anomalyDetector: &testFleetAnomalyDetector{pods: []*kapiv1.Pod{A, B, C}},
+	}
+
+	b.evaluate()
+	if !reflect.DeepEqual(broken, []string{"A"}) {
+		t.Errorf("broken pods = %v, want [A]", broken)
+	}
+	// the budget slots of the rate limited pods are released
+	if !reflect.DeepEqual(budget.released, []string{"B", "C"}) {
+		t.Errorf("released = %v, want [B C]", budget.released)
+	}
+	if event := <-recorder.Events; event != "Warning BreakRateLimited breaker strategy strategy did not break the pods [B C]: the break rate limit is reached" {
+		t.Errorf("unexpected event: %q", event)
+	}
+}
+
+func TestBreakerImpl_BreakRateLimitedEvent(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	A := test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	B := test.PodGen("B", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+	C := test.PodGen("C", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+
+	// the event goes through the events API like in the controller, which requires the events rule of the chart ClusterRole
+	kubeClient := kfakeclient.NewSimpleClientset()
+	eventBroadcaster := record.NewBroadcaster()
+	sink := eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: kubeClient.Core().Events("")})
+	defer sink.Stop()
+	b := &breakerImpl{
+		kubervisorName:        "kubervisor",
+		namespace:             "test-ns",
+		breakerStrategyName:   "strategy",
+		breakerStrategyConfig: api.BreakerStrategy{MinPodsAvailableCount: api.NewUInt(1)},
+		selector:              labels.SelectorFromSet(map[string]string{"app": "foo"}),
+		podLister:             test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C}, "test-ns"),
+		podControl:            &test.TestPodControl{},
+		logger:                devlogger,
+		recorder:              eventBroadcaster.NewRecorder(scheme.Scheme, kapiv1.EventSource{Component: "kubervisor-controller"}),
+		rateLimiter:           &testBreakRateLimiter{breaks: 0},
+		anomalyDetector:       &testFleetAnomalyDetector{pods: []*kapiv1.Pod{A}},
+	}
+	b.evaluate()
+
+	for i := 0; i < 100; i++ {
+		for _, action := range kubeClient.Actions() {
+			create, ok := action.(clienttesting.CreateAction)
+			if !ok || action.GetResource().Resource != "events" {
+				continue
+			}
+			e := create.GetObject().(*kapiv1.Event)
+			if e.Reason != "BreakRateLimited" || e.Namespace != "test-ns" || e.InvolvedObject.Name != "kubervisor" {
+				t.Fatalf("unexpected event: %s/%s %s on %s", e.Namespace, e.Name, e.Reason, e.InvolvedObject.Name)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("the BreakRateLimited event is not created with the events API")
+}
+
+func TestBreakerImpl_MinPodsAvailablePerTopology(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	inZone := func(name, zone, nodeName string) *kapiv1.Pod {
+		p := test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes)
+		if zone != "" {
+			p.Labels["zone"] = zone
+		}
+		p.Spec.NodeName = nodeName
+		return p
+	}
+	// the zone of C and D is read from their node
+	A, B, C, D, E := inZone("A", "a", ""), inZone("B", "a", ""), inZone("C", "", "node-b"), inZone("D", "", "node-b"), inZone("E", "c", "")
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	nodeIndex.Add(&kapiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"zone": "b"}}})
+
+	tests := []struct {
+		name       string
+		topology   api.TopologyMinAvailable
+		wantBroken []string
+	}{
+		{
+			name:       "one pod per zone",
+			topology:   api.TopologyMinAvailable{TopologyKey: "zone", MinPodsAvailableCount: api.NewUInt(1)},
+			wantBroken: []string{"A", "C"},
+		},
+		{
+			name:       "ratio per zone",
+			topology:   api.TopologyMinAvailable{TopologyKey: "zone", MinPodsAvailableRatio: api.NewUInt(100)},
+			wantBroken: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := []string{}
+			topology := tt.topology
+			b := &breakerImpl{
+				kubervisorName:      "kubervisor",
+				namespace:           "test-ns",
+				breakerStrategyName: "strategy",
+				breakerStrategyConfig: api.BreakerStrategy{
+					MinPodsAvailableCount:       api.NewUInt(1),
+					MinPodsAvailablePerTopology: &topology,
+				},
+				selector:   labels.SelectorFromSet(map[string]string{"app": "foo"}),
+				podLister:  test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C, D, E}, "test-ns"),
+				nodeLister: kv1.NewNodeLister(nodeIndex),
+				podControl: &test.TestPodControl{
+					UpdateBreakerAnnotationAndLabelFunc: func(name string, strategy string, p *kapiv1.Pod) (*kapiv1.Pod, error) {
+						broken = append(broken, p.Name)
+						return p, nil
+					},
+				},
+				logger:          devlogger,
+				anomalyDetector: &testFleetAnomalyDetector{pods: []*kapiv1.Pod{A, B, C, D, E}},
+			}
+
+			// the global minimum allows 4 cuts, the zones a and b keep one pod, the zone c has a single pod
+			b.evaluate()
+			if !reflect.DeepEqual(broken, tt.wantBroken) {
+				t.Errorf("broken pods = %v, want %v", broken, tt.wantBroken)
+			}
+		})
+	}
+}
+
 func TestBreakerImpl_CompareConfig(t *testing.T) {
 	type fields struct {
 		breakerName           string
@@ -25,10 +25,17 @@ func New(cfg FactoryConfig) (Breaker, error) {
 
 	anomalyDetector, err := anomalydetector.New(anomalydetector.FactoryConfig{
 		Config: anomalydetector.Config{
+			KubervisorName:        cfg.KubervisorName,
+			Namespace:             cfg.Namespace,
 			BreakerStrategyConfig: cfg.BreakerStrategyConfig,
 			Selector:              cfg.Selector,
 			Logger:                cfg.Logger,
 			PodLister:             cfg.PodLister,
+			PodIndexer:            cfg.PodIndexer,
+			KubeClient:            cfg.KubeClient,
+			RemoteWriteStore:      cfg.RemoteWriteStore,
+			ReportLister:          cfg.ReportLister,
+			AlertStore:            cfg.AlertStore,
 		},
 	})
 
@@ -37,11 +44,18 @@ func New(cfg FactoryConfig) (Breaker, error) {
 	}
 
 	return &breakerImpl{
+		namespace:             cfg.Namespace,
 		breakerStrategyName:   cfg.StrategyName,
 		breakerStrategyConfig: cfg.BreakerStrategyConfig,
 		logger:                cfg.Logger,
+		recorder:              cfg.Recorder,
+		nodeLimiter:           cfg.NodeLimiter,
+		anomalyRecorder:       cfg.AnomalyRecorder,
+		budget:                cfg.BreakBudget,
+		rateLimiter:           cfg.BreakRateLimiter,
 		podControl:            cfg.PodControl,
 		podLister:             cfg.PodLister,
+		nodeLister:            cfg.NodeLister,
 		kubervisorName:        cfg.KubervisorName,
 		selector:              cfg.Selector,
 		anomalyDetector:       anomalyDetector,
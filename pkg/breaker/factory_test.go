@@ -19,6 +19,9 @@ func (e *emptyCustomBreakerT) CompareConfig(specConfig *api.BreakerStrategy, spe
 func (e *emptyCustomBreakerT) Name() string {
 	return e.name
 }
+func (e *emptyCustomBreakerT) FleetIncident() string {
+	return ""
+}
 
 var emptyCustomBreaker Breaker = &emptyCustomBreakerT{}
 
@@ -22,21 +22,36 @@ import (
 
 // DefineKubervisorResources defines the  DefineKubervisor Resources as a k8s CR
 func DefineKubervisorResources(clientset apiextensionsclient.Interface) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
-	kubervisorClusterResourceName := api.ResourcePlural + "." + kubervisor.GroupName
+	return defineResource(clientset, apiextensionsv1beta1.CustomResourceDefinitionNames{
+		Plural:     api.ResourcePlural,
+		Singular:   api.ResourceSingular,
+		Kind:       reflect.TypeOf(api.KubervisorService{}).Name(),
+		ShortNames: []string{"rdc"},
+	})
+}
+
+// DefinePodAnomalyReportResources defines the PodAnomalyReport Resources as a k8s CR
+func DefinePodAnomalyReportResources(clientset apiextensionsclient.Interface) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	return defineResource(clientset, apiextensionsv1beta1.CustomResourceDefinitionNames{
+		Plural:     api.ReportResourcePlural,
+		Singular:   api.ReportResourceSingular,
+		Kind:       reflect.TypeOf(api.PodAnomalyReport{}).Name(),
+		ShortNames: []string{"par"},
+	})
+}
+
+// defineResource creates the namespaced CRD of the kubervisor group and waits for it being established
+func defineResource(clientset apiextensionsclient.Interface, names apiextensionsv1beta1.CustomResourceDefinitionNames) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	clusterResourceName := names.Plural + "." + kubervisor.GroupName
 	crd := &apiextensionsv1beta1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: kubervisorClusterResourceName,
+			Name: clusterResourceName,
 		},
 		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
 			Group:   kubervisor.GroupName,
 			Version: api.SchemeGroupVersion.Version,
 			Scope:   apiextensionsv1beta1.NamespaceScoped,
-			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
-				Plural:     api.ResourcePlural,
-				Singular:   api.ResourceSingular,
-				Kind:       reflect.TypeOf(api.KubervisorService{}).Name(),
-				ShortNames: []string{"rdc"},
-			},
+			Names:   names,
 		},
 	}
 	_, err := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
@@ -46,7 +61,7 @@ func DefineKubervisorResources(clientset apiextensionsclient.Interface) (*apiext
 
 	// wait for CRD being established
 	err = wait.Poll(500*time.Millisecond, 60*time.Second, func() (bool, error) {
-		crd, err = clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Get(kubervisorClusterResourceName, metav1.GetOptions{})
+		crd, err = clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Get(clusterResourceName, metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -65,7 +80,7 @@ func DefineKubervisorResources(clientset apiextensionsclient.Interface) (*apiext
 		return false, err
 	})
 	if err != nil {
-		deleteErr := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(kubervisorClusterResourceName, nil)
+		deleteErr := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(clusterResourceName, nil)
 		if deleteErr != nil {
 			return nil, errors.NewAggregate([]error{err, deleteErr})
 		}
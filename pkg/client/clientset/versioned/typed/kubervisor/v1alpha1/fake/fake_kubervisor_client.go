@@ -40,6 +40,10 @@ func (c *FakeKubervisorV1alpha1) KubervisorServices(namespace string) v1alpha1.K
 	return &FakeKubervisorServices{c, namespace}
 }
 
+func (c *FakeKubervisorV1alpha1) PodAnomalyReports(namespace string) v1alpha1.PodAnomalyReportInterface {
+	return &FakePodAnomalyReports{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeKubervisorV1alpha1) RESTClient() rest.Interface {
@@ -27,3 +27,5 @@ SOFTWARE.
 package v1alpha1
 
 type KubervisorServiceExpansion interface{}
+
+type PodAnomalyReportExpansion interface{}
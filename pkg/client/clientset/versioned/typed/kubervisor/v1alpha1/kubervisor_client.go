@@ -36,6 +36,7 @@ import (
 type KubervisorV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	KubervisorServicesGetter
+	PodAnomalyReportsGetter
 }
 
 // KubervisorV1alpha1Client is used to interact with features provided by the kubervisor.k8s.io group.
@@ -47,6 +48,10 @@ func (c *KubervisorV1alpha1Client) KubervisorServices(namespace string) Kubervis
 	return newKubervisorServices(c, namespace)
 }
 
+func (c *KubervisorV1alpha1Client) PodAnomalyReports(namespace string) PodAnomalyReportInterface {
+	return newPodAnomalyReports(c, namespace)
+}
+
 // NewForConfig creates a new KubervisorV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*KubervisorV1alpha1Client, error) {
 	config := *c
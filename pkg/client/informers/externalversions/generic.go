@@ -65,6 +65,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=kubervisor.k8s.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("kubervisorservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubervisor().V1alpha1().KubervisorServices().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("podanomalyreports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubervisor().V1alpha1().PodAnomalyReports().Informer()}, nil
 
 	}
 
@@ -36,6 +36,8 @@ import (
 type Interface interface {
 	// KubervisorServices returns a KubervisorServiceInformer.
 	KubervisorServices() KubervisorServiceInformer
+	// PodAnomalyReports returns a PodAnomalyReportInformer.
+	PodAnomalyReports() PodAnomalyReportInformer
 }
 
 type version struct {
@@ -53,3 +55,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) KubervisorServices() KubervisorServiceInformer {
 	return &kubervisorServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// PodAnomalyReports returns a PodAnomalyReportInformer.
+func (v *version) PodAnomalyReports() PodAnomalyReportInformer {
+	return &podAnomalyReportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
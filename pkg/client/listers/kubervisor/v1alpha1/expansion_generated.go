@@ -35,3 +35,11 @@ type KubervisorServiceListerExpansion interface{}
 // KubervisorServiceNamespaceListerExpansion allows custom methods to be added to
 // KubervisorServiceNamespaceLister.
 type KubervisorServiceNamespaceListerExpansion interface{}
+
+// PodAnomalyReportListerExpansion allows custom methods to be added to
+// PodAnomalyReportLister.
+type PodAnomalyReportListerExpansion interface{}
+
+// PodAnomalyReportNamespaceListerExpansion allows custom methods to be added to
+// PodAnomalyReportNamespaceLister.
+type PodAnomalyReportNamespaceListerExpansion interface{}
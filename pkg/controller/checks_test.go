@@ -18,6 +18,7 @@ type testInterface struct {
 	StopFunc            func() error
 	CompareWithSpecFunc func(spec *api.KubervisorServiceSpec, selector labels.Selector) bool
 	GetStatusFunc       func() (api.PodCountStatus, error)
+	FleetIncidentsFunc  func() []string
 }
 
 func (ei *testInterface) Name() string {
@@ -50,6 +51,12 @@ func (ei *testInterface) GetStatus() (api.PodCountStatus, error) {
 	}
 	return api.PodCountStatus{}, nil
 }
+func (ei *testInterface) FleetIncidents() []string {
+	if ei.FleetIncidentsFunc != nil {
+		return ei.FleetIncidentsFunc()
+	}
+	return nil
+}
 
 func TestIsSpecUpdated(t *testing.T) {
 	type args struct {
@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	kubervisorclient "github.com/amadeusitgroup/kubervisor/pkg/client"
 	bclient "github.com/amadeusitgroup/kubervisor/pkg/client/clientset/versioned"
@@ -11,12 +14,46 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	clientset "k8s.io/client-go/kubernetes"
 	restclientset "k8s.io/client-go/rest"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
 )
 
 const (
 	nbWorkerDefault = 3
+
+	remoteWriteMaxSeriesDefault = 100000
+	remoteWriteRetentionDefault = 10 * time.Minute
+
+	alertmanagerAlertMaxAgeDefault = 5 * time.Hour
+
+	topologyPeriodDefault    = 10 * time.Second
+	topologyZoneLabelDefault = "failure-domain.beta.kubernetes.io/zone"
 )
 
+//Possible actions on a node detected as bad by the topology correlator
+const (
+	NodeActionNone   = "none"
+	NodeActionCordon = "cordon"
+	NodeActionTaint  = "taint"
+)
+
+// TopologyConfig configuration of the correlation of the broken and out of bounds pods by node and by zone
+type TopologyConfig struct {
+	// Period of the correlation
+	Period time.Duration
+	// ZoneLabel node label carrying the zone
+	ZoneLabel string
+	// NodeThreshold number of KubervisorServices with a broken or out of bounds pod on a node for the node to be considered as bad, 0 disables the detection
+	NodeThreshold int
+	// ZoneThreshold number of KubervisorServices with a broken or out of bounds pod in a zone for the zone to be considered as bad, 0 disables the detection
+	ZoneThreshold int
+	// NodeAction action on a bad node: none, cordon or taint
+	NodeAction string
+	// MaxBrokenPodsPerNode maximum number of broken pods on a node across all the KubervisorServices, 0 for no limit
+	MaxBrokenPodsPerNode int
+}
+
 // Config represent the kubevisor binary configuration
 type Config struct {
 	KubeConfigFile string
@@ -24,6 +61,18 @@ type Config struct {
 	ListenAddr     string
 	Debug          bool
 
+	RemoteWrite          bool
+	RemoteWriteTokenFile string
+	RemoteWritePodLabels []string
+	RemoteWriteMaxSeries int
+	RemoteWriteRetention time.Duration
+
+	AlertmanagerWebhook          bool
+	AlertmanagerWebhookTokenFile string
+	AlertmanagerAlertMaxAge      time.Duration
+
+	Topology TopologyConfig
+
 	nbWorker uint32
 
 	logger *zap.Logger
@@ -48,6 +97,20 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.ListenAddr, "addr", "0.0.0.0:8086", "listen address of the http server which serves kubernetes probes and prometheus endpoints")
 	fs.Uint32Var(&c.nbWorker, "nbworker", nbWorkerDefault, "Number of running workers in the controller")
 	fs.BoolVar(&c.Debug, "debug", c.Debug, "used to activate debug logs")
+	fs.BoolVar(&c.RemoteWrite, "remote-write", false, "enable the remote-write endpoint (/api/v1/write). The series are kept in memory by the replica that receives them and only the leader runs the breakers: a single replica of the controller is supported when it is enabled")
+	fs.StringVar(&c.RemoteWriteTokenFile, "remote-write-token-file", "", "file containing the bearer token required on the remote-write endpoint, mandatory when the endpoint is enabled")
+	fs.StringSliceVar(&c.RemoteWritePodLabels, "remote-write-pod-labels", []string{"pod", "kubernetes_pod_name"}, "labels of the series received on the remote-write endpoint that carry the pod name")
+	fs.IntVar(&c.RemoteWriteMaxSeries, "remote-write-max-series", remoteWriteMaxSeriesDefault, "maximum number of series kept in memory for the remote-write endpoint")
+	fs.DurationVar(&c.RemoteWriteRetention, "remote-write-retention", remoteWriteRetentionDefault, "retention of the samples received on the remote-write endpoint")
+	fs.BoolVar(&c.AlertmanagerWebhook, "alertmanager-webhook", false, "enable the Alertmanager webhook receiver (/api/v1/alerts). The alerts are kept in memory by the replica that receives them and only the leader runs the breakers: a single replica of the controller is supported when it is enabled")
+	fs.StringVar(&c.AlertmanagerWebhookTokenFile, "alertmanager-webhook-token-file", "", "file containing the bearer token required on the Alertmanager webhook receiver, mandatory when the receiver is enabled")
+	fs.DurationVar(&c.AlertmanagerAlertMaxAge, "alertmanager-alert-max-age", alertmanagerAlertMaxAgeDefault, "firing alerts not received again for this duration are dropped, it must be greater than the repeat_interval of the Alertmanager route (4h by default)")
+	fs.DurationVar(&c.Topology.Period, "topology-period", topologyPeriodDefault, "period of the correlation of the broken and out of bounds pods by node and by zone")
+	fs.StringVar(&c.Topology.ZoneLabel, "topology-zone-label", topologyZoneLabelDefault, "node label carrying the zone used to group the broken pods")
+	fs.IntVar(&c.Topology.NodeThreshold, "topology-node-threshold", 0, "number of KubervisorServices with a broken or out of bounds pod on a node for the node to be considered as bad, 0 disables the bad node detection")
+	fs.IntVar(&c.Topology.ZoneThreshold, "topology-zone-threshold", 0, "number of KubervisorServices with a broken or out of bounds pod in a zone for the zone to be considered as bad, 0 disables the bad zone detection")
+	fs.StringVar(&c.Topology.NodeAction, "topology-node-action", NodeActionNone, "action on a bad node: none, cordon or taint")
+	fs.IntVar(&c.Topology.MaxBrokenPodsPerNode, "max-broken-pods-per-node", 0, "maximum number of broken pods on a node across all the KubervisorServices, 0 for no limit")
 }
 
 //RegisterAPI registers the apiextension in kubernetes apiserver
@@ -70,6 +133,12 @@ func (c *Config) RegisterAPI() error {
 		sugar.Fatalf("Unable to define KubervisorService resource:%v", err)
 		return err
 	}
+
+	_, err = kubervisorclient.DefinePodAnomalyReportResources(extClient)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		sugar.Fatalf("Unable to define PodAnomalyReport resource:%v", err)
+		return err
+	}
 	return nil
 }
 
@@ -112,3 +181,61 @@ func (c *Config) NbWorker() uint32 {
 func (c *Config) HTTPServer() *http.Server {
 	return &http.Server{Addr: c.ListenAddr}
 }
+
+//RemoteWriteStore returns the store fed by the remote-write endpoint, nil if the endpoint is disabled
+func (c *Config) RemoteWriteStore() *remotewrite.Store {
+	if !c.RemoteWrite {
+		return nil
+	}
+	return remotewrite.NewStore(remotewrite.Config{
+		PodLabels: c.RemoteWritePodLabels,
+		MaxSeries: c.RemoteWriteMaxSeries,
+		Retention: c.RemoteWriteRetention,
+	})
+}
+
+//RemoteWriteToken returns the bearer token required on the remote-write endpoint, empty if the endpoint is disabled
+func (c *Config) RemoteWriteToken() string {
+	if !c.RemoteWrite {
+		return ""
+	}
+	return readToken(c.RemoteWriteTokenFile, "remote-write", c.logger)
+}
+
+//TopologyConfig returns the configuration of the topology correlator
+func (c *Config) TopologyConfig() TopologyConfig {
+	return c.Topology
+}
+
+//AlertStore returns the store fed by the Alertmanager webhook receiver, nil if the receiver is disabled
+func (c *Config) AlertStore() *alertmanager.Store {
+	if !c.AlertmanagerWebhook {
+		return nil
+	}
+	return alertmanager.NewStore(c.AlertmanagerAlertMaxAge)
+}
+
+//AlertmanagerToken returns the bearer token required on the Alertmanager webhook receiver, empty if the receiver is disabled
+func (c *Config) AlertmanagerToken() string {
+	if !c.AlertmanagerWebhook {
+		return ""
+	}
+	return readToken(c.AlertmanagerWebhookTokenFile, "alertmanager webhook", c.logger)
+}
+
+// readToken reads a bearer token from a file, the endpoint can't be served without it
+func readToken(file, endpoint string, logger *zap.Logger) string {
+	sugar := logger.Sugar()
+	if file == "" {
+		sugar.Fatalf("The %s endpoint requires a token file", endpoint)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		sugar.Fatalf("Unable to read the %s token file: %v", endpoint, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		sugar.Fatalf("The %s token file %s is empty", endpoint, file)
+	}
+	return token
+}
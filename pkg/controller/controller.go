@@ -31,7 +31,10 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
+	"github.com/amadeusitgroup/kubervisor/pkg/anomalydetector"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/breaker"
 	bclient "github.com/amadeusitgroup/kubervisor/pkg/client/clientset/versioned"
 	binformers "github.com/amadeusitgroup/kubervisor/pkg/client/informers/externalversions"
 	"github.com/amadeusitgroup/kubervisor/pkg/client/informers/externalversions/kubervisor/v1alpha1"
@@ -40,6 +43,7 @@ import (
 	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
 	election "github.com/amadeusitgroup/kubervisor/pkg/leaderelection"
 	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
 )
 
 func init() {
@@ -90,12 +94,19 @@ type Controller struct {
 	breakerLister blisters.KubervisorServiceLister
 	BreakerSynced cache.InformerSynced
 
-	podLister corev1listers.PodLister
-	PodSynced cache.InformerSynced
+	podLister  corev1listers.PodLister
+	podIndexer cache.Indexer
+	PodSynced  cache.InformerSynced
 
 	serviceLister corev1listers.ServiceLister
 	ServiceSynced cache.InformerSynced
 
+	nodeLister corev1listers.NodeLister
+	NodeSynced cache.InformerSynced
+
+	reportLister blisters.PodAnomalyReportLister
+	ReportSynced cache.InformerSynced
+
 	queue       workqueue.RateLimitingInterface // KubervisorServices to be synced
 	enqueueFunc func(bc *api.KubervisorService)
 
@@ -110,7 +121,24 @@ type Controller struct {
 
 	httpServer *http.Server
 
-	gc *garbageCollector
+	// store fed by the remote-write endpoint, nil if the endpoint is disabled
+	remoteWriteStore *remotewrite.Store
+	// bearer token required on the remote-write endpoint
+	remoteWriteToken string
+	// store fed by the Alertmanager webhook receiver, nil if the receiver is disabled
+	alertStore *alertmanager.Store
+	// bearer token required on the Alertmanager webhook receiver
+	alertmanagerToken string
+
+	gc       *garbageCollector
+	reportGC *reportGarbageCollector
+
+	// correlator of the broken pods by node and by zone
+	topology *topologyCorrelator
+	// nodeLimiter caps the number of broken pods by node, nil for no limit
+	nodeLimiter breaker.NodeLimiter
+	// anomalyRecorder receives the pods out of bounds for the bad node and bad zone detection, nil if disabled
+	anomalyRecorder breaker.AnomalyRecorder
 }
 
 //Initializer prepare/return all dependencies for controller creation
@@ -120,6 +148,11 @@ type Initializer interface {
 	Logger() *zap.Logger
 	NbWorker() uint32
 	HTTPServer() *http.Server
+	RemoteWriteStore() *remotewrite.Store
+	RemoteWriteToken() string
+	AlertStore() *alertmanager.Store
+	AlertmanagerToken() string
+	TopologyConfig() TopologyConfig
 }
 
 // New returns new Controller instance
@@ -143,7 +176,13 @@ func New(initializer Initializer) *Controller {
 
 	podInformer := kubeInformerFactory.Core().V1().Pods()
 	serviceInformer := kubeInformerFactory.Core().V1().Services()
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
 	breakerInformer := breakerInformerFactory.Kubervisor().V1alpha1().KubervisorServices()
+	reportInformer := breakerInformerFactory.Kubervisor().V1alpha1().PodAnomalyReports()
+
+	if err := podInformer.Informer().AddIndexers(anomalydetector.PodIndexers); err != nil {
+		sugar.Fatalf("Failed to add the pod indexers: %v", err)
+	}
 
 	id, err := os.Hostname()
 	if err != nil {
@@ -177,11 +216,16 @@ func New(initializer Initializer) *Controller {
 		breakerInformer:        breakerInformer,
 		breakerClient:          breakerClient,
 		podLister:              podInformer.Lister(),
+		podIndexer:             podInformer.Informer().GetIndexer(),
 		PodSynced:              podInformer.Informer().HasSynced,
 		serviceLister:          serviceInformer.Lister(),
 		ServiceSynced:          serviceInformer.Informer().HasSynced,
+		nodeLister:             nodeInformer.Lister(),
+		NodeSynced:             nodeInformer.Informer().HasSynced,
 		breakerLister:          breakerInformer.Lister(),
 		BreakerSynced:          breakerInformer.Informer().HasSynced,
+		reportLister:           reportInformer.Lister(),
+		ReportSynced:           reportInformer.Informer().HasSynced,
 
 		podControl:            pod.NewPodControl(kubeClient),
 		rootContext:           ctx,
@@ -189,7 +233,11 @@ func New(initializer Initializer) *Controller {
 
 		items: item.NewBreackerConfigItemStore(),
 
-		httpServer: initializer.HTTPServer(),
+		httpServer:        initializer.HTTPServer(),
+		remoteWriteStore:  initializer.RemoteWriteStore(),
+		remoteWriteToken:  initializer.RemoteWriteToken(),
+		alertStore:        initializer.AlertStore(),
+		alertmanagerToken: initializer.AlertmanagerToken(),
 
 		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "kubervisorservice"),
 		recorder: recorder,
@@ -227,6 +275,21 @@ func New(initializer Initializer) *Controller {
 	if err != nil {
 		sugar.Fatalf("Unable to initialize garbage collector: %v", err)
 	}
+	ctrl.reportGC, err = newReportGarbageCollector(time.Minute, ctrl.breakerClient, ctrl.reportLister, ctrl.Logger)
+	if err != nil {
+		sugar.Fatalf("Unable to initialize report garbage collector: %v", err)
+	}
+	topologyConfig := initializer.TopologyConfig()
+	ctrl.topology, err = newTopologyCorrelator(topologyConfig, ctrl.kubeClient, ctrl.podLister, ctrl.nodeLister, ctrl.recorder, ctrl.Logger)
+	if err != nil {
+		sugar.Fatalf("Unable to initialize topology correlator: %v", err)
+	}
+	if topologyConfig.MaxBrokenPodsPerNode > 0 {
+		ctrl.nodeLimiter = ctrl.topology
+	}
+	if topologyConfig.NodeThreshold > 0 || topologyConfig.ZoneThreshold > 0 {
+		ctrl.anomalyRecorder = ctrl.topology
+	}
 
 	return ctrl
 }
@@ -238,6 +301,7 @@ func (ctrl *Controller) Run(stop <-chan struct{}) error {
 	ctrl.breakerInformerFactory.Start(stop)
 	go ctrl.runHTTPServer(stop)
 	go ctrl.gc.run(stop)
+	go ctrl.reportGC.run(stop)
 
 	// Simple run if no leader election
 	if ctrl.locker == nil {
@@ -262,9 +326,11 @@ func (ctrl *Controller) Run(stop <-chan struct{}) error {
 }
 
 func (ctrl *Controller) run(stop <-chan struct{}) error {
-	if !cache.WaitForCacheSync(stop, ctrl.PodSynced) {
+	if !cache.WaitForCacheSync(stop, ctrl.PodSynced, ctrl.NodeSynced) {
 		return fmt.Errorf("Timed out waiting for caches to sync")
 	}
+	// the topology correlator acts on the nodes: only the leader runs it
+	go ctrl.topology.run(stop)
 
 	for i := uint32(0); i < ctrl.nbWorker; i++ {
 		go wait.Until(ctrl.runWorker, time.Second, stop)
@@ -456,6 +522,13 @@ func (ctrl *Controller) syncKubervisorService(bc *api.KubervisorService) (bool,
 			return false, err
 		}
 	}
+	if newStatus, updated := UpdateStatusConditionFleetIncident(&bc.Status, bci.FleetIncidents(), now); updated {
+		bc.Status = *newStatus
+		if _, err := ctrl.updateHandlerFunc(bc); err != nil {
+			ctrl.Logger.Sugar().Errorf("Unable to update status for CRD %s/%s", bc.Namespace, bc.Name)
+			return false, err
+		}
+	}
 	return false, nil
 }
 
@@ -610,7 +683,17 @@ func (ctrl *Controller) newKubervisorServiceItem(bc *api.KubervisorService, svc
 		Logger:     ctrl.Logger,
 		Selector:   selectorWithoutTrafficKey,
 		PodLister:  ctrl.podLister,
+		PodIndexer: ctrl.podIndexer,
 		PodControl: ctrl.podControl,
+		NodeLister: ctrl.nodeLister,
+
+		KubeClient:       ctrl.kubeClient,
+		RemoteWriteStore: ctrl.remoteWriteStore,
+		ReportLister:     ctrl.reportLister,
+		AlertStore:       ctrl.alertStore,
+		Recorder:         ctrl.recorder,
+		NodeLimiter:      ctrl.nodeLimiter,
+		AnomalyRecorder:  ctrl.anomalyRecorder,
 	}
 	bci, err := item.New(bc, itemConfig)
 	if err != nil {
@@ -10,12 +10,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
 	bclient "github.com/amadeusitgroup/kubervisor/pkg/client/clientset/versioned"
 	"github.com/amadeusitgroup/kubervisor/pkg/client/clientset/versioned/fake"
 	"github.com/amadeusitgroup/kubervisor/pkg/controller/item"
 	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
 	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
 	test "github.com/amadeusitgroup/kubervisor/test"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -195,6 +197,21 @@ func (i *testInitializer) Logger() *zap.Logger {
 func (i *testInitializer) NbWorker() uint32 {
 	return i.nbWorker
 }
+func (i *testInitializer) RemoteWriteStore() *remotewrite.Store {
+	return remotewrite.NewStore(remotewrite.Config{PodLabels: []string{"pod"}, MaxSeries: 100, Retention: time.Minute})
+}
+func (i *testInitializer) RemoteWriteToken() string {
+	return "remote-write-token"
+}
+func (i *testInitializer) AlertStore() *alertmanager.Store {
+	return alertmanager.NewStore(time.Hour)
+}
+func (i *testInitializer) AlertmanagerToken() string {
+	return "alertmanager-token"
+}
+func (i *testInitializer) TopologyConfig() TopologyConfig {
+	return TopologyConfig{Period: time.Second, NodeAction: NodeActionNone}
+}
 func (i *testInitializer) HTTPServer() *http.Server {
 	port, err := i.getFreePort()
 	if err != nil {
@@ -540,3 +557,4 @@ func (f fakeItem) CompareWithSpec(spec *api.KubervisorServiceSpec, selector labe
 func (f fakeItem) GetStatus() (api.PodCountStatus, error) {
 	return api.PodCountStatus{}, nil
 }
+func (f fakeItem) FleetIncidents() []string { return nil }
@@ -2,11 +2,16 @@ package controller
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/heptiolabs/healthcheck"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
 )
 
 func (ctrl *Controller) runHTTPServer(stop <-chan struct{}) error {
@@ -27,10 +32,30 @@ func (ctrl *Controller) runHTTPServer(stop <-chan struct{}) error {
 func (ctrl *Controller) configureHTTPServer() {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	if ctrl.remoteWriteStore != nil {
+		mux.Handle("/api/v1/write", bearerTokenHandler(ctrl.remoteWriteToken, remotewrite.Handler(ctrl.remoteWriteStore, ctrl.Logger)))
+	}
+	if ctrl.alertStore != nil {
+		mux.Handle("/api/v1/alerts", bearerTokenHandler(ctrl.alertmanagerToken, alertmanager.Handler(ctrl.alertStore, ctrl.Logger)))
+	}
 	mux.Handle("/", ctrl.configureHealth())
 	ctrl.httpServer.Handler = mux
 }
 
+// bearerTokenHandler rejects the requests without the expected bearer token, all of them if the token is empty
+func bearerTokenHandler(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		auth := r.Header.Get("Authorization")
+		if token == "" || !strings.HasPrefix(auth, prefix) || subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (ctrl *Controller) configureHealth() http.Handler {
 	ctrl.health = healthcheck.NewHandler()
 	ctrl.health.AddReadinessCheck("KubervisorService_cache_sync", func() error {
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_bearerTokenHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	tests := []struct {
+		name  string
+		token string
+		auth  string
+		want  int
+	}{
+		{name: "no header", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", auth: "Bearer other", want: http.StatusUnauthorized},
+		{name: "not bearer", token: "secret", auth: "Basic secret", want: http.StatusUnauthorized},
+		{name: "empty token", token: "", auth: "Bearer ", want: http.StatusUnauthorized},
+		{name: "ok", token: "secret", auth: "Bearer secret", want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/write", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			bearerTokenHandler(tt.token, next).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("bearerTokenHandler() status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package item
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/breaker"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+)
+
+// pendingTimeout duration during which a reserved pod is counted as broken while the pod cache does not show it broken yet
+const pendingTimeout = time.Minute
+
+var _ breaker.BreakBudget = &breakBudget{}
+
+//breakBudget break slots shared by the breakers of a KubervisorService: the reservations are serialized so that the breakers together can't break more pods than any of them allows
+type breakBudget struct {
+	maxUnavailable *intstr.IntOrString
+	selector       labels.Selector
+	podLister      kv1.PodNamespaceLister
+	logger         *zap.Logger
+	now            func() time.Time
+
+	lock sync.Mutex
+	// pending reservation time of the pods reserved and not yet seen as broken in the pod cache
+	pending map[string]time.Time
+}
+
+func newBreakBudget(maxUnavailable *intstr.IntOrString, selector labels.Selector, podLister kv1.PodNamespaceLister, logger *zap.Logger) *breakBudget {
+	return &breakBudget{
+		maxUnavailable: maxUnavailable,
+		selector:       selector,
+		podLister:      podLister,
+		logger:         logger,
+		now:            time.Now,
+		pending:        map[string]time.Time{},
+	}
+}
+
+//Reserve implements breaker.BreakBudget
+func (b *breakBudget) Reserve(p *kapiv1.Pod, minAvailable int) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	allPods, err := b.podLister.List(b.selector)
+	if err != nil {
+		b.logger.Sugar().Errorf("break budget can't list pods, error: %v", err)
+		return false
+	}
+	runningPods, err := pod.KeepRunningPods(allPods)
+	if err != nil {
+		b.logger.Sugar().Errorf("break budget can't get running pods, error: %v", err)
+		return false
+	}
+
+	now := b.now()
+	trafficByName := map[string]bool{}
+	for _, rp := range runningPods {
+		yes, _, err := labeling.IsPodTrafficLabelOkOrPause(rp)
+		trafficByName[rp.Name] = err == nil && yes
+	}
+	for name, since := range b.pending {
+		if traffic, ok := trafficByName[name]; !ok || !traffic || now.Sub(since) > pendingTimeout {
+			delete(b.pending, name)
+		}
+	}
+	if _, ok := b.pending[p.Name]; ok {
+		// already reserved by another breaker
+		return false
+	}
+
+	withTraffic, unavailable := 0, len(b.pending)
+	for _, rp := range runningPods {
+		if !trafficByName[rp.Name] {
+			// broken or paused
+			unavailable++
+			continue
+		}
+		if _, ok := b.pending[rp.Name]; !ok && pod.IsReady(rp) {
+			withTraffic++
+		}
+	}
+	if withTraffic-1 < minAvailable {
+		return false
+	}
+	if b.maxUnavailable != nil {
+		maxUnavailable, err := intstr.GetValueFromIntOrPercent(b.maxUnavailable, len(runningPods), false)
+		if err != nil {
+			b.logger.Sugar().Errorf("break budget bad max unavailable, error: %v", err)
+			return false
+		}
+		if unavailable+1 > maxUnavailable {
+			return false
+		}
+	}
+	b.pending[p.Name] = now
+	return true
+}
+
+//Release implements breaker.BreakBudget
+func (b *breakBudget) Release(p *kapiv1.Pod) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.pending, p.Name)
+}
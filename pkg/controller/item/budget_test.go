@@ -0,0 +1,105 @@
+package item
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func Test_breakBudget_Reserve(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	podGen := func(name string, traffic labeling.LabelTraffic) *kapiv1.Pod {
+		return test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, traffic)
+	}
+	A, B, C, D := podGen("A", labeling.LabelTrafficYes), podGen("B", labeling.LabelTrafficYes), podGen("C", labeling.LabelTrafficYes), podGen("D", labeling.LabelTrafficYes)
+	P := podGen("P", labeling.LabelTrafficPause)
+	fiftyPercent := intstr.FromString("50%")
+	one := intstr.FromInt(1)
+
+	tests := []struct {
+		name           string
+		maxUnavailable *intstr.IntOrString
+		pods           []*kapiv1.Pod
+		minAvailable   int
+		reserve        []*kapiv1.Pod
+		want           []bool
+	}{
+		{
+			name:         "min available shared by the breakers",
+			pods:         []*kapiv1.Pod{A, B, C, D},
+			minAvailable: 2,
+			reserve:      []*kapiv1.Pod{A, B, C},
+			want:         []bool{true, true, false},
+		},
+		{
+			name:         "pod already reserved",
+			pods:         []*kapiv1.Pod{A, B, C, D},
+			minAvailable: 1,
+			reserve:      []*kapiv1.Pod{A, A},
+			want:         []bool{true, false},
+		},
+		{
+			name:           "paused pods count in max unavailable",
+			maxUnavailable: &fiftyPercent,
+			pods:           []*kapiv1.Pod{A, B, C, P},
+			minAvailable:   1,
+			reserve:        []*kapiv1.Pod{A, B},
+			want:           []bool{true, false},
+		},
+		{
+			name:           "max unavailable count",
+			maxUnavailable: &one,
+			pods:           []*kapiv1.Pod{A, B, C, P},
+			minAvailable:   1,
+			reserve:        []*kapiv1.Pod{A},
+			want:           []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBreakBudget(tt.maxUnavailable, labels.Everything(), test.NewTestPodNamespaceLister(tt.pods, "test-ns"), devlogger)
+			for i, p := range tt.reserve {
+				if got := b.Reserve(p, tt.minAvailable); got != tt.want[i] {
+					t.Errorf("breakBudget.Reserve(%s) #%d = %v, want %v", p.Name, i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_breakBudget_pending(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	podGen := func(name string, traffic labeling.LabelTraffic) *kapiv1.Pod {
+		return test.PodGen(name, "test-ns", map[string]string{"app": "foo"}, nil, true, true, traffic)
+	}
+	A, B, C := podGen("A", labeling.LabelTrafficYes), podGen("B", labeling.LabelTrafficYes), podGen("C", labeling.LabelTrafficYes)
+	one := intstr.FromInt(1)
+
+	now := time.Now()
+	b := newBreakBudget(&one, labels.Everything(), test.NewTestPodNamespaceLister([]*kapiv1.Pod{A, B, C}, "test-ns"), devlogger)
+	b.now = func() time.Time { return now }
+
+	if !b.Reserve(A, 1) {
+		t.Fatalf("the first reservation should be granted")
+	}
+	if b.Reserve(B, 1) {
+		t.Errorf("the reservation of A is pending: no slot should be left")
+	}
+	// A could not be broken
+	b.Release(A)
+	if !b.Reserve(B, 1) {
+		t.Errorf("the slot released by A should be granted")
+	}
+	// B is never seen broken in the cache
+	now = now.Add(pendingTimeout + time.Second)
+	if !b.Reserve(C, 1) {
+		t.Errorf("the reservation of B should have expired")
+	}
+}
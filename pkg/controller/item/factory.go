@@ -5,13 +5,19 @@ import (
 
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
 	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	activator "github.com/amadeusitgroup/kubervisor/pkg/activate"
+	"github.com/amadeusitgroup/kubervisor/pkg/alertmanager"
 	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
 	"github.com/amadeusitgroup/kubervisor/pkg/breaker"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
 	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
 	"github.com/amadeusitgroup/kubervisor/pkg/pod"
+	"github.com/amadeusitgroup/kubervisor/pkg/remotewrite"
 )
 
 // New return new KubervisorServiceItem instance
@@ -41,19 +47,40 @@ func New(bc *api.KubervisorService, cfg *Config) (Interface, error) {
 		return nil, err
 	}
 
+	budget := newBreakBudget(bc.Spec.MaxUnavailable, augmentedSelector, namespacedPodLister, cfg.Logger)
+	var rateLimiter *breakRateLimiter
+	if bc.Spec.BreakRateLimit != nil {
+		rateLimiter = newBreakRateLimiter(*bc.Spec.BreakRateLimit)
+	}
 	baPairs := []breakerActivatorPair{}
 	for _, bspec := range bc.Spec.Breakers {
 		breakerConfig := breaker.FactoryConfig{
 			Config: breaker.Config{
 				KubervisorName:        bc.Name,
+				Namespace:             bc.Namespace,
 				StrategyName:          bspec.Name,
 				Selector:              augmentedSelector,
 				BreakerStrategyConfig: bspec,
 				PodControl:            cfg.PodControl,
 				PodLister:             namespacedPodLister,
+				PodIndexer:            cfg.PodIndexer,
+				NodeLister:            cfg.NodeLister,
+				KubeClient:            cfg.KubeClient,
+				RemoteWriteStore:      cfg.RemoteWriteStore,
+				AlertStore:            cfg.AlertStore,
+				Recorder:              cfg.Recorder,
+				NodeLimiter:           cfg.NodeLimiter,
+				AnomalyRecorder:       cfg.AnomalyRecorder,
+				BreakBudget:           budget,
 				Logger:                cfg.Logger,
 			},
 		}
+		if cfg.ReportLister != nil {
+			breakerConfig.ReportLister = cfg.ReportLister.PodAnomalyReports(bc.Namespace)
+		}
+		if rateLimiter != nil {
+			breakerConfig.BreakRateLimiter = rateLimiter
+		}
 		breakerInterface, err := breaker.New(breakerConfig)
 		if err != nil {
 			return nil, err
@@ -89,6 +116,8 @@ func New(bc *api.KubervisorService, cfg *Config) (Interface, error) {
 		selector:         augmentedSelector,
 		defaultActivator: activatorDefaultInterface,
 		breakers:         baPairs,
+		budget:           budget,
+		rateLimiter:      rateLimiter,
 		podLister:        namespacedPodLister,
 	}, nil
 
@@ -98,9 +127,19 @@ func New(bc *api.KubervisorService, cfg *Config) (Interface, error) {
 type Config struct {
 	Selector   labels.Selector
 	PodLister  kv1.PodLister
+	PodIndexer cache.Indexer
 	PodControl pod.ControlInterface
+	NodeLister kv1.NodeLister
 	Logger     *zap.Logger
 
+	KubeClient       clientset.Interface
+	RemoteWriteStore *remotewrite.Store
+	ReportLister     blisters.PodAnomalyReportLister
+	AlertStore       *alertmanager.Store
+	Recorder         record.EventRecorder
+	NodeLimiter      breaker.NodeLimiter
+	AnomalyRecorder  breaker.AnomalyRecorder
+
 	customFactory Factory
 }
 
@@ -0,0 +1,58 @@
+package item
+
+import (
+	"sync"
+	"time"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/breaker"
+)
+
+var _ breaker.BreakRateLimiter = &breakRateLimiter{}
+
+//breakRateLimiter sliding window limiting the number of breaks of the breakers of a KubervisorService
+type breakRateLimiter struct {
+	config    api.BreakRateLimit
+	maxBreaks int
+	period    time.Duration
+	now       func() time.Time
+
+	lock sync.Mutex
+	// breaks times of the breaks in the window, oldest first
+	breaks []time.Time
+}
+
+func newBreakRateLimiter(config api.BreakRateLimit) *breakRateLimiter {
+	return &breakRateLimiter{
+		config:    config,
+		maxBreaks: int(*config.MaxBreaks),
+		period:    time.Duration(*config.Period*1000) * time.Millisecond,
+		now:       time.Now,
+	}
+}
+
+//Take implements breaker.BreakRateLimiter
+func (l *breakRateLimiter) Take() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	expired := 0
+	for expired < len(l.breaks) && now.Sub(l.breaks[expired]) >= l.period {
+		expired++
+	}
+	l.breaks = l.breaks[expired:]
+	if len(l.breaks) >= l.maxBreaks {
+		return false
+	}
+	l.breaks = append(l.breaks, now)
+	return true
+}
+
+//Return implements breaker.BreakRateLimiter
+func (l *breakRateLimiter) Return() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if len(l.breaks) > 0 {
+		l.breaks = l.breaks[:len(l.breaks)-1]
+	}
+}
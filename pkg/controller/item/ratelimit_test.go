@@ -0,0 +1,34 @@
+package item
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+)
+
+func Test_breakRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := newBreakRateLimiter(api.BreakRateLimit{MaxBreaks: api.NewUInt(2), Period: api.NewFloat64(300)})
+	l.now = func() time.Time { return now }
+
+	take := func(step string, want bool) {
+		if got := l.Take(); got != want {
+			t.Errorf("%s: breakRateLimiter.Take() = %v, want %v", step, got, want)
+		}
+	}
+
+	take("first break", true)
+	now = now.Add(time.Minute)
+	take("second break", true)
+	take("limit reached", false)
+
+	// a break that did not happen is given back
+	l.Return()
+	take("returned break", true)
+
+	// the first break leaves the window
+	now = now.Add(4 * time.Minute)
+	take("window slides", true)
+	take("limit reached again", false)
+}
@@ -3,6 +3,7 @@ package item
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/labels"
@@ -48,6 +49,7 @@ type Interface interface {
 	Stop() error
 	CompareWithSpec(spec *api.KubervisorServiceSpec, selector labels.Selector) bool
 	GetStatus() (api.PodCountStatus, error)
+	FleetIncidents() []string
 }
 
 type breakerActivatorPair struct {
@@ -77,6 +79,9 @@ type KubervisorServiceItem struct {
 	namespace        string
 	defaultActivator activator.Activator
 	breakers         []breakerActivatorPair
+	// budget and rate limiter shared by the breakers
+	budget      *breakBudget
+	rateLimiter *breakRateLimiter
 
 	podLister kv1.PodNamespaceLister
 	selector  labels.Selector
@@ -135,6 +140,15 @@ func (b *KubervisorServiceItem) CompareWithSpec(spec *api.KubervisorServiceSpec,
 	if len(spec.Breakers) != len(b.breakers) {
 		return true
 	}
+	if b.budget != nil && !reflect.DeepEqual(spec.MaxUnavailable, b.budget.maxUnavailable) {
+		return true
+	}
+	if (b.rateLimiter == nil) != (spec.BreakRateLimit == nil) {
+		return true
+	}
+	if b.rateLimiter != nil && !reflect.DeepEqual(*spec.BreakRateLimit, b.rateLimiter.config) {
+		return true
+	}
 	for i, breakerStrategy := range spec.Breakers {
 		breaker := b.getBreakerByStrategyName(breakerStrategy.Name)
 		if breaker == nil {
@@ -184,3 +198,14 @@ func (b *KubervisorServiceItem) GetStatus() (api.PodCountStatus, error) {
 	}
 	return status, nil
 }
+
+//FleetIncidents return the ongoing fleet incidents of the breakers that request the FleetIncident condition
+func (b *KubervisorServiceItem) FleetIncidents() []string {
+	incidents := []string{}
+	for _, baPair := range b.breakers {
+		if reason := baPair.breaker.FleetIncident(); reason != "" {
+			incidents = append(incidents, fmt.Sprintf("%s: %s", baPair.breaker.Name(), reason))
+		}
+	}
+	return incidents
+}
@@ -14,6 +14,7 @@ import (
 	kapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	kv1 "k8s.io/client-go/listers/core/v1"
 )
 
@@ -178,6 +179,43 @@ func TestKubervisorServiceItem_CompareWithSpec(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "max unavailable change",
+			args: args{
+				spec:     &bc.Spec,
+				selector: labels.Set(map[string]string{"app": "foo"}).AsSelector(),
+			},
+			init: func() Interface {
+				bc2 := bc.DeepCopy()
+				maxUnavailable := intstr.FromString("20%")
+				bc2.Spec.MaxUnavailable = &maxUnavailable
+				i, err := New(bc2, cfg)
+				if err != nil {
+					t.Fatalf("Factory did not return an Interface: %v", err)
+					return nil
+				}
+				return i
+			},
+			want: true,
+		},
+		{
+			name: "break rate limit change",
+			args: args{
+				spec:     &bc.Spec,
+				selector: labels.Set(map[string]string{"app": "foo"}).AsSelector(),
+			},
+			init: func() Interface {
+				bc2 := bc.DeepCopy()
+				bc2.Spec.BreakRateLimit = &api.BreakRateLimit{MaxBreaks: api.NewUInt(3), Period: api.NewFloat64(300)}
+				i, err := New(bc2, cfg)
+				if err != nil {
+					t.Fatalf("Factory did not return an Interface: %v", err)
+					return nil
+				}
+				return i
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -244,7 +282,8 @@ func (f *fakeBreaker) Run(stop <-chan struct{}) {
 func (f *fakeBreaker) CompareConfig(specConfig *api.BreakerStrategy, specSelector labels.Selector) bool {
 	return true
 }
-func (f *fakeBreaker) Name() string { return "Name" }
+func (f *fakeBreaker) Name() string          { return "Name" }
+func (f *fakeBreaker) FleetIncident() string { return "" }
 
 func TestStartStop(t *testing.T) {
 	// The failure of that test will consist in a timeout in case the sequence does not complete
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	bclient "github.com/amadeusitgroup/kubervisor/pkg/client/clientset/versioned"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
+)
+
+//reportGarbageCollector deletes the PodAnomalyReports that are expired
+type reportGarbageCollector struct {
+	logger        *zap.Logger
+	reportLister  blisters.PodAnomalyReportLister
+	breakerClient bclient.Interface
+	period        time.Duration
+	now           func() time.Time
+}
+
+func newReportGarbageCollector(period time.Duration, breakerClient bclient.Interface, reportLister blisters.PodAnomalyReportLister, logger *zap.Logger) (*reportGarbageCollector, error) {
+	if logger == nil || breakerClient == nil || reportLister == nil || period.Seconds() == 0.0 {
+		return nil, fmt.Errorf("Bad report GC parameter(s)")
+	}
+
+	return &reportGarbageCollector{
+		period:        period,
+		logger:        logger,
+		reportLister:  reportLister,
+		breakerClient: breakerClient,
+		now:           time.Now,
+	}, nil
+}
+
+func (gc *reportGarbageCollector) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(gc.period)
+	for {
+		select {
+		case <-ticker.C:
+			gc.cleanReports()
+
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (gc *reportGarbageCollector) cleanReports() {
+	reports, err := gc.reportLister.List(labels.Everything())
+	if err != nil {
+		gc.logger.Sugar().Errorf("GC Can't list pod anomaly reports: %v", err)
+		return
+	}
+	now := gc.now()
+	for _, r := range reports {
+		if r.Spec.Expiry.Time.After(now) {
+			continue
+		}
+		err := gc.breakerClient.KubervisorV1alpha1().PodAnomalyReports(r.Namespace).Delete(r.Name, nil)
+		if err != nil && !apierrors.IsNotFound(err) {
+			gc.logger.Sugar().Errorf("GC Can't delete expired pod anomaly report %s/%s. Maybe at next iteration. Error: %v", r.Namespace, r.Name, err)
+			continue
+		}
+		gc.logger.Sugar().Debugf("GC expired pod anomaly report %s/%s deleted", r.Namespace, r.Name)
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/client/clientset/versioned/fake"
+	blisters "github.com/amadeusitgroup/kubervisor/pkg/client/listers/kubervisor/v1alpha1"
+)
+
+func Test_reportGarbageCollector_cleanReports(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	reports := []*api.PodAnomalyReport{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "expired", Namespace: "test-ns"},
+			Spec:       api.PodAnomalyReportSpec{PodName: "A", Source: "lb", Severity: api.PodAnomalyReportSeverityCritical, Expiry: metav1.NewTime(now.Add(-time.Second))},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "active", Namespace: "test-ns"},
+			Spec:       api.PodAnomalyReportSpec{PodName: "B", Source: "lb", Severity: api.PodAnomalyReportSeverityCritical, Expiry: metav1.NewTime(now.Add(time.Minute))},
+		},
+	}
+
+	client := fake.NewSimpleClientset(reports[0], reports[1])
+	index := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, r := range reports {
+		index.Add(r)
+	}
+
+	gc, err := newReportGarbageCollector(time.Second, client, blisters.NewPodAnomalyReportLister(index), devlogger)
+	if err != nil {
+		t.Fatalf("newReportGarbageCollector() error = %v", err)
+	}
+	gc.now = func() time.Time { return now }
+	gc.cleanReports()
+
+	list, err := client.KubervisorV1alpha1().PodAnomalyReports("test-ns").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "active" {
+		t.Errorf("remaining reports = %v, want only active", list.Items)
+	}
+
+	if _, err := newReportGarbageCollector(time.Second, nil, nil, devlogger); err == nil {
+		t.Errorf("newReportGarbageCollector() with nil parameters should fail")
+	}
+}
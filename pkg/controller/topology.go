@@ -0,0 +1,399 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+	"github.com/amadeusitgroup/kubervisor/pkg/breaker"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+)
+
+func init() {
+	prometheus.MustRegister(nodeBrokenPodsGauges)
+	prometheus.MustRegister(zoneBrokenPodsGauges)
+	prometheus.MustRegister(badNodeGauges)
+	prometheus.MustRegister(badZoneGauges)
+}
+
+// anomaliesTimeout duration after which the out of bounds pods recorded by a breaker are ignored: the breaker was deleted or its evaluation fails
+const anomaliesTimeout = 5 * time.Minute
+
+var (
+	nodeBrokenPodsGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_node_broken_pods",
+			Help: "Number of pods broken by kubervisor on the node",
+		},
+		[]string{"node"},
+	)
+
+	zoneBrokenPodsGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_zone_broken_pods",
+			Help: "Number of pods broken by kubervisor in the zone",
+		},
+		[]string{"zone"},
+	)
+
+	badNodeGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_bad_node",
+			Help: "1 if the node has broken or out of bounds pods of more KubervisorServices than the node threshold",
+		},
+		[]string{"node"},
+	)
+
+	badZoneGauges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubervisor_bad_zone",
+			Help: "1 if the zone has broken or out of bounds pods of more KubervisorServices than the zone threshold",
+		},
+		[]string{"zone"},
+	)
+)
+
+var _ breaker.NodeLimiter = &topologyCorrelator{}
+var _ breaker.AnomalyRecorder = &topologyCorrelator{}
+
+//topologyCorrelator groups the broken and out of bounds pods of all the KubervisorServices by node and by zone, to detect the bad nodes and zones and to cap the broken pods by node
+//The out of bounds pods are taken into account so that a bad node or zone is detected before its pods are broken, or when the breaks are blocked
+type topologyCorrelator struct {
+	logger     *zap.Logger
+	config     TopologyConfig
+	kubeClient clientset.Interface
+	podLister  corev1listers.PodLister
+	nodeLister corev1listers.NodeLister
+	recorder   record.EventRecorder
+	now        func() time.Time
+
+	anomaliesLock sync.Mutex
+	// anomalies last out of bounds pods recorded by each breaker
+	anomalies map[string]recordedAnomalies
+
+	// badNodes and badZones are only accessed by the correlator goroutine
+	badNodes map[string]struct{}
+	// badZones KubervisorServices of each bad zone when it was detected
+	badZones map[string]map[string]struct{}
+}
+
+// recordedAnomalies out of bounds pods found by an evaluation of a breaker
+type recordedAnomalies struct {
+	service string
+	time    time.Time
+	nodes   []string
+}
+
+func newTopologyCorrelator(config TopologyConfig, kubeClient clientset.Interface, podLister corev1listers.PodLister, nodeLister corev1listers.NodeLister, recorder record.EventRecorder, logger *zap.Logger) (*topologyCorrelator, error) {
+	if logger == nil || kubeClient == nil || podLister == nil || nodeLister == nil || config.Period <= 0 || config.NodeThreshold < 0 || config.ZoneThreshold < 0 || config.MaxBrokenPodsPerNode < 0 {
+		return nil, fmt.Errorf("Bad topology correlator parameter(s)")
+	}
+	switch config.NodeAction {
+	case "", NodeActionNone, NodeActionCordon, NodeActionTaint:
+	default:
+		return nil, fmt.Errorf("Bad topology node action %q", config.NodeAction)
+	}
+
+	return &topologyCorrelator{
+		config:     config,
+		logger:     logger,
+		kubeClient: kubeClient,
+		podLister:  podLister,
+		nodeLister: nodeLister,
+		recorder:   recorder,
+		now:        time.Now,
+		anomalies:  map[string]recordedAnomalies{},
+		badNodes:   map[string]struct{}{},
+		badZones:   map[string]map[string]struct{}{},
+	}, nil
+}
+
+func (c *topologyCorrelator) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.config.Period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.correlate()
+
+		case <-stop:
+			return
+		}
+	}
+}
+
+//RemainingBreaks implements the breaker.NodeLimiter interface
+func (c *topologyCorrelator) RemainingBreaks(nodeName string) int {
+	if c.config.MaxBrokenPodsPerNode == 0 {
+		return int(^uint(0) >> 1)
+	}
+	pods, err := c.podLister.List(labels.SelectorFromSet(labels.Set{labeling.LabelTrafficKey: string(labeling.LabelTrafficNo)}))
+	if err != nil {
+		c.logger.Sugar().Errorf("Topology can't list broken pods: %v", err)
+		return 0
+	}
+	broken := 0
+	for _, p := range pods {
+		if p.Spec.NodeName == nodeName {
+			broken++
+		}
+	}
+	return c.config.MaxBrokenPodsPerNode - broken
+}
+
+//RecordAnomalies implements the breaker.AnomalyRecorder interface
+func (c *topologyCorrelator) RecordAnomalies(namespace, kubervisorName, strategyName string, pods []*kapiv1.Pod) {
+	nodes := []string{}
+	for _, p := range pods {
+		if p.Spec.NodeName != "" {
+			nodes = append(nodes, p.Spec.NodeName)
+		}
+	}
+	c.anomaliesLock.Lock()
+	defer c.anomaliesLock.Unlock()
+	c.anomalies[namespace+"/"+kubervisorName+"/"+strategyName] = recordedAnomalies{service: namespace + "/" + kubervisorName, time: c.now(), nodes: nodes}
+}
+
+// recordedServicesByNode returns the KubervisorServices with recent out of bounds pods by node
+func (c *topologyCorrelator) recordedServicesByNode() map[string]map[string]struct{} {
+	c.anomaliesLock.Lock()
+	defer c.anomaliesLock.Unlock()
+	now := c.now()
+	servicesByNode := map[string]map[string]struct{}{}
+	for key, a := range c.anomalies {
+		if now.Sub(a.time) > anomaliesTimeout {
+			delete(c.anomalies, key)
+			continue
+		}
+		for _, node := range a.nodes {
+			addService(servicesByNode, node, a.service)
+		}
+	}
+	return servicesByNode
+}
+
+func (c *topologyCorrelator) correlate() {
+	pods, err := c.podLister.List(labels.SelectorFromSet(labels.Set{labeling.LabelTrafficKey: string(labeling.LabelTrafficNo)}))
+	if err != nil {
+		c.logger.Sugar().Errorf("Topology can't list broken pods: %v", err)
+		return
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		c.logger.Sugar().Errorf("Topology can't list nodes: %v", err)
+		return
+	}
+	zoneByNode := map[string]string{}
+	for _, n := range nodes {
+		zoneByNode[n.Name] = n.Labels[c.config.ZoneLabel]
+	}
+
+	brokenByNode := map[string]int{}
+	brokenByZone := map[string]int{}
+	servicesByNode := c.recordedServicesByNode()
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		brokenByNode[p.Spec.NodeName]++
+		if zone := zoneByNode[p.Spec.NodeName]; zone != "" {
+			brokenByZone[zone]++
+		}
+		addService(servicesByNode, p.Spec.NodeName, p.Namespace+"/"+p.Labels[labeling.LabelBreakerNameKey])
+	}
+
+	nodeBrokenPodsGauges.Reset()
+	for node, count := range brokenByNode {
+		nodeBrokenPodsGauges.WithLabelValues(node).Set(float64(count))
+	}
+	zoneBrokenPodsGauges.Reset()
+	for zone, count := range brokenByZone {
+		zoneBrokenPodsGauges.WithLabelValues(zone).Set(float64(count))
+	}
+
+	if c.config.NodeThreshold > 0 {
+		c.updateBadNodes(nodes, servicesByNode)
+	}
+	if c.config.ZoneThreshold > 0 {
+		servicesByZone := map[string]map[string]struct{}{}
+		for node, services := range servicesByNode {
+			if zone := zoneByNode[node]; zone != "" {
+				for service := range services {
+					addService(servicesByZone, zone, service)
+				}
+			}
+		}
+		c.updateBadZones(servicesByZone)
+	}
+}
+
+// updateBadZones compares the number of KubervisorServices with broken or out of bounds pods in each zone to the threshold, and reports the changes with events on the KubervisorServices
+func (c *topologyCorrelator) updateBadZones(servicesByZone map[string]map[string]struct{}) {
+	badZones := map[string]map[string]struct{}{}
+	for zone, services := range servicesByZone {
+		if len(services) < c.config.ZoneThreshold {
+			continue
+		}
+		if previous, ok := c.badZones[zone]; ok {
+			badZones[zone] = previous
+			continue
+		}
+		badZones[zone] = services
+		c.logger.Sugar().Warnf("Topology the zone %s is bad: broken or out of bounds pods of %d KubervisorServices", zone, len(services))
+		for service := range services {
+			c.recordServiceEvent(service, kapiv1.EventTypeWarning, "BadZone", fmt.Sprintf("pods of %d KubervisorServices are broken or out of bounds in the zone %s: %s", len(services), zone, serviceNames(services)))
+		}
+	}
+	for zone, services := range c.badZones {
+		if _, ok := badZones[zone]; !ok {
+			c.logger.Sugar().Infof("Topology the zone %s is not bad anymore", zone)
+			for service := range services {
+				c.recordServiceEvent(service, kapiv1.EventTypeNormal, "BadZoneRecovered", fmt.Sprintf("the broken or out of bounds pods in the zone %s are below the threshold", zone))
+			}
+		}
+	}
+	c.badZones = badZones
+
+	badZoneGauges.Reset()
+	for zone := range badZones {
+		badZoneGauges.WithLabelValues(zone).Set(1)
+	}
+}
+
+// updateBadNodes compares the number of KubervisorServices with broken or out of bounds pods on each node to the threshold, and applies or reverts the node action
+func (c *topologyCorrelator) updateBadNodes(nodes []*kapiv1.Node, servicesByNode map[string]map[string]struct{}) {
+	badNodes := map[string]struct{}{}
+	for node, services := range servicesByNode {
+		if len(services) < c.config.NodeThreshold {
+			continue
+		}
+		badNodes[node] = struct{}{}
+		if _, ok := c.badNodes[node]; !ok {
+			c.logger.Sugar().Warnf("Topology the node %s is bad: broken or out of bounds pods of %d KubervisorServices", node, len(services))
+			c.recordNodeEvent(node, kapiv1.EventTypeWarning, "BadNode", fmt.Sprintf("pods of %d KubervisorServices are broken or out of bounds on the node: %s", len(services), serviceNames(services)))
+		}
+	}
+	for node := range c.badNodes {
+		if _, ok := badNodes[node]; !ok {
+			c.logger.Sugar().Infof("Topology the node %s is not bad anymore", node)
+			c.recordNodeEvent(node, kapiv1.EventTypeNormal, "BadNodeRecovered", "the broken or out of bounds pods on the node are below the threshold")
+		}
+	}
+	c.badNodes = badNodes
+
+	badNodeGauges.Reset()
+	for node := range badNodes {
+		badNodeGauges.WithLabelValues(node).Set(1)
+	}
+
+	// the node action is checked against the node itself to revert it after a restart of the controller
+	for _, n := range nodes {
+		_, bad := badNodes[n.Name]
+		if err := c.applyNodeAction(n, bad); err != nil {
+			c.logger.Sugar().Errorf("Topology can't update the node %s: %v", n.Name, err)
+		}
+	}
+}
+
+// applyNodeAction cordons or taints a bad node, and reverts what was done by kubervisor on a node that is not bad anymore
+func (c *topologyCorrelator) applyNodeAction(inputNode *kapiv1.Node, bad bool) error {
+	cordoned := inputNode.Annotations[labeling.AnnotationNodeCordonedKey] != ""
+	tainted := false
+	for _, t := range inputNode.Spec.Taints {
+		if t.Key == labeling.TaintNodeDegradedKey {
+			tainted = true
+		}
+	}
+
+	//Copy to avoid modifying object inside the cache
+	n := inputNode.DeepCopy()
+	switch {
+	case bad && c.config.NodeAction == NodeActionCordon && !cordoned:
+		if n.Spec.Unschedulable {
+			// already cordoned by someone else: it must not be uncordoned by kubervisor
+			return nil
+		}
+		n.Spec.Unschedulable = true
+		if n.Annotations == nil {
+			n.Annotations = map[string]string{}
+		}
+		n.Annotations[labeling.AnnotationNodeCordonedKey] = "true"
+	case bad && c.config.NodeAction == NodeActionTaint && !tainted:
+		n.Spec.Taints = append(n.Spec.Taints, kapiv1.Taint{Key: labeling.TaintNodeDegradedKey, Value: "true", Effect: kapiv1.TaintEffectNoSchedule})
+	case !bad && (cordoned || tainted):
+		if cordoned {
+			n.Spec.Unschedulable = false
+			delete(n.Annotations, labeling.AnnotationNodeCordonedKey)
+		}
+		taints := []kapiv1.Taint{}
+		for _, t := range n.Spec.Taints {
+			if t.Key != labeling.TaintNodeDegradedKey {
+				taints = append(taints, t)
+			}
+		}
+		n.Spec.Taints = taints
+	default:
+		return nil
+	}
+	_, err := c.kubeClient.Core().Nodes().Update(n)
+	return err
+}
+
+func (c *topologyCorrelator) recordNodeEvent(nodeName, eventType, reason, message string) {
+	if c.recorder == nil {
+		return
+	}
+	ref := &kapiv1.ObjectReference{
+		Kind: "Node",
+		Name: nodeName,
+		UID:  types.UID(nodeName),
+	}
+	c.recorder.Event(ref, eventType, reason, message)
+}
+
+// recordServiceEvent records an event on the KubervisorService namespace/name
+func (c *topologyCorrelator) recordServiceEvent(service, eventType, reason, message string) {
+	if c.recorder == nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(service)
+	if err != nil {
+		return
+	}
+	ref := &kapiv1.ObjectReference{
+		Kind:       api.ResourceKind,
+		APIVersion: api.SchemeGroupVersion.String(),
+		Namespace:  namespace,
+		Name:       name,
+	}
+	c.recorder.Event(ref, eventType, reason, message)
+}
+
+func addService(servicesByKey map[string]map[string]struct{}, key, service string) {
+	if servicesByKey[key] == nil {
+		servicesByKey[key] = map[string]struct{}{}
+	}
+	servicesByKey[key][service] = struct{}{}
+}
+
+func serviceNames(services map[string]struct{}) []string {
+	names := []string{}
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func brokenPodOnNode(name, namespace, kubervisorName, nodeName string) *kapiv1.Pod {
+	p := test.PodGen(name, namespace, map[string]string{labeling.LabelBreakerNameKey: kubervisorName}, nil, true, true, labeling.LabelTrafficNo)
+	p.Spec.NodeName = nodeName
+	return p
+}
+
+func Test_newTopologyCorrelator(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	nodeLister := corev1listers.NewNodeLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+
+	tests := []struct {
+		name    string
+		config  TopologyConfig
+		logger  *zap.Logger
+		wantErr bool
+	}{
+		{
+			name:    "nil",
+			config:  TopologyConfig{Period: time.Second, NodeAction: NodeActionNone},
+			wantErr: true,
+		},
+		{
+			name:    "no period",
+			config:  TopologyConfig{NodeAction: NodeActionNone},
+			logger:  devlogger,
+			wantErr: true,
+		},
+		{
+			name:    "bad action",
+			config:  TopologyConfig{Period: time.Second, NodeAction: "drain"},
+			logger:  devlogger,
+			wantErr: true,
+		},
+		{
+			name:   "ok",
+			config: TopologyConfig{Period: time.Second, NodeThreshold: 2, ZoneThreshold: 2, NodeAction: NodeActionCordon},
+			logger: devlogger,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTopologyCorrelator(tt.config, kfakeclient.NewSimpleClientset(), test.NewTestPodLister(nil), nodeLister, nil, tt.logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newTopologyCorrelator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_topologyCorrelator_RemainingBreaks(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	pods := []*kapiv1.Pod{
+		brokenPodOnNode("A", "ns1", "foo", "node1"),
+		brokenPodOnNode("B", "ns2", "bar", "node1"),
+		test.PodGen("C", "ns1", nil, nil, true, true, labeling.LabelTrafficYes),
+	}
+	pods[2].Spec.NodeName = "node1"
+
+	c := &topologyCorrelator{
+		config:    TopologyConfig{MaxBrokenPodsPerNode: 3},
+		podLister: test.NewTestPodLister(pods),
+		logger:    devlogger,
+	}
+	if got := c.RemainingBreaks("node1"); got != 1 {
+		t.Errorf("topologyCorrelator.RemainingBreaks(node1) = %d, want 1", got)
+	}
+	if got := c.RemainingBreaks("node2"); got != 3 {
+		t.Errorf("topologyCorrelator.RemainingBreaks(node2) = %d, want 3", got)
+	}
+}
+
+func Test_topologyCorrelator_correlate(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	nodes := []*kapiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{topologyZoneLabelDefault: "zone-a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{topologyZoneLabelDefault: "zone-a"}}},
+	}
+	kubeClient := kfakeclient.NewSimpleClientset(nodes[0], nodes[1])
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, n := range nodes {
+		nodeIndex.Add(n)
+	}
+	podIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, p := range []*kapiv1.Pod{
+		brokenPodOnNode("A", "ns1", "foo", "node1"),
+		brokenPodOnNode("B", "ns1", "foo", "node1"),
+		brokenPodOnNode("C", "ns2", "bar", "node1"),
+		brokenPodOnNode("D", "ns1", "foo", "node2"),
+	} {
+		podIndex.Add(p)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	c, err := newTopologyCorrelator(TopologyConfig{Period: time.Second, ZoneLabel: topologyZoneLabelDefault, NodeThreshold: 2, NodeAction: NodeActionTaint}, kubeClient, corev1listers.NewPodLister(podIndex), corev1listers.NewNodeLister(nodeIndex), recorder, devlogger)
+	if err != nil {
+		t.Fatalf("newTopologyCorrelator() error = %v", err)
+	}
+
+	isTainted := func(name string) bool {
+		n, err := kubeClient.Core().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("can't get node %s: %v", name, err)
+		}
+		// keep the lister in sync with the client
+		nodeIndex.Update(n)
+		for _, taint := range n.Spec.Taints {
+			if taint.Key == labeling.TaintNodeDegradedKey {
+				return true
+			}
+		}
+		return false
+	}
+
+	// node1 has broken pods of 2 KubervisorServices, node2 of only 1
+	c.correlate()
+	if event := <-recorder.Events; event != "Warning BadNode pods of 2 KubervisorServices are broken or out of bounds on the node: [ns1/foo ns2/bar]" {
+		t.Errorf("unexpected event: %q", event)
+	}
+	if !isTainted("node1") || isTainted("node2") {
+		t.Errorf("only node1 should be tainted")
+	}
+
+	// the event is sent once
+	c.correlate()
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("unexpected event: %q", event)
+	default:
+	}
+
+	// the pod of bar is back with traffic: node1 recovers
+	podIndex.Delete(brokenPodOnNode("C", "ns2", "bar", "node1"))
+	c.correlate()
+	if event := <-recorder.Events; event != "Normal BadNodeRecovered the broken or out of bounds pods on the node are below the threshold" {
+		t.Errorf("unexpected event: %q", event)
+	}
+	if isTainted("node1") {
+		t.Errorf("node1 should not be tainted anymore")
+	}
+}
+
+func Test_topologyCorrelator_eventSink(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	node := &kapiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{topologyZoneLabelDefault: "zone-a"}}}
+	kubeClient := kfakeclient.NewSimpleClientset(node)
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	nodeIndex.Add(node)
+	podIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	podIndex.Add(brokenPodOnNode("A", "ns1", "foo", "node1"))
+	podIndex.Add(brokenPodOnNode("B", "ns2", "bar", "node1"))
+
+	// the events go through the events API like in the controller, which requires the events rule of the chart ClusterRole
+	eventBroadcaster := record.NewBroadcaster()
+	sink := eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: kubeClient.Core().Events("")})
+	defer sink.Stop()
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, kapiv1.EventSource{Component: "kubervisor-controller"})
+	config := TopologyConfig{Period: time.Second, ZoneLabel: topologyZoneLabelDefault, NodeThreshold: 2, ZoneThreshold: 2, NodeAction: NodeActionNone}
+	c, err := newTopologyCorrelator(config, kubeClient, corev1listers.NewPodLister(podIndex), corev1listers.NewNodeLister(nodeIndex), recorder, devlogger)
+	if err != nil {
+		t.Fatalf("newTopologyCorrelator() error = %v", err)
+	}
+	c.correlate()
+
+	want := map[string]bool{"default/BadNode": true, "ns1/BadZone": true, "ns2/BadZone": true}
+	var got map[string]bool
+	for i := 0; i < 100; i++ {
+		got = map[string]bool{}
+		for _, action := range kubeClient.Actions() {
+			if create, ok := action.(clienttesting.CreateAction); ok && action.GetResource().Resource == "events" {
+				e := create.GetObject().(*kapiv1.Event)
+				got[e.Namespace+"/"+e.Reason] = true
+			}
+		}
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("events created with the events API = %v, want %v", got, want)
+}
+
+func Test_topologyCorrelator_correlateAnomalies(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	nodes := []*kapiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{topologyZoneLabelDefault: "zone-a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{topologyZoneLabelDefault: "zone-a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node3", Labels: map[string]string{topologyZoneLabelDefault: "zone-b"}}},
+	}
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, n := range nodes {
+		nodeIndex.Add(n)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	config := TopologyConfig{Period: time.Second, ZoneLabel: topologyZoneLabelDefault, NodeThreshold: 2, ZoneThreshold: 2, NodeAction: NodeActionNone}
+	c, err := newTopologyCorrelator(config, kfakeclient.NewSimpleClientset(), test.NewTestPodLister(nil), corev1listers.NewNodeLister(nodeIndex), recorder, devlogger)
+	if err != nil {
+		t.Fatalf("newTopologyCorrelator() error = %v", err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	// no pod is broken yet: the out of bounds pods are enough to detect the bad node and zone
+	c.RecordAnomalies("ns1", "foo", "errors", []*kapiv1.Pod{brokenPodOnNode("A", "ns1", "foo", "node1")})
+	c.RecordAnomalies("ns2", "bar", "latency", []*kapiv1.Pod{brokenPodOnNode("B", "ns2", "bar", "node1"), brokenPodOnNode("C", "ns2", "bar", "node3")})
+	c.correlate()
+	events := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		events[<-recorder.Events] = true
+	}
+	for _, want := range []string{
+		"Warning BadNode pods of 2 KubervisorServices are broken or out of bounds on the node: [ns1/foo ns2/bar]",
+		"Warning BadZone pods of 2 KubervisorServices are broken or out of bounds in the zone zone-a: [ns1/foo ns2/bar]",
+	} {
+		if !events[want] {
+			t.Errorf("missing event %q in %v", want, events)
+		}
+	}
+	if _, ok := c.badZones["zone-b"]; ok {
+		t.Errorf("zone-b should not be bad")
+	}
+
+	// the anomalies of foo are not refreshed: they expire
+	now = now.Add(anomaliesTimeout + time.Second)
+	c.RecordAnomalies("ns2", "bar", "latency", []*kapiv1.Pod{brokenPodOnNode("B", "ns2", "bar", "node1")})
+	c.correlate()
+	events = map[string]bool{}
+	for i := 0; i < 3; i++ {
+		events[<-recorder.Events] = true
+	}
+	for _, want := range []string{
+		"Normal BadNodeRecovered the broken or out of bounds pods on the node are below the threshold",
+		"Normal BadZoneRecovered the broken or out of bounds pods in the zone zone-a are below the threshold",
+	} {
+		if !events[want] {
+			t.Errorf("missing event %q in %v", want, events)
+		}
+	}
+}
+
+func Test_topologyCorrelator_applyNodeAction(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	cordonedByAdmin := &kapiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "admin"}, Spec: kapiv1.NodeSpec{Unschedulable: true}}
+	node := &kapiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	kubeClient := kfakeclient.NewSimpleClientset(cordonedByAdmin, node)
+	c := &topologyCorrelator{
+		config:     TopologyConfig{NodeThreshold: 1, NodeAction: NodeActionCordon},
+		kubeClient: kubeClient,
+		logger:     devlogger,
+	}
+
+	get := func(name string) *kapiv1.Node {
+		n, err := kubeClient.Core().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("can't get node %s: %v", name, err)
+		}
+		return n
+	}
+
+	for _, n := range []*kapiv1.Node{cordonedByAdmin, node} {
+		if err := c.applyNodeAction(n, true); err != nil {
+			t.Fatalf("applyNodeAction(%s, true) error = %v", n.Name, err)
+		}
+	}
+	if n := get("node"); !n.Spec.Unschedulable || n.Annotations[labeling.AnnotationNodeCordonedKey] == "" {
+		t.Errorf("node should be cordoned by kubervisor: %v", n)
+	}
+
+	// only the node cordoned by kubervisor is uncordoned
+	for _, name := range []string{"admin", "node"} {
+		if err := c.applyNodeAction(get(name), false); err != nil {
+			t.Fatalf("applyNodeAction(%s, false) error = %v", name, err)
+		}
+	}
+	if !get("admin").Spec.Unschedulable {
+		t.Errorf("node cordoned by the admin should stay cordoned")
+	}
+	if n := get("node"); n.Spec.Unschedulable || n.Annotations[labeling.AnnotationNodeCordonedKey] != "" {
+		t.Errorf("node should be uncordoned: %v", n)
+	}
+}
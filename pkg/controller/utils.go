@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"strings"
+
 	kapiv1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -90,7 +92,8 @@ func UpdateStatusCondition(status *api.KubervisorServiceStatus, conditionType ap
 		if condition.Type == conditionType {
 			found = true
 			newStatus.Conditions[idCondition] = updateConditionFunc(&condition)
-		} else {
+		} else if condition.Type != api.KubervisorServiceFleetIncident {
+			// the FleetIncident condition is independent of the others, see UpdateStatusConditionFleetIncident
 			// TODO improve condition status transition. Can we have 2 condition with true ?
 			newStatus.Conditions[idCondition] = updateStatusCondition(&condition, kapiv1.ConditionFalse, updatetime)
 		}
@@ -102,6 +105,33 @@ func UpdateStatusCondition(status *api.KubervisorServiceStatus, conditionType ap
 	return newStatus, nil
 }
 
+// UpdateStatusConditionFleetIncident used to set or clear the FleetIncident condition according to the ongoing fleet incidents, returns false if the status is unchanged
+func UpdateStatusConditionFleetIncident(status *api.KubervisorServiceStatus, incidents []string, updatetime metav1.Time) (*api.KubervisorServiceStatus, bool) {
+	conditionStatus := kapiv1.ConditionFalse
+	if len(incidents) > 0 {
+		conditionStatus = kapiv1.ConditionTrue
+	}
+	msg := strings.Join(incidents, "; ")
+	newStatus := status.DeepCopy()
+	for idCondition, condition := range newStatus.Conditions {
+		if condition.Type != api.KubervisorServiceFleetIncident {
+			continue
+		}
+		if condition.Status == conditionStatus && condition.Message == msg {
+			return status, false
+		}
+		newCondition := updateStatusCondition(&condition, conditionStatus, updatetime)
+		newCondition.Message = msg
+		newStatus.Conditions[idCondition] = newCondition
+		return newStatus, true
+	}
+	if len(incidents) == 0 {
+		return status, false
+	}
+	newStatus.Conditions = append(newStatus.Conditions, newStatusCondition(api.KubervisorServiceFleetIncident, conditionStatus, msg, "Fleet-wide anomaly, no pod is broken", updatetime))
+	return newStatus, true
+}
+
 func equalPodCountStatus(a, b api.PodCountStatus) bool {
 	t0 := metav1.Time{}
 	a.LastProbeTime, b.LastProbeTime = t0, t0
@@ -198,6 +198,67 @@ func TestUpdateStatusConditionRunning(t *testing.T) {
 	}
 }
 
+func TestUpdateStatusConditionFleetIncident(t *testing.T) {
+
+	now := metav1.Now()
+	pastTime := metav1.NewTime(now.Truncate(2 * time.Minute))
+	msg := "strategy1: fleet error ratio 30.0% exceeds 20%"
+
+	runningCondition := newStatusConditionRunning("", pastTime)
+	incidentCondition := newStatusCondition(api.KubervisorServiceFleetIncident, kapiv1.ConditionTrue, msg, "Fleet-wide anomaly, no pod is broken", pastTime)
+	resolvedCondition := updateStatusCondition(&incidentCondition, kapiv1.ConditionFalse, now)
+	resolvedCondition.Message = ""
+
+	tests := []struct {
+		name        string
+		status      *api.KubervisorServiceStatus
+		incidents   []string
+		want        *api.KubervisorServiceStatus
+		wantUpdated bool
+	}{
+		{
+			name:        "no incident",
+			status:      &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition}},
+			want:        &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition}},
+			wantUpdated: false,
+		},
+		{
+			name:      "new incident",
+			status:    &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition}},
+			incidents: []string{msg},
+			want: &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{
+				runningCondition,
+				newStatusCondition(api.KubervisorServiceFleetIncident, kapiv1.ConditionTrue, msg, "Fleet-wide anomaly, no pod is broken", now),
+			}},
+			wantUpdated: true,
+		},
+		{
+			name:        "ongoing incident",
+			status:      &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition, incidentCondition}},
+			incidents:   []string{msg},
+			want:        &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition, incidentCondition}},
+			wantUpdated: false,
+		},
+		{
+			name:        "resolved incident",
+			status:      &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition, incidentCondition}},
+			want:        &api.KubervisorServiceStatus{Conditions: []api.KubervisorServiceCondition{runningCondition, resolvedCondition}},
+			wantUpdated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, updated := UpdateStatusConditionFleetIncident(tt.status, tt.incidents, now)
+			if updated != tt.wantUpdated {
+				t.Errorf("UpdateStatusConditionFleetIncident() updated = %v, want %v", updated, tt.wantUpdated)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpdateStatusConditionFleetIncident()\ngot = %v\nwant= %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_equalPodCountStatus(t *testing.T) {
 	t0 := metav1.Time{}
 	t1 := metav1.Time{Time: time.Now()}
@@ -0,0 +1,99 @@
+// Package detectortest validates a custom detector service against the anomaly detectors of the controller
+package detectortest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"go.uber.org/zap"
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/anomalydetector"
+	api "github.com/amadeusitgroup/kubervisor/pkg/api/kubervisor/v1alpha1"
+)
+
+// Harness serves a detector handler in process and evaluates it with the anomaly detectors of the controller
+type Harness struct {
+	// KubervisorName and Namespace are sent in the v2 requests
+	KubervisorName string
+	Namespace      string
+	// Pods managed by the KubervisorService. Only the pods running, ready and with the traffic label are sent to the detector
+	Pods kv1.PodNamespaceLister
+	// Selector of the managed pods. Default: all the pods
+	Selector labels.Selector
+	Logger   *zap.Logger
+
+	server *httptest.Server
+}
+
+// New starts serving the handler. Close must be called at the end of the test
+func New(handler http.Handler, namespace string, pods kv1.PodNamespaceLister) *Harness {
+	logger, _ := zap.NewDevelopment()
+	return &Harness{
+		KubervisorName: "detectortest",
+		Namespace:      namespace,
+		Pods:           pods,
+		Selector:       labels.Everything(),
+		Logger:         logger,
+		server:         httptest.NewServer(handler),
+	}
+}
+
+// URL of the served handler
+func (h *Harness) URL() string {
+	return h.server.URL
+}
+
+// Close stops serving the handler
+func (h *Harness) Close() {
+	h.server.Close()
+}
+
+// EvaluateV2 returns the names of the out of bounds pods, as decoded by the customDetector strategy (v2 protocol)
+func (h *Harness) EvaluateV2() ([]string, error) {
+	return h.evaluate(api.BreakerStrategy{
+		Name:           "detectortest",
+		CustomDetector: api.DefaultCustomDetector(&api.CustomDetector{URL: h.server.URL}),
+	})
+}
+
+// EvaluateV1 returns the names of the out of bounds pods, as decoded by the customService strategy (v1 protocol)
+func (h *Harness) EvaluateV1() ([]string, error) {
+	return h.evaluate(api.BreakerStrategy{
+		Name:          "detectortest",
+		CustomService: strings.TrimPrefix(h.server.URL, "http://"),
+	})
+}
+
+func (h *Harness) evaluate(strategy api.BreakerStrategy) ([]string, error) {
+	detector, err := anomalydetector.New(anomalydetector.FactoryConfig{
+		Config: anomalydetector.Config{
+			KubervisorName:        h.KubervisorName,
+			Namespace:             h.Namespace,
+			BreakerStrategyConfig: strategy,
+			Selector:              h.Selector,
+			PodLister:             h.Pods,
+			Logger:                h.Logger,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create the anomaly detector: %v", err)
+	}
+	pods, err := detector.GetPodsOutOfBounds()
+	if err != nil {
+		return nil, err
+	}
+	return podNames(pods), nil
+}
+
+func podNames(pods []*kapiv1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	return names
+}
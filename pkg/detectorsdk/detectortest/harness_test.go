@@ -0,0 +1,74 @@
+package detectortest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+
+	"github.com/amadeusitgroup/kubervisor/pkg/detectorsdk"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+func TestHarness(t *testing.T) {
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo", "faulty": "true"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("C", "test-ns", map[string]string{"app": "foo", "faulty": "true"}, nil, true, true, labeling.LabelTrafficNo),
+	}
+	lister := test.NewTestPodNamespaceLister(pods, "test-ns")
+
+	tests := []struct {
+		name    string
+		detect  detectorsdk.DetectFunc
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "faulty label",
+			detect: func(ctx context.Context, pods []*kapiv1.Pod) ([]detectorsdk.Verdict, error) {
+				verdicts := []detectorsdk.Verdict{}
+				for _, p := range pods {
+					v := detectorsdk.Verdict{Name: p.Name, Verdict: detectorsdk.VerdictOK}
+					if p.Labels["faulty"] == "true" {
+						v.Verdict = detectorsdk.VerdictOutOfBounds
+					}
+					verdicts = append(verdicts, v)
+				}
+				return verdicts, nil
+			},
+			want: []string{"B"},
+		},
+		{
+			name: "no verdict",
+			detect: func(ctx context.Context, pods []*kapiv1.Pod) ([]detectorsdk.Verdict, error) {
+				return nil, nil
+			},
+			want: []string{},
+		},
+		{
+			name: "error",
+			detect: func(ctx context.Context, pods []*kapiv1.Pod) ([]detectorsdk.Verdict, error) {
+				return nil, fmt.Errorf("boom")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(detectorsdk.NewHandler(detectorsdk.Config{Detect: tt.detect, Pods: lister}), "test-ns", lister)
+			defer h.Close()
+
+			got, err := h.EvaluateV2()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateV2() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EvaluateV2() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package detectorsdk helps writing custom anomaly detector services.
+//
+// The detector author provides a DetectFunc that returns a verdict for the pods, and serves the Handler:
+//
+//	lister, err := detectorsdk.NewPodLister(kubeClient, namespace, stop)
+//	handler := detectorsdk.NewHandler(detectorsdk.Config{
+//		Detect: func(ctx context.Context, pods []*v1.Pod) ([]detectorsdk.Verdict, error) {
+//			...
+//		},
+//		Pods: lister,
+//	})
+//	http.ListenAndServe(":8080", handler)
+//
+// The Handler speaks the v2 protocol (POST, customDetector strategy) and the v1 protocol (GET, customService strategy).
+// The package detectortest checks a detector against the anomaly detectors of the controller.
+package detectorsdk
@@ -0,0 +1,135 @@
+package detectorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kv1 "k8s.io/client-go/listers/core/v1"
+
+	v2 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/v2"
+)
+
+// Verdict of the detector for one pod: Name is the name of the pod
+type Verdict = v2.PodVerdict
+
+// Verdict values
+const (
+	VerdictOK          = v2.VerdictOK
+	VerdictOutOfBounds = v2.VerdictOutOfBounds
+	VerdictUnknown     = v2.VerdictUnknown
+)
+
+// DetectFunc returns the verdicts of the pods. The pods without verdict are considered ok
+type DetectFunc func(ctx context.Context, pods []*kapiv1.Pod) ([]Verdict, error)
+
+// Config of the Handler
+type Config struct {
+	// Detect is called at each request
+	Detect DetectFunc
+	// Pods resolves the pods of the v2 requests to the full pod objects, see NewPodLister.
+	// Without it the pods passed to Detect only have their name, namespace and IP, and the v1 protocol is disabled
+	Pods kv1.PodNamespaceLister
+	// Selector of the pods evaluated with the v1 protocol, in which the requests do not carry the pods. Default: all the pods
+	Selector labels.Selector
+}
+
+type handler struct {
+	Config
+}
+
+// NewHandler returns an http.Handler that speaks the custom detector protocols:
+// v2 with a POST carrying the pods, and v1 with a GET answering the list of the out of bounds pods
+func NewHandler(cfg Config) http.Handler {
+	if cfg.Selector == nil {
+		cfg.Selector = labels.Everything()
+	}
+	return &handler{Config: cfg}
+}
+
+// ServeHTTP implements http.Handler
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.serveV2(w, r)
+	case http.MethodGet:
+		h.serveV1(w, r)
+	default:
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) serveV2(w http.ResponseWriter, r *http.Request) {
+	req := &v2.Request{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, fmt.Sprintf("can't decode the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.APIVersion != v2.APIVersion {
+		http.Error(w, fmt.Sprintf("unsupported apiVersion %q", req.APIVersion), http.StatusBadRequest)
+		return
+	}
+
+	pods := make([]*kapiv1.Pod, 0, len(req.Pods))
+	for _, p := range req.Pods {
+		pods = append(pods, h.resolve(req.Namespace, p))
+	}
+	verdicts, err := h.Detect(r.Context(), pods)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if verdicts == nil {
+		verdicts = []Verdict{}
+	}
+	w.Header().Set("Content-Type", v2.ContentType)
+	json.NewEncoder(w).Encode(v2.Response{APIVersion: v2.APIVersion, Pods: verdicts})
+}
+
+// resolve returns the pod from the lister if possible
+func (h *handler) resolve(namespace string, p v2.Pod) *kapiv1.Pod {
+	if h.Pods != nil {
+		if pod, err := h.Pods.Get(p.Name); err == nil {
+			return pod
+		}
+	}
+	pod := &kapiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: namespace}}
+	pod.Status.PodIP = p.IP
+	return pod
+}
+
+func (h *handler) serveV1(w http.ResponseWriter, r *http.Request) {
+	if h.Pods == nil {
+		http.Error(w, "the v1 protocol requires a pod lister", http.StatusNotImplemented)
+		return
+	}
+	pods, err := h.Pods.List(h.Selector)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("can't list pods: %v", err), http.StatusInternalServerError)
+		return
+	}
+	verdicts, err := h.Detect(r.Context(), pods)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	outOfBounds := map[string]struct{}{}
+	for _, v := range verdicts {
+		if v.Verdict == VerdictOutOfBounds {
+			outOfBounds[v.Name] = struct{}{}
+		}
+	}
+	list := kapiv1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: kapiv1.SchemeGroupVersion.String()}}
+	for _, p := range pods {
+		if _, ok := outOfBounds[p.Name]; ok {
+			list.Items = append(list.Items, *p)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
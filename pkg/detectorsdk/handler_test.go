@@ -0,0 +1,139 @@
+package detectorsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v2 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/v2"
+	"github.com/amadeusitgroup/kubervisor/pkg/labeling"
+	test "github.com/amadeusitgroup/kubervisor/test"
+)
+
+// byLabel returns out of bounds for the pods with the label faulty=true, and reports the IP in the reason
+func byLabel(ctx context.Context, pods []*kapiv1.Pod) ([]Verdict, error) {
+	verdicts := []Verdict{}
+	for _, p := range pods {
+		v := Verdict{Name: p.Name, Verdict: VerdictOK, Reason: p.Status.PodIP}
+		if p.Labels["faulty"] == "true" {
+			v.Verdict = VerdictOutOfBounds
+		}
+		verdicts = append(verdicts, v)
+	}
+	return verdicts, nil
+}
+
+func TestHandler_V2(t *testing.T) {
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo", "faulty": "true"}, nil, true, true, labeling.LabelTrafficYes),
+	}
+	pods[0].Status.PodIP = "10.0.0.1"
+	pods[1].Status.PodIP = "10.0.0.2"
+
+	tests := []struct {
+		name       string
+		pods       []*kapiv1.Pod
+		request    v2.Request
+		wantStatus int
+		want       []Verdict
+	}{
+		{
+			name: "resolved with the lister",
+			pods: pods,
+			request: v2.Request{APIVersion: v2.APIVersion, Namespace: "test-ns", Pods: []v2.Pod{
+				{Name: "A", IP: "10.0.0.1"}, {Name: "B", IP: "10.0.0.2"},
+			}},
+			wantStatus: http.StatusOK,
+			want: []Verdict{
+				{Name: "A", Verdict: VerdictOK, Reason: "10.0.0.1"},
+				{Name: "B", Verdict: VerdictOutOfBounds, Reason: "10.0.0.2"},
+			},
+		},
+		{
+			name: "without lister",
+			request: v2.Request{APIVersion: v2.APIVersion, Namespace: "test-ns", Pods: []v2.Pod{
+				{Name: "B", IP: "10.0.0.2"},
+			}},
+			wantStatus: http.StatusOK,
+			want:       []Verdict{{Name: "B", Verdict: VerdictOK, Reason: "10.0.0.2"}},
+		},
+		{
+			name:       "unsupported apiVersion",
+			request:    v2.Request{APIVersion: "kubervisor.k8s.io/detector/v3"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Detect: byLabel}
+			if tt.pods != nil {
+				cfg.Pods = test.NewTestPodNamespaceLister(tt.pods, "test-ns")
+			}
+			body, _ := json.Marshal(tt.request)
+			w := httptest.NewRecorder()
+			NewHandler(cfg).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			resp := v2.Response{}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.APIVersion != v2.APIVersion {
+				t.Errorf("apiVersion = %q", resp.APIVersion)
+			}
+			if !reflect.DeepEqual(resp.Pods, tt.want) {
+				t.Errorf("verdicts = %v, want %v", resp.Pods, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_V1(t *testing.T) {
+	pods := []*kapiv1.Pod{
+		test.PodGen("A", "test-ns", map[string]string{"app": "foo"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("B", "test-ns", map[string]string{"app": "foo", "faulty": "true"}, nil, true, true, labeling.LabelTrafficYes),
+		test.PodGen("C", "test-ns", map[string]string{"app": "bar", "faulty": "true"}, nil, true, true, labeling.LabelTrafficYes),
+	}
+
+	w := httptest.NewRecorder()
+	NewHandler(Config{Detect: byLabel}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("status without lister = %d, want %d", w.Code, http.StatusNotImplemented)
+	}
+
+	w = httptest.NewRecorder()
+	handler := NewHandler(Config{
+		Detect:   byLabel,
+		Pods:     test.NewTestPodNamespaceLister(pods, "test-ns"),
+		Selector: labels.SelectorFromSet(map[string]string{"app": "foo"}),
+	})
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	list := kapiv1.PodList{}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, p := range list.Items {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"B"}) {
+		t.Errorf("out of bounds pods = %v, want [B]", names)
+	}
+}
@@ -0,0 +1,25 @@
+package detectorsdk
+
+import (
+	"fmt"
+	"time"
+
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	kv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podResyncPeriod resync period of the pod informer
+const podResyncPeriod = 30 * time.Second
+
+// NewPodLister starts an informer on the pods of the namespace and returns its lister once the cache is synced.
+// The informer runs until stop is closed
+func NewPodLister(kubeClient clientset.Interface, namespace string, stop <-chan struct{}) (kv1.PodNamespaceLister, error) {
+	informer := coreinformers.NewPodInformer(kubeClient, namespace, podResyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	go informer.Run(stop)
+	if !cache.WaitForCacheSync(stop, informer.HasSynced) {
+		return nil, fmt.Errorf("can't sync the pods of namespace %s", namespace)
+	}
+	return kv1.NewPodLister(informer.GetIndexer()).Pods(namespace), nil
+}
@@ -0,0 +1,204 @@
+// Package expression compiles and evaluates boolean expressions over numeric variables.
+//
+// Syntax, by increasing precedence:
+//
+//	a || b
+//	a && b
+//	a < b, a <= b, a > b, a >= b, a == b, a != b
+//	a + b, a - b
+//	a * b, a / b
+//	!a, -a
+//	numbers, true, false, variables, (a)
+//
+// The expressions are type-checked at compilation: the operands of the logical operators must be booleans, the other operators work on numbers.
+package expression
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Type of a value
+type Type int
+
+// The types of the values
+const (
+	Number Type = iota
+	Bool
+)
+
+func (t Type) String() string {
+	if t == Bool {
+		return "bool"
+	}
+	return "number"
+}
+
+// Expression a compiled boolean expression
+type Expression struct {
+	source    string
+	root      node
+	variables []string
+}
+
+// Compile parses and type-checks the boolean expression. isVariable reports whether a name is a known variable, all the variables are numbers
+func Compile(source string, isVariable func(name string) bool) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, isVariable: isVariable, variables: map[string]struct{}{}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	if root.typ() != Bool {
+		return nil, fmt.Errorf("the expression is a %s instead of a bool", root.typ())
+	}
+
+	e := &Expression{source: source, root: root}
+	for name := range p.variables {
+		e.variables = append(e.variables, name)
+	}
+	sort.Strings(e.variables)
+	return e, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Variables returns the sorted names of the variables used by the expression
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Eval evaluates the expression. It fails if a variable used by the expression is missing
+func (e *Expression) Eval(vars map[string]float64) (bool, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return v.b, nil
+}
+
+// value result of the evaluation of a node, num or b depending on the type of the node
+type value struct {
+	num float64
+	b   bool
+}
+
+type node interface {
+	typ() Type
+	eval(vars map[string]float64) (value, error)
+}
+
+type numberNode float64
+
+func (n numberNode) typ() Type { return Number }
+func (n numberNode) eval(map[string]float64) (value, error) {
+	return value{num: float64(n)}, nil
+}
+
+type boolNode bool
+
+func (n boolNode) typ() Type { return Bool }
+func (n boolNode) eval(map[string]float64) (value, error) {
+	return value{b: bool(n)}, nil
+}
+
+type variableNode string
+
+func (n variableNode) typ() Type { return Number }
+func (n variableNode) eval(vars map[string]float64) (value, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return value{}, fmt.Errorf("missing variable %s", string(n))
+	}
+	return value{num: v}, nil
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n *unaryNode) typ() Type { return n.x.typ() }
+func (n *unaryNode) eval(vars map[string]float64) (value, error) {
+	x, err := n.x.eval(vars)
+	if err != nil {
+		return value{}, err
+	}
+	if n.op == "!" {
+		return value{b: !x.b}, nil
+	}
+	return value{num: -x.num}, nil
+}
+
+type binaryNode struct {
+	op   string
+	t    Type
+	l, r node
+}
+
+func (n *binaryNode) typ() Type { return n.t }
+func (n *binaryNode) eval(vars map[string]float64) (value, error) {
+	l, err := n.l.eval(vars)
+	if err != nil {
+		if n.op != "||" && n.op != "&&" {
+			return value{}, err
+		}
+		// the right operand alone decides the result when it is true for || and false for &&
+		r, rErr := n.r.eval(vars)
+		if rErr == nil && r.b == (n.op == "||") {
+			return value{b: r.b}, nil
+		}
+		return value{}, err
+	}
+	// short-circuit: the right operand may use a variable that is missing
+	switch {
+	case n.op == "||" && l.b:
+		return value{b: true}, nil
+	case n.op == "&&" && !l.b:
+		return value{b: false}, nil
+	}
+	r, err := n.r.eval(vars)
+	if err != nil {
+		return value{}, err
+	}
+	switch n.op {
+	case "||", "&&":
+		return value{b: r.b}, nil
+	case "+":
+		return value{num: l.num + r.num}, nil
+	case "-":
+		return value{num: l.num - r.num}, nil
+	case "*":
+		return value{num: l.num * r.num}, nil
+	case "/":
+		return value{num: l.num / r.num}, nil
+	case "<":
+		return value{b: l.num < r.num}, nil
+	case "<=":
+		return value{b: l.num <= r.num}, nil
+	case ">":
+		return value{b: l.num > r.num}, nil
+	case ">=":
+		return value{b: l.num >= r.num}, nil
+	case "==":
+		if n.l.typ() == Bool {
+			return value{b: l.b == r.b}, nil
+		}
+		return value{b: l.num == r.num}, nil
+	case "!=":
+		if n.l.typ() == Bool {
+			return value{b: l.b != r.b}, nil
+		}
+		return value{b: l.num != r.num}, nil
+	}
+	return value{}, fmt.Errorf("unknown operator %s", n.op)
+}
@@ -0,0 +1,87 @@
+package expression
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	known := map[string]bool{"errors": true, "requests": true, "p99": true, "fleet_p99": true}
+	isVariable := func(name string) bool { return known[name] }
+
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "full", source: "errors / requests > 0.05 && requests > 100 || p99 > 2 * fleet_p99", want: []string{"errors", "fleet_p99", "p99", "requests"}},
+		{name: "literals", source: "!(1e3 >= -2.5) == false", want: nil},
+		{name: "number", source: "errors + 1", wantErr: true},
+		{name: "unknown variable", source: "latency > 1", wantErr: true},
+		{name: "logical on numbers", source: "errors && true", wantErr: true},
+		{name: "arithmetic on bools", source: "(errors > 1) + 1 > 0", wantErr: true},
+		{name: "bool and number comparison", source: "(errors > 1) == 1", wantErr: true},
+		{name: "ordered bools", source: "(errors > 1) < true", wantErr: true},
+		{name: "chained comparison", source: "1 < errors < 3", wantErr: true},
+		{name: "negated number", source: "!errors", wantErr: true},
+		{name: "missing parenthesis", source: "(errors > 1", wantErr: true},
+		{name: "invalid character", source: "errors > 1 ; true", wantErr: true},
+		{name: "invalid number", source: "errors > 1.2.3", wantErr: true},
+		{name: "empty", source: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.source, isVariable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(e.Variables(), tt.want) {
+				t.Errorf("Variables() = %v, want %v", e.Variables(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExpression_Eval(t *testing.T) {
+	isVariable := func(name string) bool { return true }
+	tests := []struct {
+		name    string
+		source  string
+		vars    map[string]float64
+		want    bool
+		wantErr bool
+	}{
+		{name: "error rate", source: "errors / requests > 0.05 && requests > 100", vars: map[string]float64{"errors": 10, "requests": 150}, want: true},
+		{name: "not enough requests", source: "errors / requests > 0.05 && requests > 100", vars: map[string]float64{"errors": 10, "requests": 50}, want: false},
+		{name: "precedence", source: "1 + 2 * 3 == 7 && 10 - 4 - 3 == 3 && 8 / 4 / 2 == 1", want: true},
+		{name: "unary", source: "-a < 0 && !(a < 0)", vars: map[string]float64{"a": 2}, want: true},
+		{name: "bool equality", source: "(a > 1) != (a > 3)", vars: map[string]float64{"a": 2}, want: true},
+		{name: "short-circuit or", source: "a > 1 || missing > 1", vars: map[string]float64{"a": 2}, want: true},
+		{name: "short-circuit and", source: "a > 3 && missing > 1", vars: map[string]float64{"a": 2}, want: false},
+		{name: "missing variable", source: "a > 1 && missing > 1", vars: map[string]float64{"a": 2}, wantErr: true},
+		{name: "missing left, or decided by right", source: "missing > 1 || a > 1", vars: map[string]float64{"a": 2}, want: true},
+		{name: "missing left, or not decided by right", source: "missing > 1 || a > 3", vars: map[string]float64{"a": 2}, wantErr: true},
+		{name: "missing left, and decided by right", source: "missing > 1 && a > 3", vars: map[string]float64{"a": 2}, want: false},
+		{name: "missing left, and not decided by right", source: "missing > 1 && a > 1", vars: map[string]float64{"a": 2}, wantErr: true},
+		{name: "division by zero", source: "errors / requests > 0.05", vars: map[string]float64{"errors": 0, "requests": 0}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.source, isVariable)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got, err := e.Eval(tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
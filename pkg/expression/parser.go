@@ -0,0 +1,241 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators sorted so that the two characters operators are matched first
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "!", "(", ")"}
+
+func tokenize(source string) ([]token, error) {
+	runes := []rune(source)
+	tokens := []token{}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// exponent
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				i++
+				if i < len(runes) && (runes[i] == '+' || runes[i] == '-') {
+					i++
+				}
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if i+len(op) <= len(runes) && string(runes[i:i+len(op)]) == op {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+// parser recursive descent parser, one function per precedence level
+type parser struct {
+	tokens     []token
+	next       int
+	isVariable func(name string) bool
+	variables  map[string]struct{}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token if it is one of the operators
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.next++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLogical(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseLogical(p.parseComparison, "&&")
+}
+
+func (p *parser) parseLogical(operand func() (node, error), op string) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos := p.peek().pos
+		if _, ok := p.accept(op); !ok {
+			return l, nil
+		}
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if l.typ() != Bool || r.typ() != Bool {
+			return nil, fmt.Errorf("the operands of %s at position %d must be bool", op, pos)
+		}
+		l = &binaryNode{op: op, t: Bool, l: l, r: r}
+	}
+}
+
+// parseComparison comparisons are not associative: a < b < c is rejected
+func (p *parser) parseComparison() (node, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	pos := p.peek().pos
+	op, ok := p.accept("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		return l, nil
+	}
+	r, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case l.typ() != r.typ():
+		return nil, fmt.Errorf("%s at position %d compares a %s with a %s", op, pos, l.typ(), r.typ())
+	case l.typ() == Bool && op != "==" && op != "!=":
+		return nil, fmt.Errorf("the operands of %s at position %d must be numbers", op, pos)
+	}
+	return &binaryNode{op: op, t: Bool, l: l, r: r}, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseArithmetic(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseArithmetic(p.parseUnary, "*", "/")
+}
+
+func (p *parser) parseArithmetic(operand func() (node, error), ops ...string) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos := p.peek().pos
+		op, ok := p.accept(ops...)
+		if !ok {
+			return l, nil
+		}
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if l.typ() != Number || r.typ() != Number {
+			return nil, fmt.Errorf("the operands of %s at position %d must be numbers", op, pos)
+		}
+		l = &binaryNode{op: op, t: Number, l: l, r: r}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	pos := p.peek().pos
+	op, ok := p.accept("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if op == "!" && x.typ() != Bool {
+		return nil, fmt.Errorf("the operand of ! at position %d must be bool", pos)
+	}
+	if op == "-" && x.typ() != Number {
+		return nil, fmt.Errorf("the operand of - at position %d must be a number", pos)
+	}
+	return &unaryNode{op: op, x: x}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenNumber:
+		p.next++
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return numberNode(f), nil
+	case tokenIdent:
+		p.next++
+		switch t.text {
+		case "true":
+			return boolNode(true), nil
+		case "false":
+			return boolNode(false), nil
+		}
+		if !p.isVariable(t.text) {
+			return nil, fmt.Errorf("unknown variable %s at position %d", t.text, t.pos)
+		}
+		p.variables[t.text] = struct{}{}
+		return variableNode(t.text), nil
+	case tokenOperator:
+		if t.text == "(" {
+			p.next++
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("missing ) at position %d", p.peek().pos)
+			}
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
@@ -0,0 +1,7 @@
+package labeling
+
+//Kubervisor keys for the taint and the annotation set on a node detected as bad
+const (
+	TaintNodeDegradedKey      = "kubervisor/degraded"
+	AnnotationNodeCordonedKey = "kubervisor/cordoned"
+)
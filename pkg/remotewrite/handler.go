@@ -0,0 +1,52 @@
+package remotewrite
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxRequestSize maximum size of a compressed remote-write request
+	maxRequestSize = 32 << 20
+	// maxDecodedSize protects against a corrupted or malicious snappy header
+	maxDecodedSize = 64 << 20
+)
+
+// Handler returns the http handler of the remote-write endpoint that feeds the store
+// The body must be a snappy compressed WriteRequest, like prometheus sends it.
+func Handler(store *Store, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		compressed, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if n, err := snappy.DecodedLen(compressed); err != nil || n > maxDecodedSize {
+			http.Error(w, "invalid snappy block", http.StatusBadRequest)
+			return
+		}
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req := &WriteRequest{}
+		if err := proto.Unmarshal(data, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if dropped := store.Append(req); dropped > 0 {
+			logger.Sugar().Debugf("remote-write: %d series dropped (no pod label or store full)", dropped)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,51 @@
+package remotewrite
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"go.uber.org/zap"
+)
+
+func TestHandler(t *testing.T) {
+	devlogger, _ := zap.NewDevelopment()
+	t0 := time.Now().Add(-time.Minute)
+	store := NewStore(Config{PodLabels: []string{"kubernetes_pod_name"}, MaxSeries: 10, Retention: 10 * time.Minute})
+	handler := Handler(store, devlogger)
+
+	data, err := proto.Marshal(&WriteRequest{Timeseries: []*TimeSeries{counterSeries("rpc_count", "A", "200", t0, 1, 5)}})
+	if err != nil {
+		t.Fatalf("can't marshal request: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		want   int
+	}{
+		{name: "get", method: http.MethodGet, want: http.StatusMethodNotAllowed},
+		{name: "not snappy", method: http.MethodPost, body: []byte{0xff, 0xff, 0xff}, want: http.StatusBadRequest},
+		{name: "not protobuf", method: http.MethodPost, body: snappy.Encode(nil, []byte{0xff, 0xff, 0xff}), want: http.StatusBadRequest},
+		{name: "ok", method: http.MethodPost, body: snappy.Encode(nil, data), want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, "/api/v1/write", bytes.NewReader(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("Handler() status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	got := store.Increases([]string{"A"}, []string{"rpc_count"}, t0)
+	if len(got["A"]) != 1 || got["A"][0].Value != 4 {
+		t.Errorf("Store.Increases() after write = %v", got)
+	}
+}
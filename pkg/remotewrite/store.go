@@ -0,0 +1,180 @@
+package remotewrite
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// maxSamplesPerSeries protects the store against agents pushing at a very high frequency
+const maxSamplesPerSeries = 1000
+
+// Config of the Store
+type Config struct {
+	PodLabels []string      // Labels that carry the pod name, the first one present on a series is used. Series without any of them are dropped
+	MaxSeries int           // Maximum number of series kept in memory, the new series are dropped above
+	Retention time.Duration // Samples older than the retention are dropped
+}
+
+type series struct {
+	metric  model.Metric
+	samples []model.SamplePair // ordered by timestamp
+}
+
+// Store bounded in-memory time-series store fed by the remote-write endpoint. The series are indexed by pod name.
+type Store struct {
+	sync.RWMutex
+	config    Config
+	byPod     map[string]map[model.Fingerprint]*series
+	nbSeries  int
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// NewStore returns a new Store instance
+func NewStore(config Config) *Store {
+	return &Store{
+		config: config,
+		byPod:  map[string]map[model.Fingerprint]*series{},
+		now:    time.Now,
+	}
+}
+
+// Append adds the samples of the request to the store
+// It returns the number of series that were dropped because they have no pod label or because the store is full
+func (s *Store) Append(req *WriteRequest) (dropped int) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	oldest := model.TimeFromUnixNano(now.Add(-s.config.Retention).UnixNano())
+	if now.Sub(s.lastPrune) > s.config.Retention/2 {
+		s.prune(oldest)
+		s.lastPrune = now
+	}
+
+	for _, ts := range req.Timeseries {
+		metric := make(model.Metric, len(ts.Labels))
+		for _, l := range ts.Labels {
+			metric[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+		}
+		podName := s.podName(metric)
+		if podName == "" {
+			dropped++
+			continue
+		}
+
+		fp := metric.Fingerprint()
+		sr, ok := s.byPod[podName][fp]
+		if !ok {
+			if s.nbSeries >= s.config.MaxSeries {
+				dropped++
+				continue
+			}
+			podSeries, ok := s.byPod[podName]
+			if !ok {
+				podSeries = map[model.Fingerprint]*series{}
+				s.byPod[podName] = podSeries
+			}
+			sr = &series{metric: metric}
+			podSeries[fp] = sr
+			s.nbSeries++
+		}
+
+		for _, sample := range ts.Samples {
+			t := model.Time(sample.Timestamp)
+			if t.Before(oldest) || (len(sr.samples) > 0 && !t.After(sr.samples[len(sr.samples)-1].Timestamp)) {
+				// too old or out of order
+				continue
+			}
+			sr.samples = append(sr.samples, model.SamplePair{Timestamp: t, Value: model.SampleValue(sample.Value)})
+		}
+		sr.trim(oldest)
+	}
+	return dropped
+}
+
+func (s *Store) podName(metric model.Metric) string {
+	for _, l := range s.config.PodLabels {
+		if v, ok := metric[model.LabelName(l)]; ok && v != "" {
+			return string(v)
+		}
+	}
+	return ""
+}
+
+// prune drops the samples that are out of the retention and the series that became empty
+func (s *Store) prune(oldest model.Time) {
+	for podName, podSeries := range s.byPod {
+		for fp, sr := range podSeries {
+			sr.trim(oldest)
+			if len(sr.samples) == 0 {
+				delete(podSeries, fp)
+				s.nbSeries--
+			}
+		}
+		if len(podSeries) == 0 {
+			delete(s.byPod, podName)
+		}
+	}
+}
+
+func (sr *series) trim(oldest model.Time) {
+	first := 0
+	for first < len(sr.samples) && sr.samples[first].Timestamp.Before(oldest) {
+		first++
+	}
+	if len(sr.samples)-first > maxSamplesPerSeries {
+		first = len(sr.samples) - maxSamplesPerSeries
+	}
+	if first > 0 {
+		sr.samples = append(sr.samples[:0], sr.samples[first:]...)
+	}
+}
+
+// Increases returns by pod name the increase since from of the series with one of the given names
+// Counter resets are handled like prometheus does. The series with less than 2 samples in the range are skipped and
+// the pods without any series are not part of the result.
+func (s *Store) Increases(podNames []string, names []string, from time.Time) map[string]model.Vector {
+	s.RLock()
+	defer s.RUnlock()
+
+	wanted := map[model.LabelValue]struct{}{}
+	for _, n := range names {
+		wanted[model.LabelValue(n)] = struct{}{}
+	}
+	start := model.TimeFromUnixNano(from.UnixNano())
+
+	result := map[string]model.Vector{}
+	for _, podName := range podNames {
+		podSeries, ok := s.byPod[podName]
+		if !ok {
+			continue
+		}
+		vector := model.Vector{}
+		for _, sr := range podSeries {
+			if _, ok := wanted[sr.metric[model.MetricNameLabel]]; !ok {
+				continue
+			}
+			inRange := sr.samples
+			for len(inRange) > 0 && inRange[0].Timestamp.Before(start) {
+				inRange = inRange[1:]
+			}
+			if len(inRange) < 2 {
+				continue
+			}
+			var increase model.SampleValue
+			for i := 1; i < len(inRange); i++ {
+				if inRange[i].Value >= inRange[i-1].Value {
+					increase += inRange[i].Value - inRange[i-1].Value
+				} else {
+					increase += inRange[i].Value
+				}
+			}
+			vector = append(vector, &model.Sample{Metric: sr.metric, Value: increase, Timestamp: inRange[len(inRange)-1].Timestamp})
+		}
+		result[podName] = vector
+	}
+	return result
+}
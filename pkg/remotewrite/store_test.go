@@ -0,0 +1,87 @@
+package remotewrite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func counterSeries(name, pod, code string, start time.Time, values ...float64) *TimeSeries {
+	ts := &TimeSeries{Labels: []*Label{{Name: "__name__", Value: name}, {Name: "code", Value: code}}}
+	if pod != "" {
+		ts.Labels = append(ts.Labels, &Label{Name: "kubernetes_pod_name", Value: pod})
+	}
+	for i, v := range values {
+		ts.Samples = append(ts.Samples, &Sample{Value: v, Timestamp: int64(model.TimeFromUnixNano(start.Add(time.Duration(i) * 10 * time.Second).UnixNano()))})
+	}
+	return ts
+}
+
+func increasesByCode(v model.Vector) map[string]float64 {
+	result := map[string]float64{}
+	for _, s := range v {
+		result[string(s.Metric["code"])] = float64(s.Value)
+	}
+	return result
+}
+
+func TestStore_Increases(t *testing.T) {
+	t0 := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	s := NewStore(Config{PodLabels: []string{"pod", "kubernetes_pod_name"}, MaxSeries: 4, Retention: 10 * time.Minute})
+	s.now = func() time.Time { return t0.Add(time.Minute) }
+
+	dropped := s.Append(&WriteRequest{Timeseries: []*TimeSeries{
+		counterSeries("rpc_count", "A", "200", t0, 10, 20, 30, 40),
+		counterSeries("rpc_count", "A", "500", t0, 1, 1, 1, 2),
+		counterSeries("rpc_count", "B", "200", t0, 50, 60, 5, 15), // counter reset
+		counterSeries("other_count", "B", "200", t0, 1, 2, 3, 4),
+		counterSeries("rpc_count", "", "200", t0, 1, 2, 3, 4),  // no pod label
+		counterSeries("rpc_count", "C", "200", t0, 1, 2, 3, 4), // store full
+	}})
+	if dropped != 2 {
+		t.Errorf("Store.Append() dropped = %d, want 2", dropped)
+	}
+
+	got := s.Increases([]string{"A", "B", "C"}, []string{"rpc_count"}, t0)
+	if len(got) != 2 {
+		t.Fatalf("Store.Increases() = %v, want pods A and B", got)
+	}
+	if a := increasesByCode(got["A"]); a["200"] != 30 || a["500"] != 1 {
+		t.Errorf("Store.Increases() A = %v", a)
+	}
+	if b := increasesByCode(got["B"]); len(b) != 1 || b["200"] != 25 {
+		t.Errorf("Store.Increases() B = %v", b)
+	}
+
+	// only the last 2 samples are in the range
+	got = s.Increases([]string{"A"}, []string{"rpc_count"}, t0.Add(20*time.Second))
+	if a := increasesByCode(got["A"]); a["200"] != 10 || a["500"] != 1 {
+		t.Errorf("Store.Increases() A since 20s = %v", a)
+	}
+	// only one sample in the range
+	got = s.Increases([]string{"A"}, []string{"rpc_count"}, t0.Add(30*time.Second))
+	if len(got["A"]) != 0 {
+		t.Errorf("Store.Increases() A since 30s = %v, want empty", got["A"])
+	}
+
+	// out of order samples are ignored
+	s.Append(&WriteRequest{Timeseries: []*TimeSeries{counterSeries("rpc_count", "A", "200", t0, 0)}})
+	got = s.Increases([]string{"A"}, []string{"rpc_count"}, t0)
+	if a := increasesByCode(got["A"]); a["200"] != 30 {
+		t.Errorf("Store.Increases() A after out of order = %v", a)
+	}
+
+	// retention: everything is pruned, room is made for new series
+	s.now = func() time.Time { return t0.Add(time.Hour) }
+	if dropped := s.Append(&WriteRequest{Timeseries: []*TimeSeries{counterSeries("rpc_count", "C", "200", t0.Add(59*time.Minute), 1, 2)}}); dropped != 0 {
+		t.Errorf("Store.Append() after retention dropped = %d, want 0", dropped)
+	}
+	if s.nbSeries != 1 {
+		t.Errorf("Store.nbSeries = %d, want 1", s.nbSeries)
+	}
+	got = s.Increases([]string{"A", "C"}, []string{"rpc_count"}, t0)
+	if len(got) != 1 || increasesByCode(got["C"])["200"] != 1 {
+		t.Errorf("Store.Increases() after retention = %v", got)
+	}
+}
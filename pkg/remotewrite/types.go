@@ -0,0 +1,67 @@
+package remotewrite
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The following messages are wire compatible with the prometheus remote-write protocol (prompb.WriteRequest)
+// Only the fields needed by kubervisor are declared, the others are skipped by the decoder.
+
+// WriteRequest payload of a remote-write call
+type WriteRequest struct {
+	Timeseries []*TimeSeries `protobuf:"bytes,1,rep,name=timeseries" json:"timeseries,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *WriteRequest) Reset() { *m = WriteRequest{} }
+
+// String implements proto.Message
+func (m *WriteRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*WriteRequest) ProtoMessage() {}
+
+// TimeSeries labels and samples of one series
+type TimeSeries struct {
+	Labels  []*Label  `protobuf:"bytes,1,rep,name=labels" json:"labels,omitempty"`
+	Samples []*Sample `protobuf:"bytes,2,rep,name=samples" json:"samples,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *TimeSeries) Reset() { *m = TimeSeries{} }
+
+// String implements proto.Message
+func (m *TimeSeries) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*TimeSeries) ProtoMessage() {}
+
+// Label name and value of a series label
+type Label struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Label) Reset() { *m = Label{} }
+
+// String implements proto.Message
+func (m *Label) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Label) ProtoMessage() {}
+
+// Sample value and timestamp (in milliseconds) of a series point
+type Sample struct {
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Sample) Reset() { *m = Sample{} }
+
+// String implements proto.Message
+func (m *Sample) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Sample) ProtoMessage() {}
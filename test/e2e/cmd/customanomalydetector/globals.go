@@ -16,11 +16,13 @@ var kubeClient clientset.Interface
 var sugar *zap.SugaredLogger
 var config *controller.Config
 var serverAddr string
+var grpcAddr string
 var namespace string
 var selector string
 
 func initMockFlag(fs *pflag.FlagSet) {
 	fs.StringVar(&serverAddr, "serverAddr", "0.0.0.0:8080", "listen address of the http server which serves kubernetes probes and prometheus endpoints")
+	fs.StringVar(&grpcAddr, "grpcAddr", "", "listen address of the gRPC plugin server, disabled if empty")
 	fs.StringVar(&namespace, "namespace", "default", "namespace in which we need to list the pod")
 	fs.StringVar(&selector, "selector", "", "selector used to list the pod")
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	kapiv1 "k8s.io/api/core/v1"
+
+	pluginv1 "github.com/amadeusitgroup/kubervisor/pkg/api/detector/plugin/v1"
+)
+
+// podListDetector gRPC plugin returning the listed pods as out of bounds, like the http server does
+type podListDetector struct {
+	sync.Mutex
+	listed map[string]struct{}
+}
+
+var plugin = &podListDetector{listed: map[string]struct{}{}}
+
+func (d *podListDetector) setPods(pods []kapiv1.Pod) {
+	d.Lock()
+	defer d.Unlock()
+	d.listed = map[string]struct{}{}
+	for _, p := range pods {
+		d.listed[p.Name] = struct{}{}
+	}
+}
+
+// Evaluate implements pluginv1.Detector
+func (d *podListDetector) Evaluate(ctx context.Context, req *pluginv1.EvaluateRequest) (*pluginv1.EvaluateResponse, error) {
+	d.Lock()
+	defer d.Unlock()
+	resp := &pluginv1.EvaluateResponse{}
+	for _, p := range req.Pods {
+		verdict := &pluginv1.PodVerdict{Name: p.Name, Verdict: pluginv1.Verdict_VERDICT_OK}
+		if _, ok := d.listed[p.Name]; ok {
+			verdict.Verdict = pluginv1.Verdict_VERDICT_OUT_OF_BOUNDS
+			verdict.Reason = "the pod matches the selector " + selector
+		}
+		resp.Pods = append(resp.Pods, verdict)
+	}
+	return resp, nil
+}
+
+func runGRPCServer(addr string) {
+	if err := pluginv1.ListenAndServe(addr, plugin); err != nil {
+		sugar.Errorf("gRPC server: %v", err)
+	}
+}
@@ -27,6 +27,9 @@ func main() {
 
 	go prepareResponse(time.Second)
 	go runServer(serverAddr, getMux())
+	if grpcAddr != "" {
+		go runGRPCServer(grpcAddr)
+	}
 
 	<-closeChan
 }
@@ -59,6 +62,7 @@ func prepareResponse(period time.Duration) {
 				sugar.Errorf("Can't list %#v", err)
 			}
 			sugar.Infof("Listing returned %d items", len(list.Items))
+			plugin.setPods(list.Items)
 			if response, err = runtime.Encode(encoder, list); err != nil {
 				sugar.Errorf("Can't Encode list: %v", err)
 			}
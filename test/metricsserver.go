@@ -0,0 +1,92 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kapiv1 "k8s.io/api/core/v1"
+)
+
+const metricsAPIPrefix = "/apis/metrics.k8s.io/v1beta1/namespaces/"
+
+//TestMetricsServer local stand-in of the metrics API (metrics.k8s.io) serving the pod usages set by the test
+//The label selector of the requests is ignored: all the pods of the namespace are returned
+// FOR TEST PURPOSE ONLY
+type TestMetricsServer struct {
+	*httptest.Server
+
+	lock   sync.Mutex
+	usages map[string]map[string]kapiv1.ResourceList
+}
+
+//NewTestMetricsServer create and start a TestMetricsServer, to be closed by the test
+// FOR TEST PURPOSE ONLY
+func NewTestMetricsServer() *TestMetricsServer {
+	s := &TestMetricsServer{usages: map[string]map[string]kapiv1.ResourceList{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.servePodMetrics))
+	return s
+}
+
+//Host return the host:port of the server
+func (s *TestMetricsServer) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+//SetPodUsage set the usage of the pod, a nil usage removes the pod
+func (s *TestMetricsServer) SetPodUsage(namespace, name string, usage kapiv1.ResourceList) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.usages[namespace] == nil {
+		s.usages[namespace] = map[string]kapiv1.ResourceList{}
+	}
+	if usage == nil {
+		delete(s.usages[namespace], name)
+		return
+	}
+	s.usages[namespace][name] = usage
+}
+
+type testContainerMetrics struct {
+	Name  string              `json:"name"`
+	Usage kapiv1.ResourceList `json:"usage"`
+}
+
+type testPodMetrics struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Window     string                 `json:"window"`
+	Containers []testContainerMetrics `json:"containers"`
+}
+
+func (s *TestMetricsServer) servePodMetrics(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, metricsAPIPrefix) || !strings.HasSuffix(r.URL.Path, "/pods") {
+		http.NotFound(w, r)
+		return
+	}
+	namespace := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, metricsAPIPrefix), "/pods")
+
+	s.lock.Lock()
+	items := []testPodMetrics{}
+	for name, usage := range s.usages[namespace] {
+		item := testPodMetrics{Timestamp: time.Now(), Window: "30s", Containers: []testContainerMetrics{{Name: "main", Usage: usage}}}
+		item.Metadata.Name, item.Metadata.Namespace = name, namespace
+		items = append(items, item)
+	}
+	s.lock.Unlock()
+	sort.Slice(items, func(i, j int) bool { return items[i].Metadata.Name < items[j].Metadata.Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind":       "PodMetricsList",
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"items":      items,
+	})
+}
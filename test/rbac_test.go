@@ -0,0 +1,102 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	rbacv1beta1 "k8s.io/api/rbac/v1beta1"
+)
+
+const chartRBACPath = "../charts/kubervisor/templates/rbac.yaml"
+
+var templateAction = regexp.MustCompile(`{{[^}]*}}`)
+
+var readSecretsBlock = regexp.MustCompile(`(?s)[ \t]*\{\{- if \.Values\.rbac\.readSecrets \}\}\n(.*?\n)[ \t]*\{\{- end \}\}\n`)
+
+// TestChartRBAC checks that the ClusterRole of the chart grants the requests done by kubervisor
+func TestChartRBAC(t *testing.T) {
+	required := []struct {
+		apiGroup string
+		resource string
+		verbs    []string
+	}{
+		{apiGroup: "kubervisor.k8s.io", resource: "kubervisorservices", verbs: []string{"get", "list", "watch", "update"}},
+		{apiGroup: "kubervisor.k8s.io", resource: "podanomalyreports", verbs: []string{"get", "list", "watch"}},
+		{apiGroup: "", resource: "pods", verbs: []string{"get", "list", "watch", "update", "delete"}},
+		{apiGroup: "", resource: "services", verbs: []string{"get", "list", "watch"}},
+		{apiGroup: "", resource: "pods/log", verbs: []string{"get"}},
+		{apiGroup: "", resource: "nodes", verbs: []string{"get", "list", "watch", "update"}},
+		{apiGroup: "metrics.k8s.io", resource: "pods", verbs: []string{"get", "list"}},
+		{apiGroup: "", resource: "events", verbs: []string{"create", "patch"}},
+	}
+	for _, readSecrets := range []bool{false, true} {
+		role := chartClusterRole(t, readSecrets)
+		for _, r := range required {
+			for _, verb := range r.verbs {
+				if !allows(role.Rules, r.apiGroup, r.resource, verb) {
+					t.Errorf("the ClusterRole doesn't allow %s on %s of the api group %q", verb, r.resource, r.apiGroup)
+				}
+			}
+		}
+		// the secrets of all the namespaces are readable only when the option is set
+		if got := allows(role.Rules, "", "secrets", "get"); got != readSecrets {
+			t.Errorf("with rbac.readSecrets: %v, the ClusterRole allows get on secrets: %v", readSecrets, got)
+		}
+	}
+}
+
+// chartClusterRole returns the ClusterRole of the chart rendered with the default values and the rbac.readSecrets option
+func chartClusterRole(t *testing.T, readSecrets bool) *rbacv1beta1.ClusterRole {
+	data, err := ioutil.ReadFile(chartRBACPath)
+	if err != nil {
+		t.Fatalf("can't read %s: %v", chartRBACPath, err)
+	}
+	if readSecrets {
+		data = readSecretsBlock.ReplaceAll(data, []byte("$1"))
+	} else {
+		data = readSecretsBlock.ReplaceAll(data, nil)
+	}
+	// the api group is the one of the default values, the other template actions don't matter for the rules
+	data = []byte(strings.Replace(string(data), "{{ .Values.apiGroupName }}", "kubervisor.k8s.io", -1))
+	data = templateAction.ReplaceAll(data, []byte("value"))
+
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err = yaml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("can't parse %s: %v", chartRBACPath, err)
+	}
+	for _, item := range list.Items {
+		r := &rbacv1beta1.ClusterRole{}
+		if err = json.Unmarshal(item, r); err != nil {
+			t.Fatalf("can't parse an item of %s: %v", chartRBACPath, err)
+		}
+		if r.Kind == "ClusterRole" {
+			return r
+		}
+	}
+	t.Fatalf("no ClusterRole in %s", chartRBACPath)
+	return nil
+}
+
+func allows(rules []rbacv1beta1.PolicyRule, apiGroup, resource, verb string) bool {
+	for _, rule := range rules {
+		if contains(rule.APIGroups, apiGroup) && contains(rule.Resources, resource) && contains(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}